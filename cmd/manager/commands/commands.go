@@ -0,0 +1,37 @@
+// Package commands contains command handlers for manager cmd binary.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Validator func for cmd string flags with fixed set of values.
+// Returns error if flag value is not one of the given values.
+func oneOfFlagValidator(values ...string) func(string) error {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("flag value must be one of %v", values)
+		}
+		return nil
+	}
+}
+
+// Validator func for cmd int flags.
+// Returns error if flag value is less than 0.
+func nonNegativeFlagValidator(n int) error {
+	if n < 0 {
+		return errors.New("flag value must not be a negative number")
+	}
+	return nil
+}
+
+// Validator func for cmd int flags.
+// Returns error if flag value is less than 1.
+func positiveFlagValidator(n int) error {
+	if n < 1 {
+		return errors.New("flag value must be a positive number")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cli "github.com/urfave/cli/v3"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/usecase"
+)
+
+// Import subs command instance.
+// Subs are imported by the same usecase as in HTTP API (with service role).
+func NewImportSubs(subsUsecase usecase.SubsUsecase) *cli.Command {
+	return &cli.Command{
+		Name:   "import-subs",
+		Usage:  "Import subs from CSV file with header (invalid rows are reported and skipped)",
+		Action: newImportSubsAction(subsUsecase),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Path to the CSV file with subs",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only validate rows without subs creation",
+			},
+			&cli.StringFlag{
+				Name:  "delimiter",
+				Value: ",",
+				Usage: "Fields delimiter (\"tab\" for tab)",
+			},
+			&cli.StringFlag{
+				Name:  "date-format",
+				Usage: "Layout of dates in Go notation (YYYY-MM-DD and MM-YYYY by default)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "column",
+				Aliases: []string{"c"},
+				Usage:   "Header column of the subs field as field:column (e.g. price:Cost)",
+			},
+		},
+	}
+}
+
+// Handler for import subs command.
+func newImportSubsAction(subsUsecase usecase.SubsUsecase) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		layout, err := usecase.NewSubsCSVLayout(
+			cmd.String("delimiter"), cmd.String("date-format"), cmd.StringSlice("column"))
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(cmd.String("file"))
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
+		}
+		defer file.Close()
+
+		fmt.Println("Import subs...")
+		ctx = entity.ContextWithAuthUser(ctx, &entity.AuthUser{Role: entity.RoleService})
+		report, err := subsUsecase.ImportCSV(ctx, file, layout, cmd.Bool("dry-run"))
+		if err != nil {
+			return err
+		}
+		for _, rowErr := range report.Errors {
+			fmt.Printf("Line %d: %s\n", rowErr.Line, rowErr.Error)
+		}
+		if report.DryRun {
+			fmt.Printf("Dry run! Valid %d of %d rows, invalid %d rows.\n",
+				report.Imported, report.Total, report.Failed)
+			return nil
+		}
+		fmt.Printf("Successfully! Imported %d of %d rows, skipped %d invalid rows.\n",
+			report.Imported, report.Total, report.Failed)
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cli "github.com/urfave/cli/v3"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/exrates"
+)
+
+// Load rates command instance.
+func NewLoadRates(ratesRepoDB repo.ExchangeRatesRepoDB) *cli.Command {
+	return &cli.Command{
+		Name:   "load-rates",
+		Usage:  "Load exchange rates against EUR from CSV (date,currency,rate) or ECB XML file",
+		Action: newLoadRatesAction(ratesRepoDB),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Path to the file with rates",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:      "format",
+				Value:     "csv",
+				Usage:     "Format of the file (csv or ecb)",
+				Validator: oneOfFlagValidator("csv", "ecb"),
+			},
+		},
+	}
+}
+
+// Handler for load rates command.
+func newLoadRatesAction(ratesRepoDB repo.ExchangeRatesRepoDB) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		file, err := os.Open(cmd.String("file"))
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
+		}
+		defer file.Close()
+
+		fmt.Println("Parse rates...")
+		parsedRates, err := exrates.Parsers[cmd.String("format")](file)
+		if err != nil {
+			return fmt.Errorf("parse rates: %w", err)
+		}
+
+		rates := make([]entity.ExchangeRate, 0, len(parsedRates))
+		for _, rate := range parsedRates {
+			rates = append(rates, entity.ExchangeRate{
+				Currency: rate.Currency,
+				RateDate: rate.Date,
+				Rate:     rate.Rate,
+			})
+		}
+		fmt.Printf("Save %d rates... \n", len(rates))
+		if err := ratesRepoDB.Save(ctx, rates); err != nil {
+			return err
+		}
+		fmt.Println("Successfully!")
+		return nil
+	}
+}
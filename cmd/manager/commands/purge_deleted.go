@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cli "github.com/urfave/cli/v3"
+
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+// Purge deleted subs command instance.
+func NewPurgeDeleted(subsRepoDB repo.SubsRepoDB) *cli.Command {
+	return &cli.Command{
+		Name:   "purge-deleted",
+		Usage:  "Permanently delete subs deleted more than N days ago",
+		Action: newPurgeDeletedAction(subsRepoDB),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:      "days",
+				Aliases:   []string{"d"},
+				Value:     30, // nolint:mnd // default retention of deleted subs
+				Usage:     "Number of days for which deleted subs are kept",
+				Validator: nonNegativeFlagValidator,
+			},
+			&cli.IntFlag{
+				Name:      "batch-size",
+				Value:     1000, // nolint:mnd // default number of subs purged at once
+				Usage:     "Number of subs purged in one DB query",
+				Validator: positiveFlagValidator,
+			},
+		},
+	}
+}
+
+// Handler for purge deleted subs command.
+func newPurgeDeletedAction(subsRepoDB repo.SubsRepoDB) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		days, batchSize := cmd.Int("days"), cmd.Int("batch-size")
+		deletedBefore := time.Now().UTC().AddDate(0, 0, -days)
+
+		fmt.Printf("Purge subs deleted before %s...\n", deletedBefore.Format(time.RFC3339))
+		purged := 0
+		for {
+			batchPurged, err := subsRepoDB.Purge(ctx, deletedBefore, batchSize)
+			if err != nil {
+				return err
+			}
+			purged += batchPurged
+			if batchPurged < batchSize {
+				break
+			}
+		}
+		fmt.Printf("Successfully! Purged %d subs.\n", purged)
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+// Manager binary is a data manager for server DB.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v3"
+
+	"SubscriptionAggregator/cmd/manager/commands"
+	"SubscriptionAggregator/config"
+	repopg "SubscriptionAggregator/internal/app/repo/pg"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/database"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+func main() {
+	if err := startManager(); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+func startManager() error {
+	// load config
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+	// open DB connection
+	gormDB, err := database.New(cfg.DB.ConnString,
+		database.WithTranslateError(),
+		database.WithIgnoreNotFound(),
+		database.WithErrorLogLevel(),
+		database.WithLogger(logrus.StandardLogger()),
+	)
+	if err != nil {
+		return fmt.Errorf("db: %w", err)
+	}
+	// create repos
+	ratesRepoDB := repopg.NewExchangeRatesRepoDB(gormDB)
+	subsRepoDB := repopg.NewSubsRepoDB(gormDB)
+	auditRepoDB := repopg.NewAuditRepoDB(gormDB)
+	txManager := repopg.NewTxManager(gormDB)
+	// create subs usecase for import
+	subsUsecase := usecase.NewSubsUsecase(txManager, subsRepoDB, auditRepoDB, validator.New())
+
+	// create manager cmd
+	cmd := &cli.Command{
+		Name:  "manager",
+		Usage: "Data manager for application DB",
+		Commands: []*cli.Command{
+			commands.NewLoadRates(ratesRepoDB),
+			commands.NewPurgeDeleted(subsRepoDB),
+			commands.NewImportSubs(subsUsecase),
+		},
+	}
+	// run manager cmd
+	if err := cmd.Run(context.Background(), os.Args); err != nil {
+		return fmt.Errorf("manager cmd: %w", err)
+	}
+	return nil
+}
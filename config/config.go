@@ -13,12 +13,63 @@ type (
 	Config struct {
 		Server
 		DB
+		Auth
+		Webhooks
+		Outbox
+		Idempotency
+		Calendar
 	}
 
 	Server struct {
 		Name            string        `env:"SERVER_NAME" env-default:"Subscription Aggregator API"`
 		Port            string        `env:"SERVER_PORT" env-default:"8000"`
 		ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"5s"`
+		// max duration of one DB statement
+		DBTimeout time.Duration `env:"SERVER_DB_TIMEOUT" env-default:"10s"`
+		// subs update without If-Match header is rejected
+		RequireIfMatch bool `env:"SERVER_REQUIRE_IF_MATCH" env-default:"true"`
+		// max time of streaming subs export (it is not limited by DB timeout)
+		ExportTimeout time.Duration `env:"SERVER_EXPORT_TIMEOUT" env-default:"5m"`
+	}
+
+	Auth struct {
+		JWTSecret string `env:"AUTH_JWT_SECRET"`
+		JWKSFile  string `env:"AUTH_JWKS_FILE"`
+	}
+
+	Webhooks struct {
+		PollInterval   time.Duration `env:"WEBHOOKS_POLL_INTERVAL" env-default:"5s"`
+		BatchSize      int           `env:"WEBHOOKS_BATCH_SIZE" env-default:"100"`
+		MaxAttempts    int           `env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+		RetryBackoff   time.Duration `env:"WEBHOOKS_RETRY_BACKOFF" env-default:"30s"`
+		RequestTimeout time.Duration `env:"WEBHOOKS_REQUEST_TIMEOUT" env-default:"10s"`
+	}
+
+	Outbox struct {
+		// publisher of events: log, http, nats or none
+		Publisher      string        `env:"OUTBOX_PUBLISHER" env-default:"log"`
+		PollInterval   time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+		BatchSize      int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+		MaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS" env-default:"10"`
+		RetryBackoff   time.Duration `env:"OUTBOX_RETRY_BACKOFF" env-default:"10s"`
+		PublishTimeout time.Duration `env:"OUTBOX_PUBLISH_TIMEOUT" env-default:"10s"`
+		HTTPURL        string        `env:"OUTBOX_HTTP_URL"`
+		NATSAddr       string        `env:"OUTBOX_NATS_ADDR"`
+		NATSSubject    string        `env:"OUTBOX_NATS_SUBJECT" env-default:"subscription_aggregator"`
+	}
+
+	Idempotency struct {
+		// time for which idempotency key and saved response are kept
+		TTL            time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"`
+		PurgeInterval  time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
+		PurgeBatchSize int           `env:"IDEMPOTENCY_PURGE_BATCH_SIZE" env-default:"1000"`
+		// time after which the key of unfinished request can be taken over by the same request
+		Lease time.Duration `env:"IDEMPOTENCY_LEASE" env-default:"1m"`
+	}
+
+	Calendar struct {
+		// secret to sign calendar tokens of users (calendar is disabled if it is empty)
+		Secret string `env:"CALENDAR_SECRET"`
 	}
 
 	DB struct {
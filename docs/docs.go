@@ -21,41 +21,60 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subs": {
+        "/api-keys": {
             "get": {
-                "description": "Получение всех записей подписок.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение всех API-ключей без их значений (только для администратора).",
                 "tags": [
-                    "subs-crudl"
+                    "api-keys"
                 ],
-                "summary": "Получить все записи подписок",
-                "operationId": "get-all-subs",
+                "summary": "Получить API-ключи",
+                "operationId": "get-all-api-keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/entity.Subscription"
+                                "$ref": "#/definitions/entity.APIKey"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
             },
             "post": {
-                "description": "Создание новой записи подписки.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание нового API-ключа (только для администратора). Ключ возвращается только один раз.",
                 "tags": [
-                    "subs-crudl"
+                    "api-keys"
                 ],
-                "summary": "Создать запись подписки",
-                "operationId": "create-sub",
+                "summary": "Создать API-ключ",
+                "operationId": "create-api-key",
                 "parameters": [
                     {
-                        "description": "Информация о подписке",
-                        "name": "Sub",
+                        "description": "Информация о ключе",
+                        "name": "Key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.inSubsCreate"
+                            "$ref": "#/definitions/v1.inAPIKeyCreate"
                         }
                     }
                 ],
@@ -63,46 +82,139 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Subscription"
+                            "$ref": "#/definitions/entity.APIKeyCreated"
                         }
                     },
                     "400": {
                         "description": "Невалидное тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
             }
         },
-        "/subs-sum": {
+        "/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзыв API-ключа по его ID (только для администратора).",
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Отозвать API-ключ",
+                "operationId": "revoke-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Успешный отзыв"
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "404": {
+                        "description": "Ключ не найден"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/audit": {
             "get": {
-                "description": "Получение суммарной стоимости всех подписок за выбранный период с фильтрацией по id пользователя и названию подписки.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение журнала изменений всех подписок от новых к старым с фильтрацией и пагинацией (только для администратора).",
                 "tags": [
-                    "subs-advanced"
+                    "audit"
                 ],
-                "summary": "Получить суммарную стоимость подписок",
-                "operationId": "get-subs-sum",
+                "summary": "Получить журнал изменений подписок",
+                "operationId": "get-audit",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID пользователя",
+                        "description": "UUID подписки",
+                        "name": "subs_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID владельца подписки",
                         "name": "user_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Название сервиса",
-                        "name": "service_name",
+                        "description": "UUID автора изменения",
+                        "name": "actor_id",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "pause",
+                            "resume"
+                        ],
                         "type": "string",
-                        "description": "Дата начала",
-                        "name": "start_date",
+                        "description": "Действие",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Дата окончания",
-                        "name": "end_date",
+                        "description": "Минимальное время изменения в RFC 3339 (включительно)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Максимальное время изменения в RFC 3339 (не включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
@@ -110,142 +222,2440 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SubscriptionSum"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.AuditEntry"
+                            }
                         }
                     },
                     "400": {
                         "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
             }
         },
-        "/subs/{id}": {
+        "/subs": {
             "get": {
-                "description": "Получение записи подписки по её ID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение записей подписок с фильтрацией, сортировкой и пагинацией (offset или cursor).",
                 "tags": [
                     "subs-crudl"
                 ],
-                "summary": "Получить запись подписки",
-                "operationId": "get-sub",
+                "summary": "Получить записи подписок",
+                "operationId": "get-all-subs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата (YYYY-MM-DD) или месяц (MM-YYYY), в любой день которого подписка активна",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена в минимальных единицах валюты, например копейках (включительно)",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена в минимальных единицах валюты, например копейках (включительно)",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Включая удалённые подписки (только для администратора)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "service_name",
+                            "price",
+                            "start_date"
+                        ],
+                        "type": "string",
+                        "default": "start_date",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Порядок сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (нельзя использовать вместе с cursor)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы (next_cursor)",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Subscription"
+                            "$ref": "#/definitions/entity.SubscriptionPage"
                         }
                     },
                     "400": {
-                        "description": "Невалидный параметр запроса"
+                        "description": "Невалидный(ые) параметр(ы) запроса"
                     },
-                    "404": {
-                        "description": "Подписка не найдена"
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
             },
-            "delete": {
-                "description": "Удаление записи подписки по её ID.",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание новой записи подписки. Даты задаются с точностью до дня (YYYY-MM-DD)\nили до месяца (MM-YYYY), точность возвращается в полях start_date_precision\nи end_date_precision. Дата окончания с точностью до месяца включает весь месяц.\nСписания, кроме еженедельных, приходятся на день billing_day (по умолчанию день\nдаты начала), в коротких месяцах — на последний день месяца.",
                 "tags": [
                     "subs-crudl"
                 ],
-                "summary": "Удалить запись подписки",
-                "operationId": "delete-sub",
+                "summary": "Создать запись подписки",
+                "operationId": "create-sub",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Ключ идемпотентности (повторный запрос с ним вернёт сохранённый ответ)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Информация о подписке",
+                        "name": "Sub",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.inSubsCreate"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Успешное удаление"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true, если ответ повторён по ключу идемпотентности"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Невалидный параметр запроса"
+                        "description": "Невалидное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/errors.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "409": {
+                        "description": "Запрос с этим ключом идемпотентности ещё выполняется"
+                    },
+                    "422": {
+                        "description": "Ключ идемпотентности использован с другим запросом"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
-            },
-            "patch": {
-                "description": "Обновление записи подписки по её ID.",
+            }
+        },
+        "/subs-sum": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение суммарной стоимости всех подписок за выбранный период с фильтрацией по id пользователя и названию подписки.\nСтоимость каждой подписки умножается на количество дат её списаний (по периоду оплаты) в пределах периода.\nГраницы периода задаются днями (YYYY-MM-DD) или месяцами (MM-YYYY), месяц окончания включается целиком.",
                 "tags": [
-                    "subs-crudl"
+                    "subs-advanced"
                 ],
-                "summary": "Обновить запись подписки",
-                "operationId": "update-sub",
+                "summary": "Получить суммарную стоимость подписок",
+                "operationId": "get-subs-sum",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Информация о подписке",
-                        "name": "Sub",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.inSubsUpdate"
-                        }
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала (YYYY-MM-DD или MM-YYYY)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания (YYYY-MM-DD или MM-YYYY — весь месяц)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата (ISO 4217), по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Subscription"
+                            "$ref": "#/definitions/entity.SubscriptionSum"
                         }
                     },
                     "400": {
-                        "description": "Невалидный параметр или тело запроса"
+                        "description": "Невалидный(ые) параметр(ы) запроса"
                     },
-                    "404": {
-                        "description": "Подписка не найдена"
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "422": {
+                        "description": "Не найден курс валюты для месяца списания"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.Subscription": {
-            "description": "Subscription object",
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "description": "end date",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "subscription uuid",
-                    "type": "string"
-                },
-                "price": {
-                    "description": "price",
-                    "type": "integer"
-                },
+        },
+        "/subs-sum/grouped": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение суммарной стоимости подписок за выбранный период, сгруппированной по названию сервиса или id пользователя.\nГруппы отсортированы по убыванию суммы, стоимость считается так же, как в /subs-sum.",
+                "tags": [
+                    "subs-advanced"
+                ],
+                "summary": "Получить стоимость подписок по группам",
+                "operationId": "get-subs-grouped-sum",
+                "parameters": [
+                    {
+                        "enum": [
+                            "service_name",
+                            "user_id"
+                        ],
+                        "type": "string",
+                        "description": "Поле группировки",
+                        "name": "by",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Количество групп (top-N)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала (YYYY-MM-DD или MM-YYYY)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания (YYYY-MM-DD или MM-YYYY — весь месяц)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата (ISO 4217), по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionSumGroup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "422": {
+                        "description": "Не найден курс валюты для месяца списания"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs-sum/monthly": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение стоимости подписок за каждый месяц периода с количеством активных подписок и разбивкой по сервисам.\nПериод задаётся месяцами from и to (включительно) и не может быть длиннее 120 месяцев.",
+                "tags": [
+                    "subs-advanced"
+                ],
+                "summary": "Получить помесячную стоимость подписок",
+                "operationId": "get-subs-monthly-sum",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Первый месяц периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний месяц периода",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата (ISO 4217), по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionMonthlySum"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "422": {
+                        "description": "Не найден курс валюты для месяца списания"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание до 1000 записей подписок одним запросом (многострочной вставкой).\nПри atomic=true (по умолчанию) все подписки создаются в одной транзакции,\nи ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).\nПри atomic=false невалидные подписки пропускаются, при ошибке БД подписки\nсоздаются по одной (каждая в своей точке сохранения), а результат каждой\nподписки возвращается с её индексом, кодом и ошибкой (код ответа 207).",
+                "tags": [
+                    "subs-batch"
+                ],
+                "summary": "Создать записи подписок пакетом",
+                "operationId": "create-subs-batch",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Все подписки в одной транзакции (по умолчанию true)",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности (повторный запрос с ним вернёт сохранённый ответ)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Информация о подписках",
+                        "name": "Subs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.inSubsCreate"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/errors.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "409": {
+                        "description": "Запрос с этим ключом идемпотентности ещё выполняется"
+                    },
+                    "422": {
+                        "description": "Ключ идемпотентности использован с другим запросом"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление до 1000 записей подписок по их ID одним запросом.\nПри atomic=true (по умолчанию) все подписки удаляются в одной транзакции,\nи ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).\nПри atomic=false каждая подписка удаляется отдельно, а результат каждой\nвозвращается с её индексом, кодом и ошибкой (код ответа 207).",
+                "tags": [
+                    "subs-batch"
+                ],
+                "summary": "Удалить записи подписок пакетом",
+                "operationId": "delete-subs-batch",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Все подписки в одной транзакции (по умолчанию true)",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "UUID подписок",
+                        "name": "IDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр или тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление до 1000 записей подписок одним запросом.\nКаждый элемент содержит ID подписки, обновляемые поля и, при необходимости,\nожидаемую версию подписки (version).\nЕсли обязателен заголовок If-Match (SERVER_REQUIRE_IF_MATCH), обязательна и version.\nПри atomic=true (по умолчанию) все подписки обновляются в одной транзакции,\nи ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).\nПри atomic=false каждая подписка обновляется отдельно, а результат каждой\nвозвращается с её индексом, кодом и ошибкой (код ответа 207).",
+                "tags": [
+                    "subs-batch"
+                ],
+                "summary": "Обновить записи подписок пакетом",
+                "operationId": "update-subs-batch",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Все подписки в одной транзакции (по умолчанию true)",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Информация о подписках",
+                        "name": "Subs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.inSubsBatchUpdate"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionBatchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/errors.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "412": {
+                        "description": "Подписка изменилась (версия не совпадает с version)"
+                    },
+                    "428": {
+                        "description": "Не указана version"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Потоковая выгрузка всех записей подписок с фильтрацией и сортировкой как в списке подписок\n(без пагинации) в формате CSV, XLSX или JSON Lines.\nКроме полей подписки выгружаются вычисляемые колонки: months_active — число месяцев\nот начала подписки до её окончания или текущего месяца без месяцев приостановки,\ntotal_paid — сумма списаний с начала подписки до текущей даты в валюте подписки.\nОшибка во время выгрузки прерывает файл (код ответа уже отправлен).",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/jsonl"
+                ],
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Выгрузить записи подписок",
+                "operationId": "export-subs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат файла",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата (YYYY-MM-DD) или месяц (MM-YYYY), в любой день которого подписка активна",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена в минимальных единицах валюты, например копейках (включительно)",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена в минимальных единицах валюты, например копейках (включительно)",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Включая удалённые подписки (только для администратора)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "service_name",
+                            "price",
+                            "start_date"
+                        ],
+                        "type": "string",
+                        "default": "start_date",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Порядок сортировки",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл с подписками",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=subs.\u003cformat\u003e"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    }
+                }
+            }
+        },
+        "/subs/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Импорт записей подписок из CSV-файла (поле формы file).\nПервая строка файла — заголовок. По умолчанию колонки называются как поля\nподписки (service_name, price, currency, billing_period, billing_interval,\nbilling_day, user_id, start_date, end_date), разделитель — запятая,\nдаты — YYYY-MM-DD или MM-YYYY. Названия колонок задаются параметрами column\nвида поле:колонка, формат дат — параметром date_format в нотации Go\n(например, 02.01.2006, дата без дня задаётся с точностью до месяца).\nКаждая строка проверяется как тело создания подписки. Файл читается потоково,\nподписки из валидных строк создаются пачками в одной транзакции, ошибки\nневалидных строк возвращаются в отчёте с номерами строк.\nПри dry_run=true подписки только проверяются.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "subs-batch"
+                ],
+                "summary": "Импортировать записи подписок из CSV",
+                "operationId": "import-subs",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV-файл с подписками",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить строки, не создавая подписки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Разделитель полей (по умолчанию запятая, tab для табуляции)",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат дат в нотации Go (по умолчанию YYYY-MM-DD или MM-YYYY)",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Название колонки поля в виде поле:колонка",
+                        "name": "column",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчёт проверки или импорта без созданных подписок",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SubscriptionImportReport"
+                        }
+                    },
+                    "201": {
+                        "description": "Отчёт импорта",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SubscriptionImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидные параметры или файл"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/upcoming": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение не более limit списаний по подпискам, которые должны произойти\nв ближайшие days дней (включая текущий день), отсортированных по дате.\nСписания в месяцах приостановки и после окончания подписки не учитываются.",
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Получить предстоящие списания",
+                "operationId": "get-upcoming-charges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя (для администратора без него — все пользователи)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 366,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Количество дней",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Максимальное количество списаний",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionCharge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение записи подписки по её ID.",
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Получить запись подписки",
+                "operationId": "get-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Включая удалённую подписку (только для администратора)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag известной клиенту версии подписки",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Подписка не изменилась"
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора для удалённой подписки"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление записи подписки по её ID.\nУдалённая подписка хранится до окончательного удаления и может быть восстановлена.\nУдаление идемпотентно: повторное удаление уже удалённой подписки возвращает 204,\nа 404 возвращается только для подписки, которой никогда не было.",
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Удалить запись подписки",
+                "operationId": "delete-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Успешное удаление"
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление записи подписки по её ID.\nС типом application/json обновляются только переданные поля.\nС типом application/merge-patch+json тело является JSON Merge Patch (RFC 7386):\nnull очищает поле (например, end_date).\nС типом application/json-patch+json тело является списком операций JSON Patch (RFC 6902).\nРезультат патча проверяется целиком, включая даты начала и окончания.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Обновить запись подписки",
+                "operationId": "update-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии подписки (обязателен в строгом режиме)",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Информация о подписке или патч",
+                        "name": "Sub",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.inSubsUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр или тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/errors.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "409": {
+                        "description": "Не выполнена операция test в JSON Patch"
+                    },
+                    "412": {
+                        "description": "Подписка изменилась (версия не совпадает с If-Match)"
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмена подписки сразу (последний месяц подписки - текущий) или с указанного месяца (MM-YYYY) или дня (YYYY-MM-DD) с необязательной причиной.\nТело запроса необязательно.",
+                "tags": [
+                    "subs-actions"
+                ],
+                "summary": "Отменить подписку",
+                "operationId": "cancel-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Информация об отмене",
+                        "name": "Cancel",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.inSubsCancel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр или тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "409": {
+                        "description": "Подписка уже закончилась"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение журнала изменений подписки по её ID от новых к старым.\nКаждая запись содержит автора, действие, состояния до и после изменения и ID запроса.",
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Получить историю изменений подписки",
+                "operationId": "get-sub-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Приостановка подписки на указанные месяцы (по умолчанию с текущего месяца до возобновления).\nПриостановленные месяцы не учитываются в суммах. Для даты с днём приостанавливается её месяц. Тело запроса необязательно.",
+                "tags": [
+                    "subs-actions"
+                ],
+                "summary": "Приостановить подписку",
+                "operationId": "pause-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Приостановленные месяцы",
+                        "name": "Pause",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.inSubsPause"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SubscriptionPause"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр или тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "409": {
+                        "description": "Подписка уже приостановлена в эти месяцы"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/pauses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение всех приостановок подписки по её ID.",
+                "tags": [
+                    "subs-actions"
+                ],
+                "summary": "Получить приостановки подписки",
+                "operationId": "get-sub-pauses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SubscriptionPause"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстановление удалённой записи подписки по её ID.",
+                "tags": [
+                    "subs-crudl"
+                ],
+                "summary": "Восстановить запись подписки",
+                "operationId": "restore-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Удалённая подписка не найдена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/subs/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возобновление приостановленной подписки с текущего месяца.\nТекущая приостановка заканчивается в предыдущем месяце, запланированные приостановки отменяются.",
+                "tags": [
+                    "subs-actions"
+                ],
+                "summary": "Возобновить подписку",
+                "operationId": "resume-sub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Успешное возобновление"
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "404": {
+                        "description": "Подписка не найдена"
+                    },
+                    "409": {
+                        "description": "Подписка не приостановлена"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar-token": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение секретного токена и ссылки на календарь (iCalendar) списаний\nпо подпискам пользователя для подписки на него в приложении календаря.\nПользователь может получить только свой токен,\nадминистратор — токен любого пользователя.",
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Получить ссылку на календарь списаний",
+                "operationId": "get-calendar-token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CalendarToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к календарю другого пользователя"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выпуск нового секретного токена и ссылки на календарь списаний пользователя.\nПрежняя ссылка отзывается и перестаёт работать.\nПользователь может перевыпустить только свой токен,\nадминистратор — токен любого пользователя.",
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Перевыпустить ссылку на календарь списаний",
+                "operationId": "regenerate-calendar-token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CalendarToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет доступа к календарю другого пользователя"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/charges.ics": {
+            "get": {
+                "description": "Календарь (iCalendar, RFC 5545) списаний по подпискам пользователя, которые\nне завершились до текущего месяца. Каждая подписка — повторяющееся событие\nна весь день с правилом повторения по периоду оплаты, датой окончания подписки\nи исключёнными месяцами приостановки. UID события постоянен для подписки,\nпоэтому приложения календаря обновляют события, а не дублируют их.\nДоступ проверяется по токену пользователя из ссылки (отозванный перевыпуском\nтокен не действует), заголовок Authorization не нужен.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Получить календарь списаний",
+                "operationId": "get-charges-calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен календаря пользователя",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Календарь списаний",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "404": {
+                        "description": "Неверный токен календаря"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение всех вебхуков (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить вебхуки",
+                "operationId": "get-all-webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание вебхука на события жизненного цикла подписок (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Создать вебхук",
+                "operationId": "create-webhook",
+                "parameters": [
+                    {
+                        "description": "Информация о вебхуке",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.inWebhookCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидное тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение вебхука по его ID (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить вебхук",
+                "operationId": "get-webhook-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "404": {
+                        "description": "Вебхук не найден"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление вебхука вместе с журналом его доставок по его ID (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить вебхук",
+                "operationId": "delete-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Успешное удаление"
+                    },
+                    "400": {
+                        "description": "Невалидный параметр запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "404": {
+                        "description": "Вебхук не найден"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление вебхука по его ID (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Обновить вебхук",
+                "operationId": "update-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Информация о вебхуке",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.inWebhookUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный параметр или тело запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "404": {
+                        "description": "Вебхук не найден"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение доставок событий вебхуку от новых к старым (только для администратора).",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить журнал доставок вебхука",
+                "operationId": "get-webhook-deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Статус доставки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидный(ые) параметр(ы) запроса"
+                    },
+                    "401": {
+                        "description": "Не авторизован"
+                    },
+                    "403": {
+                        "description": "Нет прав администратора"
+                    },
+                    "404": {
+                        "description": "Вебхук не найден"
+                    },
+                    "504": {
+                        "description": "Превышено время выполнения запроса к БД"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.APIKey": {
+            "description": "API key for service-to-service access.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "creation time",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "expiration time",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "api key uuid",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "time of the last key usage (with LastUsedPrecision)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "api key name",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "uuid of the user whose subs are accessed by the key (all users if it is absent)",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "revocation time",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "allowed scopes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.APIKeyCreated": {
+            "description": "Created API key with its plaintext value (it is shown only once).",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "creation time",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "expiration time",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "api key uuid",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "plaintext key to use in \"Authorization: ApiKey \u003ckey\u003e\" header",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "time of the last key usage (with LastUsedPrecision)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "api key name",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "uuid of the user whose subs are accessed by the key (all users if it is absent)",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "revocation time",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "allowed scopes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "restore",
+                "pause",
+                "resume"
+            ],
+            "x-enum-varnames": [
+                "AuditCreate",
+                "AuditUpdate",
+                "AuditDelete",
+                "AuditRestore",
+                "AuditPause",
+                "AuditResume"
+            ]
+        },
+        "entity.AuditEntry": {
+            "description": "Audit log entry with one subs change.",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "action",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "pause",
+                        "resume"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.AuditAction"
+                        }
+                    ]
+                },
+                "actor_id": {
+                    "description": "uuid of the user made the change (absent for API key without owner)",
+                    "type": "string"
+                },
+                "actor_role": {
+                    "description": "role of the user made the change",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Role"
+                        }
+                    ]
+                },
+                "after": {
+                    "description": "subs state after the change (absent for delete)",
+                    "type": "object"
+                },
+                "api_key_id": {
+                    "description": "uuid of the API key used for the change",
+                    "type": "string"
+                },
+                "before": {
+                    "description": "subs state before the change (absent for create)",
+                    "type": "object"
+                },
+                "changes": {
+                    "description": "changed fields with old and new values (subs pauses for pause and resume)",
+                    "type": "object"
+                },
+                "created_at": {
+                    "description": "time of the change",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "entry uuid",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "ID of the request made the change",
+                    "type": "string"
+                },
+                "subs_id": {
+                    "description": "changed subs uuid",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "uuid of the subs owner",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BillingPeriod": {
+            "type": "string",
+            "enum": [
+                "weekly",
+                "monthly",
+                "quarterly",
+                "yearly"
+            ],
+            "x-enum-varnames": [
+                "BillingWeekly",
+                "BillingMonthly",
+                "BillingQuarterly",
+                "BillingYearly"
+            ]
+        },
+        "entity.CalendarToken": {
+            "description": "Calendar token of the user to subscribe to the calendar of his charges.",
+            "type": "object",
+            "properties": {
+                "token": {
+                    "description": "secret token of the calendar (it is valid until it is regenerated\nor server calendar secret is changed)",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL of the calendar (iCalendar) with the token",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "user uuid",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "entity.DatePrecision": {
+            "type": "string",
+            "enum": [
+                "month",
+                "day"
+            ],
+            "x-enum-varnames": [
+                "PrecisionMonth",
+                "PrecisionDay"
+            ]
+        },
+        "entity.EventType": {
+            "type": "string",
+            "enum": [
+                "subs.created",
+                "subs.updated",
+                "subs.deleted",
+                "subs.restored",
+                "subs.ended",
+                "subs.paused",
+                "subs.resumed"
+            ],
+            "x-enum-varnames": [
+                "EventSubsCreated",
+                "EventSubsUpdated",
+                "EventSubsDeleted",
+                "EventSubsRestored",
+                "EventSubsEnded",
+                "EventSubsPaused",
+                "EventSubsResumed"
+            ]
+        },
+        "entity.Money": {
+            "description": "Money amount in the currency.",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "amount in minor units (e.g. kopecks), decimal string in major units in JSON",
+                    "type": "string",
+                    "example": "199.99"
+                },
+                "currency": {
+                    "description": "currency (ISO 4217)",
+                    "type": "string",
+                    "example": "RUB"
+                }
+            }
+        },
+        "entity.Role": {
+            "type": "string",
+            "enum": [
+                "user",
+                "admin",
+                "service"
+            ],
+            "x-enum-comments": {
+                "RoleService": "API key without owner"
+            },
+            "x-enum-descriptions": [
+                "API key without owner"
+            ],
+            "x-enum-varnames": [
+                "RoleUser",
+                "RoleAdmin",
+                "RoleService"
+            ]
+        },
+        "entity.Subscription": {
+            "description": "Subscription object",
+            "type": "object",
+            "properties": {
+                "billing_day": {
+                    "description": "day of month of charges (the last day of the shorter month), it is not used for weekly subs",
+                    "type": "integer",
+                    "example": 17
+                },
+                "billing_interval": {
+                    "description": "number of billing periods between charges",
+                    "type": "integer"
+                },
+                "billing_period": {
+                    "description": "billing period",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BillingPeriod"
+                        }
+                    ]
+                },
+                "cancel_reason": {
+                    "description": "reason of the cancellation",
+                    "type": "string"
+                },
+                "canceled_at": {
+                    "description": "time of the cancellation",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "time of the deletion (present only for deleted subs)",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "end date (the whole month is included for month precision)",
+                    "type": "string"
+                },
+                "end_date_precision": {
+                    "description": "precision of the end date (present only with end date, month precision if it is absent)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.DatePrecision"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "subscription uuid",
+                    "type": "string"
+                },
+                "monthly_price": {
+                    "description": "price normalized to one month",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
+                },
+                "next_charge_date": {
+                    "description": "date of the next charge from the current date (absent if there are no more charges)",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "price for one billing period",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
+                },
+                "service_name": {
+                    "description": "service name",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "start date",
+                    "type": "string"
+                },
+                "start_date_precision": {
+                    "description": "precision of the start date",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.DatePrecision"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "user uuid",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "version incremented on every update",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.SubscriptionBatchResult": {
+            "description": "Result of one item of the subs batch.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "error message of the failed item",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "subs uuid",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "index of the item in the batch",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "HTTP status code of the item",
+                    "type": "integer"
+                },
+                "subs": {
+                    "description": "created or updated subs",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Subscription"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.SubscriptionCharge": {
+            "description": "Upcoming charge of the subscription.",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "charged amount (subs price)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
+                },
+                "date": {
+                    "description": "charge date",
+                    "type": "string",
+                    "example": "2025-07-17T00:00:00Z"
+                },
+                "service_name": {
+                    "description": "service name",
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subs_id": {
+                    "description": "subscription uuid",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "user uuid",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.SubscriptionImportError": {
+            "description": "Error of one row of the imported file.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "error message",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "line number of the row in the file",
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "description": "HTTP status code of the row error",
+                    "type": "integer",
+                    "example": 400
+                }
+            }
+        },
+        "entity.SubscriptionImportReport": {
+            "description": "Report of subs import from file.",
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "subs were only validated, not created",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "errors of the invalid rows",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.SubscriptionImportError"
+                    }
+                },
+                "failed": {
+                    "description": "number of invalid rows",
+                    "type": "integer"
+                },
+                "imported": {
+                    "description": "number of created subs (valid rows for dry run)",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "number of data rows in the file",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.SubscriptionMonthlySum": {
+            "description": "Subs costs for one month with per-service breakdown.",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "number of active subs",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "month (first day of month)",
+                    "type": "string"
+                },
+                "services": {
+                    "description": "per-service breakdown",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.SubscriptionServiceSum"
+                    }
+                },
+                "sum": {
+                    "description": "total charged",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.SubscriptionPage": {
+            "description": "Page of subscriptions.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "subs on the page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Subscription"
+                    }
+                },
+                "next_cursor": {
+                    "description": "cursor to get next page (absent on the last page)",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "total number of subs matched by filter",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.SubscriptionPause": {
+            "description": "Interval of months when the subscription is paused and is not charged.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "creation time",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "the last paused month (absent until resume)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "pause uuid",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "the first paused month",
+                    "type": "string"
+                },
+                "subs_id": {
+                    "description": "subscription uuid",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.SubscriptionServiceSum": {
+            "description": "Subs costs of one service for one month.",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "number of active subs",
+                    "type": "integer"
+                },
                 "service_name": {
                     "description": "service name",
                     "type": "string"
                 },
-                "start_date": {
-                    "description": "start date",
-                    "type": "string"
-                },
-                "user_id": {
-                    "description": "user uuid",
-                    "type": "string"
+                "sum": {
+                    "description": "total charged",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
                 }
             }
         },
@@ -261,9 +2671,20 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "rates": {
+                    "description": "exchange rates used to convert subs prices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.SubscriptionSumRate"
+                    }
+                },
                 "sum": {
                     "description": "result",
-                    "type": "integer"
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
                 }
             }
         },
@@ -271,10 +2692,22 @@ const docTemplate = `{
             "description": "Filter for SubscriptionSum result.",
             "type": "object",
             "properties": {
+                "currency": {
+                    "description": "currency to convert prices to (ISO 4217)",
+                    "type": "string"
+                },
                 "end_date": {
                     "description": "end date",
                     "type": "string"
                 },
+                "end_date_precision": {
+                    "description": "precision of the end date (the whole month is included for month precision)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.DatePrecision"
+                        }
+                    ]
+                },
                 "service_name": {
                     "description": "service name",
                     "type": "string"
@@ -289,6 +2722,326 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.SubscriptionSumGroup": {
+            "description": "Subs costs of one group.",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "number of subs in the group",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "value of the group field",
+                    "type": "string"
+                },
+                "sum": {
+                    "description": "total charged",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.SubscriptionSumRate": {
+            "description": "Exchange rate used to convert subs prices charged in the month.",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "subs price currency",
+                    "type": "string"
+                },
+                "month": {
+                    "description": "charge month (first day of month)",
+                    "type": "string"
+                },
+                "rate": {
+                    "description": "amount of result currency for 1 unit of subs price currency",
+                    "type": "number"
+                },
+                "to": {
+                    "description": "result currency",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Webhook": {
+            "description": "Webhook subscribed to subs lifecycle events.",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "events are sent only to active webhooks",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "creation time",
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "event types to send",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "webhook uuid",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "receiver URL",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookDelivery": {
+            "description": "Delivery of one event to one webhook.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "number of made attempts",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "creation time",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "time of successful delivery",
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "event uuid",
+                    "type": "string"
+                },
+                "event_type": {
+                    "description": "event type",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.EventType"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "delivery uuid",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "error of the last failed attempt",
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "description": "HTTP status code of the last attempt response",
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "description": "time of the next attempt (for pending delivery)",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "sent JSON payload (Event)",
+                    "type": "object"
+                },
+                "status": {
+                    "description": "delivery status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.WebhookDeliveryStatus"
+                        }
+                    ]
+                },
+                "webhook_id": {
+                    "description": "webhook uuid",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-comments": {
+                "DeliveryDead": "all attempts are failed"
+            },
+            "x-enum-descriptions": [
+                "all attempts are failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryDelivered",
+                "DeliveryDead"
+            ]
+        },
+        "errors.FieldError": {
+            "description": "Validation error of one data field.",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "field name",
+                    "type": "string",
+                    "example": "end_date"
+                },
+                "message": {
+                    "description": "error message",
+                    "type": "string",
+                    "example": "must not be before start_date"
+                }
+            }
+        },
+        "errors.ValidationErrorResponse": {
+            "description": "Validation error response with invalid fields.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "error message",
+                    "type": "string",
+                    "example": "update subs: validate data: end_date must not be before start_date"
+                },
+                "fields": {
+                    "description": "invalid fields",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/errors.FieldError"
+                    }
+                }
+            }
+        },
+        "v1.inAPIKeyCreate": {
+            "description": "inAPIKeyCreate is body input data with API key data.",
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "expiration time (without expiration if it is absent)",
+                    "type": "string",
+                    "example": "2026-01-01T00:00:00Z"
+                },
+                "name": {
+                    "description": "key name",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "billing-cron"
+                },
+                "owner_id": {
+                    "description": "uuid of the user whose subs are accessed by the key (all users if it is absent)",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "scopes": {
+                    "description": "allowed scopes",
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subs:read",
+                        "subs:sum"
+                    ]
+                }
+            }
+        },
+        "v1.inSubsBatchUpdate": {
+            "description": "inSubsBatchUpdate is body input data with subs ID and its optional data.",
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "billing_day": {
+                    "description": "day of month of charges (new start date day if start date is changed)",
+                    "type": "integer",
+                    "maximum": 31,
+                    "minimum": 1,
+                    "example": 17
+                },
+                "billing_interval": {
+                    "description": "number of billing periods between charges",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "billing_period": {
+                    "description": "billing period",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "currency": {
+                    "description": "price currency (ISO 4217)",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "end date (YYYY-MM-DD or MM-YYYY, the whole month is included)",
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "id": {
+                    "description": "subs uuid",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "price": {
+                    "description": "price for one billing period (decimal string in major units)",
+                    "type": "string",
+                    "example": "199.99"
+                },
+                "service_name": {
+                    "description": "service name",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "description": "start date (YYYY-MM-DD or MM-YYYY)",
+                    "type": "string",
+                    "example": "2025-07-17"
+                },
+                "user_id": {
+                    "description": "user uuid",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "version": {
+                    "description": "expected current subs version (required if If-Match header is required,\notherwise it is not checked if it is absent)",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "v1.inSubsCancel": {
+            "description": "inSubsCancel is body input data with subs cancellation.",
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "the last month (MM-YYYY) or day (YYYY-MM-DD) of the subs (the current month by default)",
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "reason": {
+                    "description": "reason of the cancellation",
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Too expensive"
+                }
+            }
+        },
         "v1.inSubsCreate": {
             "description": "inSubsCreate is body input data with subs data.",
             "type": "object",
@@ -299,15 +3052,45 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "billing_day": {
+                    "description": "day of month of charges (start date day by default)",
+                    "type": "integer",
+                    "maximum": 31,
+                    "minimum": 1,
+                    "example": 17
+                },
+                "billing_interval": {
+                    "description": "number of billing periods between charges (1 by default)",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "billing_period": {
+                    "description": "billing period (monthly by default)",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "currency": {
+                    "description": "price currency (ISO 4217, RUB by default)",
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
-                    "description": "end date",
+                    "description": "end date (YYYY-MM-DD or MM-YYYY, the whole month is included)",
                     "type": "string",
                     "example": "08-2025"
                 },
                 "price": {
-                    "description": "price",
-                    "type": "integer",
-                    "example": 400
+                    "description": "price for one billing period (decimal string in major units)",
+                    "type": "string",
+                    "example": "199.99"
                 },
                 "service_name": {
                     "description": "service name",
@@ -316,9 +3099,9 @@ const docTemplate = `{
                     "example": "Yandex Plus"
                 },
                 "start_date": {
-                    "description": "start date",
+                    "description": "start date (YYYY-MM-DD or MM-YYYY)",
                     "type": "string",
-                    "example": "07-2025"
+                    "example": "2025-07-17"
                 },
                 "user_id": {
                     "description": "user uuid",
@@ -327,19 +3110,65 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.inSubsPause": {
+            "description": "inSubsPause is body input data with paused months.",
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "the last paused month (until resume if it is absent)",
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "start_date": {
+                    "description": "the first paused month (the current month by default)",
+                    "type": "string",
+                    "example": "07-2025"
+                }
+            }
+        },
         "v1.inSubsUpdate": {
             "description": "inSubsUpdate is body input data with optional subs data.",
             "type": "object",
             "properties": {
+                "billing_day": {
+                    "description": "day of month of charges (new start date day if start date is changed)",
+                    "type": "integer",
+                    "maximum": 31,
+                    "minimum": 1,
+                    "example": 17
+                },
+                "billing_interval": {
+                    "description": "number of billing periods between charges",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "billing_period": {
+                    "description": "billing period",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "currency": {
+                    "description": "price currency (ISO 4217)",
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
-                    "description": "end date",
+                    "description": "end date (YYYY-MM-DD or MM-YYYY, the whole month is included)",
                     "type": "string",
                     "example": "08-2025"
                 },
                 "price": {
-                    "description": "price",
-                    "type": "integer",
-                    "example": 400
+                    "description": "price for one billing period (decimal string in major units)",
+                    "type": "string",
+                    "example": "199.99"
                 },
                 "service_name": {
                     "description": "service name",
@@ -348,9 +3177,9 @@ const docTemplate = `{
                     "example": "Yandex Plus"
                 },
                 "start_date": {
-                    "description": "start date",
+                    "description": "start date (YYYY-MM-DD or MM-YYYY)",
                     "type": "string",
-                    "example": "07-2025"
+                    "example": "2025-07-17"
                 },
                 "user_id": {
                     "description": "user uuid",
@@ -358,6 +3187,93 @@ const docTemplate = `{
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
+        },
+        "v1.inWebhookCreate": {
+            "description": "inWebhookCreate is body input data with webhook data.",
+            "type": "object",
+            "required": [
+                "event_types",
+                "secret",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "events are sent only to active webhooks (true by default)",
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "description": "event types to send",
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subs.created",
+                        "subs.deleted"
+                    ]
+                },
+                "secret": {
+                    "description": "secret to sign payload with HMAC-SHA256",
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 16,
+                    "example": "0123456789abcdef"
+                },
+                "url": {
+                    "description": "receiver URL",
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "https://example.com/hooks/subs"
+                }
+            }
+        },
+        "v1.inWebhookUpdate": {
+            "description": "inWebhookUpdate is body input data with optional webhook data.",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "events are sent only to active webhooks",
+                    "type": "boolean",
+                    "example": false
+                },
+                "event_types": {
+                    "description": "event types to send",
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subs.created",
+                        "subs.deleted"
+                    ]
+                },
+                "secret": {
+                    "description": "secret to sign payload with HMAC-SHA256",
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 16,
+                    "example": "0123456789abcdef"
+                },
+                "url": {
+                    "description": "receiver URL",
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "https://example.com/hooks/subs"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT (HS256 или RS256) в формате \"Bearer \u003ctoken\u003e\" или API-ключ в формате \"ApiKey \u003ckey\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"fmt"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+// APIKeysController is a HTTP-controller for API keys usecase.
+type APIKeysController struct {
+	apiKeysUC usecase.APIKeysUsecase
+	valid     validator.Validator
+}
+
+// NewAPIKeysController returns new APIKeysController.
+func NewAPIKeysController(
+	apiKeysUC usecase.APIKeysUsecase,
+	valid validator.Validator,
+) *APIKeysController {
+	return &APIKeysController{
+		apiKeysUC: apiKeysUC,
+		valid:     valid,
+	}
+}
+
+// @summary		Создать API-ключ
+// @description	Создание нового API-ключа (только для администратора). Ключ возвращается только один раз.
+// @router			/api-keys [post]
+// @id				create-api-key
+// @tags			api-keys
+// @security		BearerAuth
+// @param			Key	body		inAPIKeyCreate	true	"Информация о ключе"
+// @success		201	{object}	entity.APIKeyCreated
+// @failure		400	"Невалидное тело запроса"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *APIKeysController) Create(ctx *fiber.Ctx) error {
+	bodyData := &inAPIKeyCreate{}
+	// parse body
+	if err := ctx.BodyParser(bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	key := entity.APIKey{
+		Name:      bodyData.Name,
+		Scopes:    bodyData.Scopes,
+		OwnerID:   bodyData.OwnerID,
+		ExpiresAt: bodyData.ExpiresAt,
+	}
+	// create key
+	createdKey, err := c.apiKeysUC.Create(ctx.UserContext(), &key)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(createdKey)
+}
+
+// @summary		Получить API-ключи
+// @description	Получение всех API-ключей без их значений (только для администратора).
+// @router			/api-keys [get]
+// @id				get-all-api-keys
+// @tags			api-keys
+// @security		BearerAuth
+// @success		200	{array}	entity.APIKey
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *APIKeysController) GetAll(ctx *fiber.Ctx) error {
+	keyList, err := c.apiKeysUC.GetAll(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(keyList)
+}
+
+// @summary		Отозвать API-ключ
+// @description	Отзыв API-ключа по его ID (только для администратора).
+// @router			/api-keys/{id} [delete]
+// @id				revoke-api-key
+// @tags			api-keys
+// @security		BearerAuth
+// @param			id	path	string	true	"UUID ключа"
+// @success		204	"Успешный отзыв"
+// @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		404	"Ключ не найден"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *APIKeysController) Revoke(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// revoke key
+	if err := c.apiKeysUC.Revoke(ctx.UserContext(), pathData.ID); err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
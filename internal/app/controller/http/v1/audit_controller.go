@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"fmt"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+// AuditController is a HTTP-controller for audit usecase.
+type AuditController struct {
+	auditUC usecase.AuditUsecase
+	valid   validator.Validator
+}
+
+// NewAuditController returns new AuditController.
+func NewAuditController(auditUC usecase.AuditUsecase, valid validator.Validator) *AuditController {
+	return &AuditController{
+		auditUC: auditUC,
+		valid:   valid,
+	}
+}
+
+// @summary		Получить журнал изменений подписок
+// @description	Получение журнала изменений всех подписок от новых к старым с фильтрацией и пагинацией (только для администратора).
+// @router			/audit [get]
+// @id				get-audit
+// @tags			audit
+// @security		BearerAuth
+// @param			subs_id		query	string	false	"UUID подписки"
+// @param			user_id		query	string	false	"UUID владельца подписки"
+// @param			actor_id	query	string	false	"UUID автора изменения"
+// @param			action		query	string	false	"Действие"	Enums(create, update, delete, restore, pause, resume)
+// @param			from		query	string	false	"Минимальное время изменения в RFC 3339 (включительно)"	example:"2025-07-01T00:00:00Z"
+// @param			to			query	string	false	"Максимальное время изменения в RFC 3339 (не включительно)"	example:"2025-08-01T00:00:00Z"
+// @param			limit		query	int		false	"Размер страницы"	minimum(1)	maximum(1000)	default(50)
+// @param			offset		query	int		false	"Смещение"
+// @success		200			{array}	entity.AuditEntry
+// @failure		400			"Невалидный(ые) параметр(ы) запроса"
+// @failure		401			"Не авторизован"
+// @failure		403			"Нет прав администратора"
+// @failure		504			"Превышено время выполнения запроса к БД"
+func (c *AuditController) GetAll(ctx *fiber.Ctx) error {
+	queryData := newInAuditFilter()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse times
+	if err := queryData.ParseTimes(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	filter := entity.AuditFilter{
+		SubsID:  queryData.SubsID,
+		UserID:  queryData.UserID,
+		ActorID: queryData.ActorID,
+		Action:  entity.AuditAction(queryData.Action),
+		From:    queryData.FromParsed,
+		To:      queryData.ToParsed,
+		Limit:   queryData.Limit,
+		Offset:  queryData.Offset,
+	}
+	// get audit log
+	auditList, err := c.auditUC.GetAll(ctx.UserContext(), &filter)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(auditList)
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"fmt"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// batchResults collects results of the batch items failed in controller
+// and results of the items passed to usecase.
+type batchResults struct {
+	results entity.SubscriptionBatchResultList
+	passed  []int // batch indexes of the items passed to usecase
+}
+
+// newBatchResults returns new batchResults for the batch with the given size.
+func newBatchResults(size int) *batchResults {
+	return &batchResults{
+		results: make(entity.SubscriptionBatchResultList, size),
+		passed:  make([]int, 0, size),
+	}
+}
+
+// fail sets error of the batch item with the given index and subs ID (if it is known).
+func (b *batchResults) fail(i int, id string, err error) {
+	b.results[i] = entity.SubscriptionBatchResult{Index: i, ID: id, Err: err}
+}
+
+// pass marks the batch item with the given index as passed to usecase.
+func (b *batchResults) pass(i int) {
+	b.passed = append(b.passed, i)
+}
+
+// merge puts usecase results of the passed items into batch results and sets statuses
+// of all items (okStatus for successful items) and error messages of the failed ones.
+func (b *batchResults) merge(
+	results entity.SubscriptionBatchResultList,
+	okStatus int,
+) entity.SubscriptionBatchResultList {
+	for j, result := range results {
+		result.Index = b.passed[j]
+		b.results[result.Index] = result
+	}
+	for i := range b.results {
+		result := &b.results[i]
+		if result.Err != nil {
+			result.Status = errors.ErrorCode(result.Err)
+			result.Error = result.Err.Error()
+			continue
+		}
+		result.Status = okStatus
+	}
+	return b.results
+}
+
+// batchStatus returns response status of the batch: okStatus for atomic batch
+// and Multi-Status for non-atomic one with statuses of the items.
+func batchStatus(atomic bool, okStatus int) int {
+	if atomic {
+		return okStatus
+	}
+	return fiber.StatusMultiStatus
+}
+
+// checkBatchSize returns ErrValidateData if the batch is empty or too large.
+func checkBatchSize(size int) error {
+	if size == 0 || size > _maxBatchSize {
+		return fmt.Errorf("%w: batch must contain from 1 to %d items",
+			errors.ErrValidateData, _maxBatchSize)
+	}
+	return nil
+}
+
+// parseSubsBatchUpdate validates input subs data of the batch item
+// and returns subs update with it. Like If-Match header of single subs update
+// the item version is required if requireIfMatch is set (ErrNoPrecondition).
+func (c *SubsController) parseSubsBatchUpdate(
+	bodyData *inSubsBatchUpdate,
+) (*entity.SubscriptionUpdate, error) {
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	if c.requireIfMatch && bodyData.Version == nil {
+		return nil, fmt.Errorf("%w: version is required", errors.ErrNoPrecondition)
+	}
+	return c.parseSubsUpdate(&bodyData.inSubsUpdate, bodyData.ID, bodyData.Version)
+}
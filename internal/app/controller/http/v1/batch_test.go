@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+// fakeSubsUsecase is SubsUsecase which updates all the passed subs.
+type fakeSubsUsecase struct {
+	usecase.SubsUsecase
+	updated []entity.SubscriptionUpdate
+}
+
+func (f *fakeSubsUsecase) UpdateBatch(_ context.Context, updates []entity.SubscriptionUpdate,
+	_ bool) (entity.SubscriptionBatchResultList, error) {
+	f.updated = append(f.updated, updates...)
+	results := make(entity.SubscriptionBatchResultList, len(updates))
+	for i, update := range updates {
+		results[i] = entity.SubscriptionBatchResult{ID: update.ID}
+	}
+	return results, nil
+}
+
+// updateBatch sends batch update request to app with SubsController
+// and returns response status and body.
+func updateBatch(t *testing.T, subsUC *fakeSubsUsecase, requireIfMatch bool,
+	query, body string) (int, string) {
+	t.Helper()
+
+	c := NewSubsController(subsUC, validator.New(), requireIfMatch, time.Minute)
+	app := fiber.New(fiber.Config{ErrorHandler: errors.CustomErrorHandler})
+	app.Patch("/subs/batch", c.UpdateBatch)
+	req := httptest.NewRequest(http.MethodPatch, "/subs/batch"+query, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody)
+}
+
+func TestSubsController_UpdateBatchVersion(t *testing.T) {
+	t.Log("Require version of batch update items if If-Match header is required")
+
+	const body = `[
+		{"id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "price": "100", "version": 1},
+		{"id": "6e3b1f4c-4b8e-4c1a-9d8e-2a3f5b6c7d8e", "price": "200"}
+	]`
+
+	t.Run("atomic", func(t *testing.T) {
+		subsUC := &fakeSubsUsecase{}
+		status, respBody := updateBatch(t, subsUC, true, "", body)
+		require.Equal(t, fiber.StatusPreconditionRequired, status)
+		require.Contains(t, respBody, "item 1")
+		require.Empty(t, subsUC.updated)
+	})
+
+	t.Run("non-atomic", func(t *testing.T) {
+		subsUC := &fakeSubsUsecase{}
+		status, respBody := updateBatch(t, subsUC, true, "?atomic=false", body)
+		require.Equal(t, fiber.StatusMultiStatus, status)
+		var results entity.SubscriptionBatchResultList
+		require.NoError(t, json.Unmarshal([]byte(respBody), &results))
+		require.Len(t, results, 2)
+		require.Equal(t, fiber.StatusOK, results[0].Status)
+		require.Equal(t, fiber.StatusPreconditionRequired, results[1].Status)
+		require.Equal(t, "6e3b1f4c-4b8e-4c1a-9d8e-2a3f5b6c7d8e", results[1].ID)
+		require.Len(t, subsUC.updated, 1)
+	})
+
+	t.Run("not required", func(t *testing.T) {
+		subsUC := &fakeSubsUsecase{}
+		status, _ := updateBatch(t, subsUC, false, "", body)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Len(t, subsUC.updated, 2)
+	})
+}
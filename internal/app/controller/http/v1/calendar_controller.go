@@ -0,0 +1,253 @@
+package v1
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/ical"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+// Calendar params.
+const (
+	_calendarProdID  = "-//Subscription Aggregator//Charges//RU"
+	_calendarName    = "Списания по подпискам"
+	_calendarUIDHost = "subscription-aggregator" // host part of the events UIDs
+	// path of the user calendar relative to API root
+	_calendarPathFmt = "/users/%s/charges.ics"
+	_minMonthDays    = 28 // number of days in the shortest month
+)
+
+// calendarRecurrence is a recurrence of the billing period charges.
+type calendarRecurrence struct {
+	freq ical.Frequency
+	// number of frequency periods in one billing period
+	periods int
+}
+
+// Recurrences of charges of billing periods.
+var _calendarRecurrences = map[entity.BillingPeriod]calendarRecurrence{
+	entity.BillingWeekly:    {ical.Weekly, 1},
+	entity.BillingMonthly:   {ical.Monthly, 1},
+	entity.BillingQuarterly: {ical.Monthly, 3},
+	entity.BillingYearly:    {ical.Yearly, 1},
+}
+
+// CalendarController is a HTTP-controller for calendar usecase.
+type CalendarController struct {
+	calendarUC usecase.CalendarUsecase
+	valid      validator.Validator
+	// API root path which calendar URL is relative to
+	apiPath string
+}
+
+// NewCalendarController returns new CalendarController.
+func NewCalendarController(
+	calendarUC usecase.CalendarUsecase,
+	valid validator.Validator,
+	apiPath string,
+) *CalendarController {
+	return &CalendarController{
+		calendarUC: calendarUC,
+		valid:      valid,
+		apiPath:    apiPath,
+	}
+}
+
+// @summary		Получить ссылку на календарь списаний
+// @description	Получение секретного токена и ссылки на календарь (iCalendar) списаний
+// @description	по подпискам пользователя для подписки на него в приложении календаря.
+// @description	Пользователь может получить только свой токен,
+// @description	администратор — токен любого пользователя.
+// @router			/users/{user_id}/calendar-token [get]
+// @id				get-calendar-token
+// @tags			calendar
+// @security		BearerAuth
+// @param			user_id	path		string	true	"UUID пользователя"
+// @success		200		{object}	entity.CalendarToken
+// @failure		400		"Невалидный параметр запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет доступа к календарю другого пользователя"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *CalendarController) GetToken(ctx *fiber.Ctx) error {
+	pathData := &inPathUserID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	token, err := c.calendarUC.Token(ctx.UserContext(), pathData.UserID)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(c.calendarToken(ctx, pathData.UserID, token))
+}
+
+// @summary		Перевыпустить ссылку на календарь списаний
+// @description	Выпуск нового секретного токена и ссылки на календарь списаний пользователя.
+// @description	Прежняя ссылка отзывается и перестаёт работать.
+// @description	Пользователь может перевыпустить только свой токен,
+// @description	администратор — токен любого пользователя.
+// @router			/users/{user_id}/calendar-token [post]
+// @id				regenerate-calendar-token
+// @tags			calendar
+// @security		BearerAuth
+// @param			user_id	path		string	true	"UUID пользователя"
+// @success		200		{object}	entity.CalendarToken
+// @failure		400		"Невалидный параметр запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет доступа к календарю другого пользователя"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *CalendarController) RegenerateToken(ctx *fiber.Ctx) error {
+	pathData := &inPathUserID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	token, err := c.calendarUC.RegenerateToken(ctx.UserContext(), pathData.UserID)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(c.calendarToken(ctx, pathData.UserID, token))
+}
+
+// calendarToken returns calendar token of the user with URL of his calendar.
+func (c *CalendarController) calendarToken(
+	ctx *fiber.Ctx,
+	userID, token string,
+) *entity.CalendarToken {
+	calendarURL := ctx.BaseURL() + c.apiPath + fmt.Sprintf(_calendarPathFmt, userID) +
+		"?token=" + url.QueryEscape(token)
+	return &entity.CalendarToken{
+		UserID: userID,
+		Token:  token,
+		URL:    calendarURL,
+	}
+}
+
+// @summary		Получить календарь списаний
+// @description	Календарь (iCalendar, RFC 5545) списаний по подпискам пользователя, которые
+// @description	не завершились до текущего месяца. Каждая подписка — повторяющееся событие
+// @description	на весь день с правилом повторения по периоду оплаты, датой окончания подписки
+// @description	и исключёнными месяцами приостановки. UID события постоянен для подписки,
+// @description	поэтому приложения календаря обновляют события, а не дублируют их.
+// @description	Доступ проверяется по токену пользователя из ссылки (отозванный перевыпуском
+// @description	токен не действует), заголовок Authorization не нужен.
+// @router			/users/{user_id}/charges.ics [get]
+// @id				get-charges-calendar
+// @tags			calendar
+// @produce		text/calendar
+// @param			user_id	path		string	true	"UUID пользователя"
+// @param			token	query		string	true	"Токен календаря пользователя"
+// @success		200		{string}	string	"Календарь списаний"
+// @failure		400		"Невалидный(ые) параметр(ы) запроса"
+// @failure		404		"Неверный токен календаря"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *CalendarController) GetCharges(ctx *fiber.Ctx) error {
+	pathData := &inPathUserID{}
+	queryData := &inCalendarToken{}
+	// parse path-params and query-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	scheduleList, err := c.calendarUC.GetSchedules(ctx.UserContext(),
+		pathData.UserID, queryData.Token)
+	if err != nil {
+		return err
+	}
+	calendar := &ical.Calendar{
+		ProdID: _calendarProdID,
+		Name:   _calendarName,
+		Events: make([]ical.Event, 0, len(scheduleList)),
+	}
+	for i := range scheduleList {
+		calendar.Events = append(calendar.Events, newChargesEvent(&scheduleList[i]))
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.MIMEType)
+	return calendar.Encode(ctx, time.Now())
+}
+
+// newChargesEvent returns recurring calendar event of the subs charges.
+// The rule is expanded from the start date (the first charge), so its occurrence
+// on the billing day of the start month after the start date is excluded:
+// the next charge is in the next billing period.
+func newChargesEvent(schedule *entity.SubscriptionSchedule) ical.Event {
+	recurrence := newChargesRecurrence(schedule)
+	exDates := schedule.Skipped
+	if len(recurrence.ByMonthDays) != 0 {
+		start := *schedule.StartDate
+		monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		lastDay := monthStart.AddDate(0, 1, -1).Day()
+		monthCharge := monthStart.AddDate(0, 0, min(schedule.BillingDay, lastDay)-1)
+		if monthCharge.After(start) {
+			exDates = append([]time.Time{monthCharge}, exDates...)
+		}
+	}
+	return ical.Event{
+		UID:      schedule.ID + "@" + _calendarUIDHost,
+		Sequence: schedule.Version,
+		Summary: fmt.Sprintf("%s: %s %s", schedule.ServiceName,
+			schedule.Price.String(), schedule.Price.Currency),
+		Date:       *schedule.StartDate,
+		Recurrence: recurrence,
+		ExDates:    exDates,
+	}
+}
+
+// newChargesRecurrence returns recurrence of the subs charges.
+// Charges on the billing day which differs from the start date day or is absent
+// in short months are set by days of month from the 28th to the billing day
+// with the last existing one chosen, so they are on the last day of the shorter month.
+func newChargesRecurrence(schedule *entity.SubscriptionSchedule) *ical.Recurrence {
+	calRecurrence := _calendarRecurrences[schedule.BillingPeriod]
+	recurrence := &ical.Recurrence{
+		Freq:     calRecurrence.freq,
+		Interval: calRecurrence.periods * schedule.BillingInterval,
+		Until:    schedule.Until,
+	}
+	billingDay := schedule.BillingDay
+	if recurrence.Freq == ical.Weekly || billingDay == 0 ||
+		(billingDay == schedule.StartDate.Day() && billingDay <= _minMonthDays) {
+		return recurrence
+	}
+
+	if recurrence.Freq == ical.Yearly {
+		recurrence.ByMonth = schedule.StartDate.Month()
+	}
+	if billingDay <= _minMonthDays {
+		recurrence.ByMonthDays = []int{billingDay}
+		return recurrence
+	}
+	for day := _minMonthDays; day <= billingDay; day++ {
+		recurrence.ByMonthDays = append(recurrence.ByMonthDays, day)
+	}
+	recurrence.BySetPos = -1
+	return recurrence
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/pkg/ical"
+)
+
+// date returns pointer to the given date.
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+// expand returns occurrences of the recurring event not after the given date
+// like calendar clients do (RFC 5545): the event date is the first occurrence
+// and the rule is expanded from it. Only rules of charges events are supported.
+func expand(event *ical.Event, to time.Time) []time.Time {
+	rule := event.Recurrence
+	if rule.Until != nil && rule.Until.Before(to) {
+		to = *rule.Until
+	}
+	interval := max(rule.Interval, 1)
+	start := event.Date
+	month := start.Month()
+	if rule.ByMonth != 0 {
+		month = rule.ByMonth
+	}
+	periodStart := time.Date(start.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	monthDays := rule.ByMonthDays
+	if len(monthDays) == 0 {
+		monthDays = []int{start.Day()}
+	}
+
+	occurrences := []time.Time{start}
+	for k := 0; ; k++ {
+		var dates []time.Time
+		switch rule.Freq {
+		case ical.Weekly:
+			dates = []time.Time{start.AddDate(0, 0, 7*interval*k)}
+		case ical.Monthly, ical.Yearly:
+			period := periodStart.AddDate(0, interval*k, 0)
+			if rule.Freq == ical.Yearly {
+				period = periodStart.AddDate(interval*k, 0, 0)
+			}
+			if period.After(to) {
+				return excludeDates(occurrences, event.ExDates)
+			}
+			for _, day := range monthDays {
+				// invalid dates (e.g. the 30th of February) are skipped
+				if d := period.AddDate(0, 0, day-1); d.Month() == period.Month() {
+					dates = append(dates, d)
+				}
+			}
+			if rule.BySetPos == -1 && len(dates) != 0 {
+				dates = dates[len(dates)-1:]
+			}
+		}
+		for _, d := range dates {
+			if d.After(to) {
+				return excludeDates(occurrences, event.ExDates)
+			}
+			if d.After(start) {
+				occurrences = append(occurrences, d)
+			}
+		}
+	}
+}
+
+// excludeDates returns dates without the excluded ones.
+func excludeDates(dates, excluded []time.Time) []time.Time {
+	return slices.DeleteFunc(dates, func(d time.Time) bool {
+		return slices.ContainsFunc(excluded, d.Equal)
+	})
+}
+
+func TestNewChargesEvent_Expansion(t *testing.T) {
+	t.Log("Expand charges event to the charges of the subs schedule")
+
+	tests := []struct {
+		name   string
+		subs   entity.Subscription
+		pauses entity.SubscriptionPauseList
+	}{
+		{
+			name: "billing day after start day",
+			subs: entity.Subscription{BillingPeriod: entity.BillingMonthly,
+				StartDate: date(2025, time.January, 5), BillingDay: 20},
+		},
+		{
+			name: "billing day before start day",
+			subs: entity.Subscription{BillingPeriod: entity.BillingMonthly,
+				StartDate: date(2025, time.January, 20), BillingDay: 5},
+		},
+		{
+			name: "billing day absent in short months",
+			subs: entity.Subscription{BillingPeriod: entity.BillingMonthly,
+				StartDate: date(2025, time.January, 5), BillingDay: 31},
+		},
+		{
+			name: "billing day on start day absent in short months",
+			subs: entity.Subscription{BillingPeriod: entity.BillingMonthly,
+				StartDate: date(2025, time.January, 30), BillingDay: 30},
+		},
+		{
+			name: "quarterly",
+			subs: entity.Subscription{BillingPeriod: entity.BillingQuarterly,
+				StartDate: date(2025, time.February, 1), BillingDay: 15},
+		},
+		{
+			name: "yearly on leap day",
+			subs: entity.Subscription{BillingPeriod: entity.BillingYearly,
+				StartDate: date(2024, time.February, 10), BillingDay: 29},
+		},
+		{
+			name: "weekly",
+			subs: entity.Subscription{BillingPeriod: entity.BillingWeekly, BillingInterval: 2,
+				StartDate: date(2025, time.January, 5), BillingDay: 20},
+		},
+		{
+			name: "paused with end date",
+			subs: entity.Subscription{BillingPeriod: entity.BillingMonthly,
+				StartDate: date(2025, time.January, 5), BillingDay: 20,
+				EndDate: date(2025, time.October, 1)},
+			pauses: entity.SubscriptionPauseList{{
+				StartDate: date(2025, time.March, 1), EndDate: date(2025, time.April, 1),
+			}},
+		},
+	}
+	to := *date(2027, time.December, 31)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs := tt.subs
+			subs.ID = "subs"
+			subs.BillingInterval = max(subs.BillingInterval, 1)
+			schedule, ok := entity.NewSubscriptionSchedule(&subs, tt.pauses)
+			require.True(t, ok)
+
+			event := newChargesEvent(schedule)
+			require.Equal(t, schedule.Charges(*subs.StartDate, to), expand(&event, to))
+		})
+	}
+}
@@ -3,65 +3,112 @@
 package v1
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"time"
 
 	fiber "github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 
 	"SubscriptionAggregator/internal/app/entity"
 	"SubscriptionAggregator/internal/app/errors"
 	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/jsonpatch"
 	"SubscriptionAggregator/internal/pkg/validator"
 )
 
 // SubsController is a HTTP-controller for subs usecase.
 type SubsController struct {
-	subsUC usecase.SubsUsecase
-	valid  validator.Validator
+	subsUC         usecase.SubsUsecase
+	valid          validator.Validator
+	requireIfMatch bool
+	exportTimeout  time.Duration
 }
 
 // NewSubsController returns new SubsController.
-func NewSubsController(subsUC usecase.SubsUsecase, valid validator.Validator) *SubsController {
+// If requireIfMatch is true subs update without If-Match header is rejected.
+// Streaming of subs export is canceled after exportTimeout.
+func NewSubsController(
+	subsUC usecase.SubsUsecase,
+	valid validator.Validator,
+	requireIfMatch bool,
+	exportTimeout time.Duration,
+) *SubsController {
 	return &SubsController{
-		subsUC: subsUC,
-		valid:  valid,
+		subsUC:         subsUC,
+		valid:          valid,
+		requireIfMatch: requireIfMatch,
+		exportTimeout:  exportTimeout,
 	}
 }
 
 // @summary		Создать запись подписки
-// @description	Создание новой записи подписки.
+// @description	Создание новой записи подписки. Даты задаются с точностью до дня (YYYY-MM-DD)
+// @description	или до месяца (MM-YYYY), точность возвращается в полях start_date_precision
+// @description	и end_date_precision. Дата окончания с точностью до месяца включает весь месяц.
+// @description	Списания, кроме еженедельных, приходятся на день billing_day (по умолчанию день
+// @description	даты начала), в коротких месяцах — на последний день месяца.
 // @router			/subs [post]
 // @id				create-sub
 // @tags			subs-crudl
-// @param			Sub	body		inSubsCreate	true	"Информация о подписке"
-// @success		201	{object}	entity.Subscription
-// @failure		400	"Невалидное тело запроса"
+// @security		BearerAuth
+// @param			Idempotency-Key	header		string			false	"Ключ идемпотентности (повторный запрос с ним вернёт сохранённый ответ)"
+// @param			Sub				body		inSubsCreate	true	"Информация о подписке"
+// @success		201				{object}	entity.Subscription
+// @header			201				{string}	ETag				"Версия подписки"
+// @header			201				{string}	Idempotent-Replayed	"true, если ответ повторён по ключу идемпотентности"
+// @failure		400				{object}	errors.ValidationErrorResponse	"Невалидное тело запроса"
+// @failure		401				"Не авторизован"
+// @failure		403				"Нет доступа к подпискам другого пользователя"
+// @failure		409				"Запрос с этим ключом идемпотентности ещё выполняется"
+// @failure		422				"Ключ идемпотентности использован с другим запросом"
+// @failure		504				"Превышено время выполнения запроса к БД"
 func (c *SubsController) Create(ctx *fiber.Ctx) error {
 	bodyData := &inSubsCreate{}
 	// parse body
 	if err := ctx.BodyParser(bodyData); err != nil {
 		return fmt.Errorf("parse body: %w", err)
 	}
+	subs, err := c.parseSubsCreate(bodyData)
+	if err != nil {
+		return err
+	}
+	// create subs
+	if err := c.subsUC.Create(ctx.UserContext(), subs); err != nil {
+		return err
+	}
+	setSubsETag(ctx, subs)
+	return ctx.Status(fiber.StatusCreated).JSON(subs)
+}
+
+// parseSubsCreate validates input subs data and returns new subs with it.
+func (c *SubsController) parseSubsCreate(bodyData *inSubsCreate) (*entity.Subscription, error) {
 	// validate parsed data
 	if err := c.valid.Validate(bodyData); err != nil {
-		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
 	// parse dates
 	if err := bodyData.ParseDates(); err != nil {
-		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
-
-	subs := entity.Subscription{
-		ServiceName: bodyData.ServiceName,
-		Price:       bodyData.Price,
-		UserID:      bodyData.UserID,
-		StartDate:   bodyData.StartDateParsed,
-		EndDate:     bodyData.EndDateParsed,
+	// parse price
+	if err := bodyData.ParsePrice(); err != nil {
+		return nil, err
 	}
-	// create subs
-	if err := c.subsUC.Create(&subs); err != nil {
-		return err
-	}
-	return ctx.Status(fiber.StatusCreated).JSON(subs)
+
+	return &entity.Subscription{
+		ServiceName:        bodyData.ServiceName,
+		Price:              bodyData.PriceParsed,
+		BillingPeriod:      entity.BillingPeriod(bodyData.BillingPeriod),
+		BillingInterval:    bodyData.BillingInterval,
+		UserID:             bodyData.UserID,
+		StartDate:          bodyData.StartDateParsed,
+		StartDatePrecision: bodyData.StartDatePrecision,
+		EndDate:            bodyData.EndDateParsed,
+		EndDatePrecision:   bodyData.EndDatePrecision,
+		BillingDay:         bodyData.BillingDay,
+	}, nil
 }
 
 // @summary		Получить запись подписки
@@ -69,10 +116,18 @@ func (c *SubsController) Create(ctx *fiber.Ctx) error {
 // @router			/subs/{id} [get]
 // @id				get-sub
 // @tags			subs-crudl
-// @param			id	path		string	true	"UUID подписки"
-// @success		200	{object}	entity.Subscription
-// @failure		400	"Невалидный параметр запроса"
-// @failure		404	"Подписка не найдена"
+// @security		BearerAuth
+// @param			id				path		string	true	"UUID подписки"
+// @param			include_deleted	query		bool	false	"Включая удалённую подписку (только для администратора)"
+// @param			If-None-Match	header		string	false	"ETag известной клиенту версии подписки"
+// @success		200				{object}	entity.Subscription
+// @header			200				{string}	ETag	"Версия подписки"
+// @success		304				"Подписка не изменилась"
+// @failure		400				"Невалидный параметр запроса"
+// @failure		404				"Подписка не найдена"
+// @failure		401				"Не авторизован"
+// @failure		403				"Нет прав администратора для удалённой подписки"
+// @failure		504				"Превышено время выполнения запроса к БД"
 func (c *SubsController) GetByID(ctx *fiber.Ctx) error {
 	pathData := &inPathUUID{}
 	// parse path-params
@@ -83,26 +138,41 @@ func (c *SubsController) GetByID(ctx *fiber.Ctx) error {
 	if err := c.valid.Validate(pathData); err != nil {
 		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
+	queryData := &inIncludeDeleted{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
 
 	// get subs
-	subs, err := c.subsUC.GetByID(pathData.ID)
+	subs, err := c.subsUC.GetByID(ctx.UserContext(), pathData.ID, queryData.IncludeDeleted)
 	if err != nil {
 		return err
 	}
+	setSubsETag(ctx, subs)
+	// if client has the same subs version
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
 	return ctx.Status(fiber.StatusOK).JSON(subs)
 }
 
-// @summary		Обновить запись подписки
-// @description	Обновление записи подписки по её ID.
-// @router			/subs/{id} [patch]
-// @id				update-sub
+// @summary		Получить историю изменений подписки
+// @description	Получение журнала изменений подписки по её ID от новых к старым.
+// @description	Каждая запись содержит автора, действие, состояния до и после изменения и ID запроса.
+// @router			/subs/{id}/history [get]
+// @id				get-sub-history
 // @tags			subs-crudl
-// @param			id	path		string			true	"UUID подписки"
-// @param			Sub	body		inSubsUpdate	true	"Информация о подписке"
-// @success		200	{object}	entity.Subscription
-// @failure		400	"Невалидный параметр или тело запроса"
-// @failure		404	"Подписка не найдена"
-func (c *SubsController) Update(ctx *fiber.Ctx) error {
+// @security		BearerAuth
+// @param			id		path	string	true	"UUID подписки"
+// @param			limit	query	int		false	"Размер страницы"	minimum(1)	maximum(1000)	default(50)
+// @param			offset	query	int		false	"Смещение"
+// @success		200		{array}	entity.AuditEntry
+// @failure		400		"Невалидный(ые) параметр(ы) запроса"
+// @failure		401		"Не авторизован"
+// @failure		404		"Подписка не найдена"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) GetHistory(ctx *fiber.Ctx) error {
 	pathData := &inPathUUID{}
 	// parse path-params
 	if err := ctx.ParamsParser(pathData); err != nil {
@@ -112,44 +182,104 @@ func (c *SubsController) Update(ctx *fiber.Ctx) error {
 	if err := c.valid.Validate(pathData); err != nil {
 		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
-	bodyData := &inSubsUpdate{}
-	// parse body
-	if err := ctx.BodyParser(bodyData); err != nil {
-		return fmt.Errorf("parse body: %w", err)
+	queryData := newInAuditPagination()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
 	}
 	// validate parsed data
-	if err := c.valid.Validate(bodyData); err != nil {
+	if err := c.valid.Validate(queryData); err != nil {
 		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
-	// parse dates
-	if err := bodyData.ParseDates(); err != nil {
+
+	filter := entity.AuditFilter{
+		SubsID: pathData.ID,
+		Limit:  queryData.Limit,
+		Offset: queryData.Offset,
+	}
+	// get subs history
+	auditList, err := c.subsUC.GetHistory(ctx.UserContext(), &filter)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(auditList)
+}
+
+// @summary		Обновить запись подписки
+// @description	Обновление записи подписки по её ID.
+// @description	С типом application/json обновляются только переданные поля.
+// @description	С типом application/merge-patch+json тело является JSON Merge Patch (RFC 7386):
+// @description	null очищает поле (например, end_date).
+// @description	С типом application/json-patch+json тело является списком операций JSON Patch (RFC 6902).
+// @description	Результат патча проверяется целиком, включая даты начала и окончания.
+// @router			/subs/{id} [patch]
+// @id				update-sub
+// @tags			subs-crudl
+// @security		BearerAuth
+// @param			id			path		string			true	"UUID подписки"
+// @param			If-Match	header		string			false	"ETag текущей версии подписки (обязателен в строгом режиме)"
+// @param			Sub			body		inSubsUpdate	true	"Информация о подписке или патч"
+// @accept			json,application/merge-patch+json,application/json-patch+json
+// @success		200			{object}	entity.Subscription
+// @header			200			{string}	ETag	"Новая версия подписки"
+// @failure		400			{object}	errors.ValidationErrorResponse	"Невалидный параметр или тело запроса"
+// @failure		404			"Подписка не найдена"
+// @failure		401			"Не авторизован"
+// @failure		403			"Нет доступа к подпискам другого пользователя"
+// @failure		409			"Не выполнена операция test в JSON Patch"
+// @failure		412			"Подписка изменилась (версия не совпадает с If-Match)"
+// @failure		428			"Не передан заголовок If-Match"
+// @failure		504			"Превышено время выполнения запроса к БД"
+func (c *SubsController) Update(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
 		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
+	// parse expected version
+	version, err := parseIfMatch(ctx, c.requireIfMatch)
+	if err != nil {
+		return err
+	}
 
-	subs := entity.SubscriptionUpdate{
-		ID:          pathData.ID,
-		ServiceName: bodyData.ServiceName,
-		Price:       bodyData.Price,
-		UserID:      bodyData.UserID,
-		StartDate:   bodyData.StartDateParsed,
-		EndDate:     bodyData.EndDateParsed,
+	var subs *entity.SubscriptionUpdate
+	switch mediaType(ctx) {
+	case jsonpatch.MIMEMergePatch, jsonpatch.MIMEJSONPatch:
+		subs, err = c.patchedSubsUpdate(ctx, pathData.ID, version)
+	default:
+		subs, err = c.bodySubsUpdate(ctx, pathData.ID, version)
+	}
+	if err != nil {
+		return err
 	}
 	// update subs
-	updatedSubs, err := c.subsUC.Update(&subs)
+	updatedSubs, err := c.subsUC.Update(ctx.UserContext(), subs)
 	if err != nil {
 		return err
 	}
+	setSubsETag(ctx, updatedSubs)
 	return ctx.Status(fiber.StatusOK).JSON(updatedSubs)
 }
 
 // @summary		Удалить запись подписки
 // @description	Удаление записи подписки по её ID.
+// @description	Удалённая подписка хранится до окончательного удаления и может быть восстановлена.
+// @description	Удаление идемпотентно: повторное удаление уже удалённой подписки возвращает 204,
+// @description	а 404 возвращается только для подписки, которой никогда не было.
 // @router			/subs/{id} [delete]
 // @id				delete-sub
 // @tags			subs-crudl
+// @security		BearerAuth
 // @param			id	path	string	true	"UUID подписки"
 // @success		204	"Успешное удаление"
 // @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		404	"Подписка не найдена"
+// @failure		504	"Превышено время выполнения запроса к БД"
 func (c *SubsController) Delete(ctx *fiber.Ctx) error {
 	pathData := &inPathUUID{}
 	// parse path-params
@@ -162,38 +292,629 @@ func (c *SubsController) Delete(ctx *fiber.Ctx) error {
 	}
 
 	// get subs
-	if err := c.subsUC.Delete(pathData.ID); err != nil {
+	if err := c.subsUC.Delete(ctx.UserContext(), pathData.ID); err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// @summary		Создать записи подписок пакетом
+// @description	Создание до 1000 записей подписок одним запросом (многострочной вставкой).
+// @description	При atomic=true (по умолчанию) все подписки создаются в одной транзакции,
+// @description	и ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).
+// @description	При atomic=false невалидные подписки пропускаются, при ошибке БД подписки
+// @description	создаются по одной (каждая в своей точке сохранения), а результат каждой
+// @description	подписки возвращается с её индексом, кодом и ошибкой (код ответа 207).
+// @router			/subs/batch [post]
+// @id				create-subs-batch
+// @tags			subs-batch
+// @security		BearerAuth
+// @param			atomic			query		bool			false	"Все подписки в одной транзакции (по умолчанию true)"
+// @param			Idempotency-Key	header		string			false	"Ключ идемпотентности (повторный запрос с ним вернёт сохранённый ответ)"
+// @param			Subs			body		[]inSubsCreate	true	"Информация о подписках"
+// @success		201				{array}		entity.SubscriptionBatchResult
+// @success		207				{array}		entity.SubscriptionBatchResult
+// @failure		400				{object}	errors.ValidationErrorResponse	"Невалидное тело запроса"
+// @failure		401				"Не авторизован"
+// @failure		403				"Нет доступа к подпискам другого пользователя"
+// @failure		409				"Запрос с этим ключом идемпотентности ещё выполняется"
+// @failure		422				"Ключ идемпотентности использован с другим запросом"
+// @failure		504				"Превышено время выполнения запроса к БД"
+func (c *SubsController) CreateBatch(ctx *fiber.Ctx) error {
+	queryData := &inBatchQuery{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("%w: parse query: %s", errors.ErrValidateData, err.Error())
+	}
+	var bodyData []inSubsCreate
+	// parse body
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	if err := checkBatchSize(len(bodyData)); err != nil {
+		return err
+	}
+
+	atomic := queryData.IsAtomic()
+	batch := newBatchResults(len(bodyData))
+	subsList := make(entity.SubscriptionList, 0, len(bodyData))
+	for i := range bodyData {
+		subs, err := c.parseSubsCreate(&bodyData[i])
+		if err != nil {
+			if atomic {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			batch.fail(i, "", err)
+			continue
+		}
+		batch.pass(i)
+		subsList = append(subsList, *subs)
+	}
+	// create subs
+	results, err := c.subsUC.CreateBatch(ctx.UserContext(), subsList, atomic)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(batchStatus(atomic, fiber.StatusCreated)).
+		JSON(batch.merge(results, fiber.StatusCreated))
+}
+
+// @summary		Обновить записи подписок пакетом
+// @description	Обновление до 1000 записей подписок одним запросом.
+// @description	Каждый элемент содержит ID подписки, обновляемые поля и, при необходимости,
+// @description	ожидаемую версию подписки (version).
+// @description	Если обязателен заголовок If-Match (SERVER_REQUIRE_IF_MATCH), обязательна и version.
+// @description	При atomic=true (по умолчанию) все подписки обновляются в одной транзакции,
+// @description	и ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).
+// @description	При atomic=false каждая подписка обновляется отдельно, а результат каждой
+// @description	возвращается с её индексом, кодом и ошибкой (код ответа 207).
+// @router			/subs/batch [patch]
+// @id				update-subs-batch
+// @tags			subs-batch
+// @security		BearerAuth
+// @param			atomic	query		bool				false	"Все подписки в одной транзакции (по умолчанию true)"
+// @param			Subs	body		[]inSubsBatchUpdate	true	"Информация о подписках"
+// @success		200		{array}		entity.SubscriptionBatchResult
+// @success		207		{array}		entity.SubscriptionBatchResult
+// @failure		400		{object}	errors.ValidationErrorResponse	"Невалидное тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет доступа к подпискам другого пользователя"
+// @failure		404		"Подписка не найдена"
+// @failure		412		"Подписка изменилась (версия не совпадает с version)"
+// @failure		428		"Не указана version"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) UpdateBatch(ctx *fiber.Ctx) error {
+	queryData := &inBatchQuery{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("%w: parse query: %s", errors.ErrValidateData, err.Error())
+	}
+	var bodyData []inSubsBatchUpdate
+	// parse body
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	if err := checkBatchSize(len(bodyData)); err != nil {
+		return err
+	}
+
+	atomic := queryData.IsAtomic()
+	batch := newBatchResults(len(bodyData))
+	updates := make([]entity.SubscriptionUpdate, 0, len(bodyData))
+	for i := range bodyData {
+		subs, err := c.parseSubsBatchUpdate(&bodyData[i])
+		if err != nil {
+			if atomic {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			batch.fail(i, bodyData[i].ID, err)
+			continue
+		}
+		batch.pass(i)
+		updates = append(updates, *subs)
+	}
+	// update subs
+	results, err := c.subsUC.UpdateBatch(ctx.UserContext(), updates, atomic)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(batchStatus(atomic, fiber.StatusOK)).
+		JSON(batch.merge(results, fiber.StatusOK))
+}
+
+// @summary		Удалить записи подписок пакетом
+// @description	Удаление до 1000 записей подписок по их ID одним запросом.
+// @description	При atomic=true (по умолчанию) все подписки удаляются в одной транзакции,
+// @description	и ошибка любой из них отменяет весь пакет (в ошибке указан индекс подписки).
+// @description	При atomic=false каждая подписка удаляется отдельно, а результат каждой
+// @description	возвращается с её индексом, кодом и ошибкой (код ответа 207).
+// @router			/subs/batch [delete]
+// @id				delete-subs-batch
+// @tags			subs-batch
+// @security		BearerAuth
+// @param			atomic	query		bool		false	"Все подписки в одной транзакции (по умолчанию true)"
+// @param			IDs		body		[]string	true	"UUID подписок"
+// @success		200		{array}		entity.SubscriptionBatchResult
+// @success		207		{array}		entity.SubscriptionBatchResult
+// @failure		400		"Невалидный параметр или тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		404		"Подписка не найдена"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) DeleteBatch(ctx *fiber.Ctx) error {
+	queryData := &inBatchQuery{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("%w: parse query: %s", errors.ErrValidateData, err.Error())
+	}
+	var bodyData []string
+	// parse body
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	if err := checkBatchSize(len(bodyData)); err != nil {
+		return err
+	}
+
+	atomic := queryData.IsAtomic()
+	batch := newBatchResults(len(bodyData))
+	ids := make([]string, 0, len(bodyData))
+	for i, id := range bodyData {
+		// validate parsed data
+		if err := c.valid.Validate(&inPathUUID{ID: id}); err != nil {
+			err = fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+			if atomic {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			batch.fail(i, id, err)
+			continue
+		}
+		batch.pass(i)
+		ids = append(ids, id)
+	}
+	// delete subs
+	results, err := c.subsUC.DeleteBatch(ctx.UserContext(), ids, atomic)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(batchStatus(atomic, fiber.StatusOK)).
+		JSON(batch.merge(results, fiber.StatusNoContent))
+}
+
+// @summary		Импортировать записи подписок из CSV
+// @description	Импорт записей подписок из CSV-файла (поле формы file).
+// @description	Первая строка файла — заголовок. По умолчанию колонки называются как поля
+// @description	подписки (service_name, price, currency, billing_period, billing_interval,
+// @description	billing_day, user_id, start_date, end_date), разделитель — запятая,
+// @description	даты — YYYY-MM-DD или MM-YYYY. Названия колонок задаются параметрами column
+// @description	вида поле:колонка, формат дат — параметром date_format в нотации Go
+// @description	(например, 02.01.2006, дата без дня задаётся с точностью до месяца).
+// @description	Каждая строка проверяется как тело создания подписки. Файл читается потоково,
+// @description	подписки из валидных строк создаются пачками в одной транзакции, ошибки
+// @description	невалидных строк возвращаются в отчёте с номерами строк.
+// @description	При dry_run=true подписки только проверяются.
+// @router			/subs/import [post]
+// @id				import-subs
+// @tags			subs-batch
+// @security		BearerAuth
+// @accept			multipart/form-data
+// @param			file		formData	file		true	"CSV-файл с подписками"
+// @param			dry_run		query		bool		false	"Только проверить строки, не создавая подписки"
+// @param			delimiter	query		string		false	"Разделитель полей (по умолчанию запятая, tab для табуляции)"
+// @param			date_format	query		string		false	"Формат дат в нотации Go (по умолчанию YYYY-MM-DD или MM-YYYY)"
+// @param			column		query		[]string	false	"Название колонки поля в виде поле:колонка"	collectionFormat(multi)
+// @success		200			{object}	entity.SubscriptionImportReport	"Отчёт проверки или импорта без созданных подписок"
+// @success		201			{object}	entity.SubscriptionImportReport	"Отчёт импорта"
+// @failure		400			"Невалидные параметры или файл"
+// @failure		401			"Не авторизован"
+// @failure		504			"Превышено время выполнения запроса к БД"
+func (c *SubsController) Import(ctx *fiber.Ctx) error {
+	queryData := &inSubsImport{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("%w: parse query: %s", errors.ErrValidateData, err.Error())
+	}
+	layout, err := usecase.NewSubsCSVLayout(queryData.Delimiter, queryData.DateFormat,
+		queryData.Columns)
+	if err != nil {
+		return err
+	}
+	// open file
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return fmt.Errorf("%w: file: %s", errors.ErrValidateData, err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	// import subs
+	report, err := c.subsUC.ImportCSV(ctx.UserContext(), file, layout, queryData.DryRun)
+	if err != nil {
+		return err
+	}
+	status := fiber.StatusOK
+	if !report.DryRun && report.Imported > 0 {
+		status = fiber.StatusCreated
+	}
+	return ctx.Status(status).JSON(report)
+}
+
+// @summary		Восстановить запись подписки
+// @description	Восстановление удалённой записи подписки по её ID.
+// @router			/subs/{id}/restore [post]
+// @id				restore-sub
+// @tags			subs-crudl
+// @security		BearerAuth
+// @param			id	path		string	true	"UUID подписки"
+// @success		200	{object}	entity.Subscription
+// @failure		400	"Невалидный параметр запроса"
+// @failure		404	"Удалённая подписка не найдена"
+// @failure		401	"Не авторизован"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *SubsController) Restore(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// restore subs
+	subs, err := c.subsUC.Restore(ctx.UserContext(), pathData.ID)
+	if err != nil {
+		return err
+	}
+	setSubsETag(ctx, subs)
+	return ctx.Status(fiber.StatusOK).JSON(subs)
+}
+
+// @summary		Отменить подписку
+// @description	Отмена подписки сразу (последний месяц подписки - текущий) или с указанного месяца (MM-YYYY) или дня (YYYY-MM-DD) с необязательной причиной.
+// @description	Тело запроса необязательно.
+// @router			/subs/{id}/cancel [post]
+// @id				cancel-sub
+// @tags			subs-actions
+// @security		BearerAuth
+// @param			id		path		string			true	"UUID подписки"
+// @param			Cancel	body		inSubsCancel	false	"Информация об отмене"
+// @success		200		{object}	entity.Subscription
+// @failure		400		"Невалидный параметр или тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		404		"Подписка не найдена"
+// @failure		409		"Подписка уже закончилась"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) Cancel(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	bodyData := &inSubsCancel{}
+	// parse optional body
+	if len(ctx.Body()) != 0 {
+		if err := ctx.BodyParser(bodyData); err != nil {
+			return fmt.Errorf("parse body: %w", err)
+		}
+	}
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := bodyData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	cancel := entity.SubscriptionCancel{
+		ID:               pathData.ID,
+		EndDate:          bodyData.EndDateParsed,
+		EndDatePrecision: bodyData.EndDatePrecision,
+		Reason:           bodyData.Reason,
+	}
+	// cancel subs
+	subs, err := c.subsUC.Cancel(ctx.UserContext(), &cancel)
+	if err != nil {
+		return err
+	}
+	setSubsETag(ctx, subs)
+	return ctx.Status(fiber.StatusOK).JSON(subs)
+}
+
+// @summary		Приостановить подписку
+// @description	Приостановка подписки на указанные месяцы (по умолчанию с текущего месяца до возобновления).
+// @description	Приостановленные месяцы не учитываются в суммах. Для даты с днём приостанавливается её месяц. Тело запроса необязательно.
+// @router			/subs/{id}/pause [post]
+// @id				pause-sub
+// @tags			subs-actions
+// @security		BearerAuth
+// @param			id		path		string		true	"UUID подписки"
+// @param			Pause	body		inSubsPause	false	"Приостановленные месяцы"
+// @success		201		{object}	entity.SubscriptionPause
+// @failure		400		"Невалидный параметр или тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		404		"Подписка не найдена"
+// @failure		409		"Подписка уже приостановлена в эти месяцы"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) Pause(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	bodyData := &inSubsPause{}
+	// parse optional body
+	if len(ctx.Body()) != 0 {
+		if err := ctx.BodyParser(bodyData); err != nil {
+			return fmt.Errorf("parse body: %w", err)
+		}
+	}
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := bodyData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	pause := entity.SubscriptionPause{
+		SubsID:    pathData.ID,
+		StartDate: bodyData.StartDateParsed,
+		EndDate:   bodyData.EndDateParsed,
+	}
+	// pause subs
+	if err := c.subsUC.Pause(ctx.UserContext(), &pause); err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(pause)
+}
+
+// @summary		Возобновить подписку
+// @description	Возобновление приостановленной подписки с текущего месяца.
+// @description	Текущая приостановка заканчивается в предыдущем месяце, запланированные приостановки отменяются.
+// @router			/subs/{id}/resume [post]
+// @id				resume-sub
+// @tags			subs-actions
+// @security		BearerAuth
+// @param			id	path	string	true	"UUID подписки"
+// @success		204	"Успешное возобновление"
+// @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		404	"Подписка не найдена"
+// @failure		409	"Подписка не приостановлена"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *SubsController) Resume(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// resume subs
+	if err := c.subsUC.Resume(ctx.UserContext(), pathData.ID); err != nil {
 		return err
 	}
 	return ctx.Status(fiber.StatusNoContent).Send(nil)
 }
 
-// @summary		Получить все записи подписок
-// @description	Получение всех записей подписок.
+// @summary		Получить приостановки подписки
+// @description	Получение всех приостановок подписки по её ID.
+// @router			/subs/{id}/pauses [get]
+// @id				get-sub-pauses
+// @tags			subs-actions
+// @security		BearerAuth
+// @param			id	path	string	true	"UUID подписки"
+// @success		200	{array}	entity.SubscriptionPause
+// @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		404	"Подписка не найдена"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *SubsController) GetPauses(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// get subs pauses
+	pauseList, err := c.subsUC.GetPauses(ctx.UserContext(), pathData.ID)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(pauseList)
+}
+
+// @summary		Получить записи подписок
+// @description	Получение записей подписок с фильтрацией, сортировкой и пагинацией (offset или cursor).
 // @router			/subs [get]
 // @id				get-all-subs
 // @tags			subs-crudl
-// @success		200	{object}	entity.SubscriptionList
+// @security		BearerAuth
+// @param			user_id			query		string	false	"UUID пользователя"
+// @param			service_name	query		string	false	"Название сервиса"
+// @param			active_at		query		string	false	"Дата (YYYY-MM-DD) или месяц (MM-YYYY), в любой день которого подписка активна"	example:"07-2025"
+// @param			price_min		query		int		false	"Минимальная цена в минимальных единицах валюты, например копейках (включительно)"
+// @param			price_max		query		int		false	"Максимальная цена в минимальных единицах валюты, например копейках (включительно)"
+// @param			include_deleted	query		bool	false	"Включая удалённые подписки (только для администратора)"
+// @param			sort			query		string	false	"Поле сортировки"	Enums(id, service_name, price, start_date)	default(start_date)
+// @param			order			query		string	false	"Порядок сортировки"	Enums(asc, desc)	default(asc)
+// @param			limit			query		int		false	"Размер страницы"	minimum(1)	maximum(1000)	default(50)
+// @param			offset			query		int		false	"Смещение (нельзя использовать вместе с cursor)"
+// @param			cursor			query		string	false	"Курсор следующей страницы (next_cursor)"
+// @success		200				{object}	entity.SubscriptionPage
+// @failure		400				"Невалидный(ые) параметр(ы) запроса"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет доступа к подпискам другого пользователя"
+// @failure		504				"Превышено время выполнения запроса к БД"
 func (c *SubsController) GetAll(ctx *fiber.Ctx) error {
-	// get all subs
-	subsList, err := c.subsUC.GetAll()
+	queryData := newInSubsListFilter()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := queryData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	subsListFilter := queryData.ListFilter()
+	subsListFilter.Limit = queryData.Limit
+	subsListFilter.Offset = queryData.Offset
+	subsListFilter.Cursor = queryData.Cursor
+	// get subs page
+	subsPage, err := c.subsUC.GetAll(ctx.UserContext(), &subsListFilter)
 	if err != nil {
 		return err
 	}
-	return ctx.Status(fiber.StatusOK).JSON(subsList)
+	return ctx.Status(fiber.StatusOK).JSON(subsPage)
+}
+
+// @summary		Получить предстоящие списания
+// @description	Получение не более limit списаний по подпискам, которые должны произойти
+// @description	в ближайшие days дней (включая текущий день), отсортированных по дате.
+// @description	Списания в месяцах приостановки и после окончания подписки не учитываются.
+// @router			/subs/upcoming [get]
+// @id				get-upcoming-charges
+// @tags			subs-crudl
+// @security		BearerAuth
+// @param			user_id	query		string	false	"UUID пользователя (для администратора без него — все пользователи)"
+// @param			days	query		int		false	"Количество дней"	minimum(1)	maximum(366)	default(30)
+// @param			limit	query		int		false	"Максимальное количество списаний"	minimum(1)	maximum(1000)	default(100)
+// @success		200		{array}		entity.SubscriptionCharge
+// @failure		400		"Невалидный(ые) параметр(ы) запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет доступа к подпискам другого пользователя"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *SubsController) GetUpcoming(ctx *fiber.Ctx) error {
+	queryData := newInSubsUpcoming()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	charges, err := c.subsUC.GetUpcoming(ctx.UserContext(),
+		queryData.UserID, queryData.Days, queryData.Limit)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(charges)
+}
+
+// @summary		Выгрузить записи подписок
+// @description	Потоковая выгрузка всех записей подписок с фильтрацией и сортировкой как в списке подписок
+// @description	(без пагинации) в формате CSV, XLSX или JSON Lines.
+// @description	Кроме полей подписки выгружаются вычисляемые колонки: months_active — число месяцев
+// @description	от начала подписки до её окончания или текущего месяца без месяцев приостановки,
+// @description	total_paid — сумма списаний с начала подписки до текущей даты в валюте подписки.
+// @description	Ошибка во время выгрузки прерывает файл (код ответа уже отправлен).
+// @router			/subs/export [get]
+// @id				export-subs
+// @tags			subs-crudl
+// @security		BearerAuth
+// @produce		text/csv
+// @produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @produce		application/jsonl
+// @param			format			query		string	false	"Формат файла"	Enums(csv, xlsx, jsonl)	default(csv)
+// @param			user_id			query		string	false	"UUID пользователя"
+// @param			service_name	query		string	false	"Название сервиса"
+// @param			active_at		query		string	false	"Дата (YYYY-MM-DD) или месяц (MM-YYYY), в любой день которого подписка активна"	example:"07-2025"
+// @param			price_min		query		int		false	"Минимальная цена в минимальных единицах валюты, например копейках (включительно)"
+// @param			price_max		query		int		false	"Максимальная цена в минимальных единицах валюты, например копейках (включительно)"
+// @param			include_deleted	query		bool	false	"Включая удалённые подписки (только для администратора)"
+// @param			sort			query		string	false	"Поле сортировки"	Enums(id, service_name, price, start_date)	default(start_date)
+// @param			order			query		string	false	"Порядок сортировки"	Enums(asc, desc)	default(asc)
+// @success		200				{file}		file	"Файл с подписками"
+// @header			200				{string}	Content-Disposition	"attachment; filename=subs.<format>"
+// @failure		400				"Невалидный(ые) параметр(ы) запроса"
+// @failure		401				"Не авторизован"
+// @failure		403				"Нет доступа к подпискам другого пользователя"
+func (c *SubsController) Export(ctx *fiber.Ctx) error {
+	queryData := newInSubsExportFilter()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := queryData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	subsListFilter := queryData.ListFilter()
+	// check access to subs before response is started
+	export, err := c.subsUC.Export(ctx.UserContext(), &subsListFilter)
+	if err != nil {
+		return err
+	}
+
+	format := queryData.Format
+	ctx.Attachment("subs." + format)
+	// content type is set after attachment, which sets it by file extension
+	ctx.Set(fiber.HeaderContentType, _exportMIMETypes[format])
+	// stream is written after handler returns and its user context is canceled,
+	// so stream uses user context values with its own timeout
+	streamCtx := context.WithoutCancel(ctx.UserContext())
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithTimeout(streamCtx, c.exportTimeout)
+		defer cancel()
+		if err := writeExport(streamCtx, w, format, export); err != nil {
+			logrus.Errorf("Export subs: %v", err)
+		}
+	})
+	return nil
 }
 
 // @summary		Получить суммарную стоимость подписок
 // @description	Получение суммарной стоимости всех подписок за выбранный период с фильтрацией по id пользователя и названию подписки.
+// @description	Стоимость каждой подписки умножается на количество дат её списаний (по периоду оплаты) в пределах периода.
+// @description	Границы периода задаются днями (YYYY-MM-DD) или месяцами (MM-YYYY), месяц окончания включается целиком.
 // @router			/subs-sum [get]
 // @id				get-subs-sum
 // @tags			subs-advanced
+// @security		BearerAuth
 // @param			user_id			query		string	false	"UUID пользователя"	example"60601fee-2bf1-4721-ae6f-7636e79a0cba"
 // @param			service_name	query		string	false	"Название сервиса"	example:"Yandex Plus"
-// @param			start_date		query		string	false	"Дата начала"		example:"07-2025"
-// @param			end_date		query		string	false	"Дата окончания"	example:"08-2025"
+// @param			start_date		query		string	false	"Дата начала (YYYY-MM-DD или MM-YYYY)"	example:"07-2025"
+// @param			end_date		query		string	false	"Дата окончания (YYYY-MM-DD или MM-YYYY — весь месяц)"	example:"08-2025"
+// @param			currency		query		string	false	"Валюта результата (ISO 4217), по умолчанию RUB"	example:"USD"
 // @success		200				{object}	entity.SubscriptionSum
 // @failure		400				"Невалидный(ые) параметр(ы) запроса"
+// @failure		422				"Не найден курс валюты для месяца списания"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет доступа к подпискам другого пользователя"
+// @failure		504				"Превышено время выполнения запроса к БД"
 func (c *SubsController) GetSum(ctx *fiber.Ctx) error {
 	queryData := &inSubSumFilter{}
 	// parse path-params
@@ -209,17 +930,121 @@ func (c *SubsController) GetSum(ctx *fiber.Ctx) error {
 		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
 	}
 
+	subSumFilter := entity.SubscriptionSumFilter{
+		ServiceName:      queryData.ServiceName,
+		UserID:           queryData.UserID,
+		StartDate:        queryData.StartDateParsed,
+		EndDate:          queryData.EndDateParsed,
+		EndDatePrecision: queryData.EndDatePrecision,
+		Currency:         queryData.Currency,
+	}
+	// get subs
+	subsSum, err := c.subsUC.GetSum(ctx.UserContext(), &subSumFilter)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(subsSum)
+}
+
+// @summary		Получить стоимость подписок по группам
+// @description	Получение суммарной стоимости подписок за выбранный период, сгруппированной по названию сервиса или id пользователя.
+// @description	Группы отсортированы по убыванию суммы, стоимость считается так же, как в /subs-sum.
+// @router			/subs-sum/grouped [get]
+// @id				get-subs-grouped-sum
+// @tags			subs-advanced
+// @security		BearerAuth
+// @param			by				query		string	true	"Поле группировки"	Enums(service_name, user_id)
+// @param			limit			query		int		false	"Количество групп (top-N)"	minimum(0)	maximum(1000)
+// @param			user_id			query		string	false	"UUID пользователя"
+// @param			service_name	query		string	false	"Название сервиса"
+// @param			start_date		query		string	false	"Дата начала (YYYY-MM-DD или MM-YYYY)"	example:"07-2025"
+// @param			end_date		query		string	false	"Дата окончания (YYYY-MM-DD или MM-YYYY — весь месяц)"	example:"08-2025"
+// @param			currency		query		string	false	"Валюта результата (ISO 4217), по умолчанию RUB"	example:"USD"
+// @success		200				{object}	entity.SubscriptionSumGroupList
+// @failure		400				"Невалидный(ые) параметр(ы) запроса"
+// @failure		422				"Не найден курс валюты для месяца списания"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет доступа к подпискам другого пользователя"
+// @failure		504				"Превышено время выполнения запроса к БД"
+func (c *SubsController) GetGroupedSum(ctx *fiber.Ctx) error {
+	queryData := &inSubsGroupedFilter{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := queryData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	groupFilter := entity.SubscriptionSumGroupFilter{
+		SubscriptionSumFilter: entity.SubscriptionSumFilter{
+			ServiceName:      queryData.ServiceName,
+			UserID:           queryData.UserID,
+			StartDate:        queryData.StartDateParsed,
+			EndDate:          queryData.EndDateParsed,
+			EndDatePrecision: queryData.EndDatePrecision,
+			Currency:         queryData.Currency,
+		},
+		GroupBy: queryData.By,
+		Limit:   queryData.Limit,
+	}
+	// get subs grouped sum
+	groupList, err := c.subsUC.GetGroupedSum(ctx.UserContext(), &groupFilter)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(groupList)
+}
+
+// @summary		Получить помесячную стоимость подписок
+// @description	Получение стоимости подписок за каждый месяц периода с количеством активных подписок и разбивкой по сервисам.
+// @description	Период задаётся месяцами from и to (включительно) и не может быть длиннее 120 месяцев.
+// @router			/subs-sum/monthly [get]
+// @id				get-subs-monthly-sum
+// @tags			subs-advanced
+// @security		BearerAuth
+// @param			from			query		string	true	"Первый месяц периода"	example:"01-2025"
+// @param			to				query		string	true	"Последний месяц периода"	example:"12-2025"
+// @param			user_id			query		string	false	"UUID пользователя"
+// @param			service_name	query		string	false	"Название сервиса"
+// @param			currency		query		string	false	"Валюта результата (ISO 4217), по умолчанию RUB"	example:"USD"
+// @success		200				{object}	entity.SubscriptionMonthlySumList
+// @failure		400				"Невалидный(ые) параметр(ы) запроса"
+// @failure		422				"Не найден курс валюты для месяца списания"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет доступа к подпискам другого пользователя"
+// @failure		504				"Превышено время выполнения запроса к БД"
+func (c *SubsController) GetMonthlySum(ctx *fiber.Ctx) error {
+	queryData := &inSubsMonthlyFilter{}
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := queryData.ParseDates(); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
 	subSumFilter := entity.SubscriptionSumFilter{
 		ServiceName: queryData.ServiceName,
 		UserID:      queryData.UserID,
-		StartDate:   queryData.StartDateParsed,
-		EndDate:     queryData.EndDateParsed,
+		StartDate:   queryData.FromParsed,
+		EndDate:     queryData.ToParsed,
+		Currency:    queryData.Currency,
 	}
-	// get subs
-	subsSum, err := c.subsUC.GetSum(&subSumFilter)
+	// get subs monthly sum
+	monthlySumList, err := c.subsUC.GetMonthlySum(ctx.UserContext(), &subSumFilter)
 	if err != nil {
 		return err
 	}
-	totalData := entity.SubscriptionSum{Sum: subsSum}
-	return ctx.Status(fiber.StatusOK).JSON(totalData)
+	return ctx.Status(fiber.StatusOK).JSON(monthlySumList)
 }
@@ -5,38 +5,93 @@ import (
 	"fmt"
 	"time"
 
+	"SubscriptionAggregator/internal/app/entity"
 	"SubscriptionAggregator/internal/pkg/utils"
 )
 
+const (
+	_maxMonthlyPeriod = 120  // max number of months in monthly sum period
+	_maxBatchSize     = 1000 // max number of items in one batch
+	// number of days of upcoming charges by default
+	_defaultUpcomingDays = 30
+	// max number of upcoming charges by default
+	_defaultUpcomingLimit = 100
+)
+
 // inPathUUID is input data with UUID in path.
 type inPathUUID struct {
 	// uuid
 	ID string `path:"id" validate:"required,uuid4"`
 }
 
+// inPathUserID is input data with user UUID in path.
+type inPathUserID struct {
+	// user uuid
+	UserID string `params:"user_id" validate:"required,uuid4"`
+}
+
+// @description inCalendarToken is query-param with calendar token.
+type inCalendarToken struct {
+	// calendar token of the user
+	Token string `query:"token" validate:"required"`
+}
+
+// @description inIncludeDeleted is query-param to include deleted subs.
+type inIncludeDeleted struct {
+	// include deleted subs
+	IncludeDeleted bool `query:"include_deleted,omitempty"`
+}
+
 // @description inSubsCreate is body input data with subs data.
 type inSubsCreate struct {
 	// service name
 	ServiceName string `json:"service_name" validate:"required,max=100" maxLength:"100" example:"Yandex Plus"`
-	// price
-	Price int `json:"price" validate:"required" example:"400"`
+	// price for one billing period (decimal string in major units)
+	Price string `json:"price" validate:"required,numeric,positive" example:"199.99"`
+	// price currency (ISO 4217, RUB by default)
+	Currency string `json:"currency,omitempty" validate:"omitempty,currency" example:"RUB"`
+	// billing period (monthly by default)
+	BillingPeriod string `json:"billing_period,omitempty" validate:"omitempty,oneof=weekly monthly quarterly yearly" enums:"weekly,monthly,quarterly,yearly" example:"monthly"`
+	// number of billing periods between charges (1 by default)
+	BillingInterval int `json:"billing_interval,omitempty" validate:"omitempty,min=1,max=100" example:"1"`
 	// user uuid
 	UserID string `json:"user_id" validate:"required,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	// start date
-	StartDate string `json:"start_date" validate:"required" example:"07-2025"`
-	// end date
+	// start date (YYYY-MM-DD or MM-YYYY)
+	StartDate string `json:"start_date" validate:"required" example:"2025-07-17"`
+	// end date (YYYY-MM-DD or MM-YYYY, the whole month is included)
 	EndDate *string `json:"end_date,omitempty" validate:"omitempty" example:"08-2025"`
+	// day of month of charges (start date day by default)
+	BillingDay int `json:"billing_day,omitempty" validate:"omitempty,min=1,max=31" example:"17"`
 
 	// string start date parsed into time.Time
 	StartDateParsed *time.Time `json:"-"`
+	// precision of the string start date
+	StartDatePrecision entity.DatePrecision `json:"-"`
 	// string end date parsed into time.Time
 	EndDateParsed *time.Time `json:"-"`
+	// precision of the string end date
+	EndDatePrecision *entity.DatePrecision `json:"-"`
+	// string price parsed into money of the currency
+	PriceParsed entity.Money `json:"-"`
 }
 
-// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields.
+// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields
+// with their precisions. It returns parsing error if it occurs.
+func (c *inSubsCreate) ParseDates() error {
+	dates, err := parseDates(&c.StartDate, c.EndDate)
+	c.StartDateParsed, c.EndDateParsed = dates.start, dates.end
+	c.StartDatePrecision, c.EndDatePrecision = dates.startPrecision, dates.endPrecisionPtr()
+	return err // err OR nil
+}
+
+// ParsePrice parses given string price into PriceParsed field (RUB is used by default).
 // It returns parsing error if it occurs.
-func (c *inSubsCreate) ParseDates() (err error) {
-	c.StartDateParsed, c.EndDateParsed, err = parseDates(&c.StartDate, c.EndDate)
+func (c *inSubsCreate) ParsePrice() (err error) {
+	currency := c.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+	c.PriceParsed, err = entity.ParseMoney(c.Price, currency)
 	return err // err OR nil
 }
 
@@ -44,13 +99,165 @@ func (c *inSubsCreate) ParseDates() (err error) {
 type inSubsUpdate struct {
 	// service name
 	ServiceName *string `json:"service_name,omitempty" validate:"omitempty,max=100" maxLength:"100" example:"Yandex Plus"`
-	// price
-	Price *int `json:"price,omitempty" validate:"omitempty" example:"400"`
+	// price for one billing period (decimal string in major units)
+	Price *string `json:"price,omitempty" validate:"omitempty,numeric,positive" example:"199.99"`
+	// price currency (ISO 4217)
+	Currency *string `json:"currency,omitempty" validate:"omitempty,currency" example:"RUB"`
+	// billing period
+	BillingPeriod *string `json:"billing_period,omitempty" validate:"omitempty,oneof=weekly monthly quarterly yearly" enums:"weekly,monthly,quarterly,yearly" example:"monthly"`
+	// number of billing periods between charges
+	BillingInterval *int `json:"billing_interval,omitempty" validate:"omitempty,min=1,max=100" example:"1"`
 	// user uuid
 	UserID *string `json:"user_id,omitempty" validate:"omitempty,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	// start date
+	// start date (YYYY-MM-DD or MM-YYYY)
+	StartDate *string `json:"start_date,omitempty" validate:"omitempty" example:"2025-07-17"`
+	// end date (YYYY-MM-DD or MM-YYYY, the whole month is included)
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty" example:"08-2025"`
+	// day of month of charges (new start date day if start date is changed)
+	BillingDay *int `json:"billing_day,omitempty" validate:"omitempty,min=1,max=31" example:"17"`
+
+	// string start date parsed into time.Time
+	StartDateParsed *time.Time `json:"-"`
+	// precision of the string start date
+	StartDatePrecision *entity.DatePrecision `json:"-"`
+	// string end date parsed into time.Time
+	EndDateParsed *time.Time `json:"-"`
+	// precision of the string end date
+	EndDatePrecision *entity.DatePrecision `json:"-"`
+}
+
+// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields
+// with their precisions. It returns parsing error if it occurs.
+func (c *inSubsUpdate) ParseDates() error {
+	dates, err := parseDates(c.StartDate, c.EndDate)
+	c.StartDateParsed, c.EndDateParsed = dates.start, dates.end
+	c.StartDatePrecision, c.EndDatePrecision = dates.startPrecisionPtr(), dates.endPrecisionPtr()
+	return err // err OR nil
+}
+
+// @description inBatchQuery is query input data with batch mode.
+type inBatchQuery struct {
+	// handle all items in one transaction (true by default)
+	Atomic *bool `query:"atomic"`
+}
+
+// IsAtomic returns true if all batch items must be handled in one transaction.
+func (q *inBatchQuery) IsAtomic() bool {
+	return q.Atomic == nil || *q.Atomic
+}
+
+// @description inSubsImport is query input data with subs import params.
+type inSubsImport struct {
+	// only validate rows without subs creation
+	DryRun bool `query:"dry_run"`
+	// fields delimiter ("tab" for tab)
+	Delimiter string `query:"delimiter"`
+	// layout of dates
+	DateFormat string `query:"date_format"`
+	// header columns of fields as "field:column" pairs
+	Columns []string `query:"column"`
+}
+
+// @description inSubsBatchUpdate is body input data with subs ID and its optional data.
+type inSubsBatchUpdate struct {
+	// subs uuid
+	ID string `json:"id" validate:"required,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	inSubsUpdate
+	// expected current subs version (required if If-Match header is required,
+	// otherwise it is not checked if it is absent)
+	Version *int64 `json:"version,omitempty" validate:"omitempty,min=1" example:"1"`
+}
+
+// @description inSubsDocument is subs document with all updatable fields
+// @description which is patched by JSON Merge Patch or JSON Patch.
+type inSubsDocument struct {
+	// service name
+	ServiceName string `json:"service_name" validate:"required,max=100"`
+	// price for one billing period (decimal string in major units)
+	Price string `json:"price" validate:"required,numeric,positive"`
+	// price currency (ISO 4217)
+	Currency string `json:"currency" validate:"required,currency"`
+	// billing period
+	BillingPeriod string `json:"billing_period" validate:"required,oneof=weekly monthly quarterly yearly"`
+	// number of billing periods between charges
+	BillingInterval int `json:"billing_interval" validate:"required,min=1,max=100"`
+	// user uuid
+	UserID string `json:"user_id" validate:"required,uuid4"`
+	// start date (YYYY-MM-DD or MM-YYYY)
+	StartDate string `json:"start_date" validate:"required"`
+	// end date (YYYY-MM-DD or MM-YYYY, the whole month is included)
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty"`
+	// day of month of charges
+	BillingDay int `json:"billing_day" validate:"required,min=1,max=31"`
+
+	// string start date parsed into time.Time
+	StartDateParsed *time.Time `json:"-"`
+	// precision of the string start date
+	StartDatePrecision entity.DatePrecision `json:"-"`
+	// string end date parsed into time.Time
+	EndDateParsed *time.Time `json:"-"`
+	// precision of the string end date
+	EndDatePrecision *entity.DatePrecision `json:"-"`
+}
+
+// newInSubsDocument returns subs document with the given subs fields.
+func newInSubsDocument(subs *entity.Subscription) *inSubsDocument {
+	doc := &inSubsDocument{
+		ServiceName:     subs.ServiceName,
+		Price:           subs.Price.String(),
+		Currency:        subs.Price.Currency,
+		BillingPeriod:   string(subs.BillingPeriod),
+		BillingInterval: subs.BillingInterval,
+		UserID:          subs.UserID,
+		BillingDay:      subs.BillingDay,
+	}
+	if subs.StartDate != nil {
+		doc.StartDate = utils.FormatDate(*subs.StartDate,
+			subs.StartDatePrecision == entity.PrecisionDay)
+	}
+	if subs.EndDate != nil {
+		endDate := utils.FormatDate(*subs.EndDate,
+			subs.EndDatePrecision != nil && *subs.EndDatePrecision == entity.PrecisionDay)
+		doc.EndDate = &endDate
+	}
+	return doc
+}
+
+// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields
+// with their precisions. It returns parsing error if it occurs.
+func (c *inSubsDocument) ParseDates() error {
+	dates, err := parseDates(&c.StartDate, c.EndDate)
+	c.StartDateParsed, c.EndDateParsed = dates.start, dates.end
+	c.StartDatePrecision, c.EndDatePrecision = dates.startPrecision, dates.endPrecisionPtr()
+	return err // err OR nil
+}
+
+// @description inSubsCancel is body input data with subs cancellation.
+type inSubsCancel struct {
+	// the last month (MM-YYYY) or day (YYYY-MM-DD) of the subs (the current month by default)
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty" example:"08-2025"`
+	// reason of the cancellation
+	Reason string `json:"reason,omitempty" validate:"max=500" maxLength:"500" example:"Too expensive"`
+
+	// string end date parsed into time.Time
+	EndDateParsed *time.Time `json:"-"`
+	// precision of the string end date
+	EndDatePrecision entity.DatePrecision `json:"-"`
+}
+
+// ParseDates parses given string end date into EndDateParsed field with its precision.
+// It returns parsing error if it occurs.
+func (c *inSubsCancel) ParseDates() error {
+	dates, err := parseDates(nil, c.EndDate)
+	c.EndDateParsed, c.EndDatePrecision = dates.end, dates.endPrecision
+	return err // err OR nil
+}
+
+// @description inSubsPause is body input data with paused months.
+type inSubsPause struct {
+	// the first paused month (the current month by default)
 	StartDate *string `json:"start_date,omitempty" validate:"omitempty" example:"07-2025"`
-	// end date
+	// the last paused month (until resume if it is absent)
 	EndDate *string `json:"end_date,omitempty" validate:"omitempty" example:"08-2025"`
 
 	// string start date parsed into time.Time
@@ -60,61 +267,380 @@ type inSubsUpdate struct {
 }
 
 // ParseDates parses given string dates into StartDateParsed and EndDateParsed fields.
+// Pause consists of months, so day of the date is ignored.
 // It returns parsing error if it occurs.
-func (c *inSubsUpdate) ParseDates() (err error) {
-	c.StartDateParsed, c.EndDateParsed, err = parseDates(c.StartDate, c.EndDate)
+func (c *inSubsPause) ParseDates() error {
+	dates, err := parseDates(c.StartDate, c.EndDate)
+	c.StartDateParsed, c.EndDateParsed = monthOf(dates.start), monthOf(dates.end)
 	return err // err OR nil
 }
 
+// @description inSubsFilter is query-params with filter and sort for subs list.
+type inSubsFilter struct {
+	// service name
+	ServiceName string `query:"service_name,omitempty" validate:"omitempty,max=100"`
+	// user uuid
+	UserID string `query:"user_id,omitempty" validate:"omitempty,uuid4"`
+	// date at which subs must be active
+	ActiveAt *string `query:"active_at,omitempty" validate:"omitempty"`
+	// min price in minor units (inclusive)
+	PriceMin *int64 `query:"price_min,omitempty" validate:"omitempty,min=0"`
+	// max price in minor units (inclusive)
+	PriceMax *int64 `query:"price_max,omitempty" validate:"omitempty,min=0"`
+	// include deleted subs
+	IncludeDeleted bool `query:"include_deleted,omitempty"`
+	// field to sort by
+	Sort string `query:"sort" validate:"oneof=id service_name price start_date"`
+	// sort order
+	Order string `query:"order" validate:"oneof=asc desc"`
+
+	// string active at date parsed into time.Time
+	ActiveAtParsed *time.Time `json:"-"`
+	// precision of the string active at date
+	ActiveAtPrecision entity.DatePrecision `json:"-"`
+}
+
+// ParseDates parses given string active at date into ActiveAtParsed field.
+// Also it checks that price range is correct.
+// It returns parsing error if it occurs.
+func (c *inSubsFilter) ParseDates() error {
+	if c.PriceMin != nil && c.PriceMax != nil && *c.PriceMin > *c.PriceMax {
+		return errors.New("price min is greater than price max")
+	}
+	if c.ActiveAt == nil {
+		return nil
+	}
+	activeAt, withDay, err := utils.ParseDate(*c.ActiveAt)
+	if err != nil {
+		return fmt.Errorf("parse active at date: %w", err)
+	}
+	c.ActiveAtParsed, c.ActiveAtPrecision = &activeAt, datePrecision(withDay)
+	return nil
+}
+
+// ListFilter returns subs list filter with filter and sort params.
+func (c *inSubsFilter) ListFilter() entity.SubscriptionListFilter {
+	return entity.SubscriptionListFilter{
+		ServiceName:       c.ServiceName,
+		UserID:            c.UserID,
+		ActiveAt:          c.ActiveAtParsed,
+		ActiveAtPrecision: c.ActiveAtPrecision,
+		PriceMin:          c.PriceMin,
+		PriceMax:          c.PriceMax,
+		IncludeDeleted:    c.IncludeDeleted,
+		Sort:              c.Sort,
+		Order:             c.Order,
+	}
+}
+
+// @description inSubsListFilter is query-params with filter, sort and pagination for subs list.
+type inSubsListFilter struct {
+	inSubsFilter
+	// max number of items on the page
+	Limit int `query:"limit" validate:"min=1,max=1000"`
+	// number of items to skip
+	Offset int `query:"offset,omitempty" validate:"min=0,excluded_with=Cursor"`
+	// cursor from previous page
+	Cursor string `query:"cursor,omitempty" validate:"omitempty,base64rawurl"`
+}
+
+// newInSubsListFilter returns inSubsListFilter with default sort and pagination values.
+func newInSubsListFilter() *inSubsListFilter {
+	return &inSubsListFilter{
+		inSubsFilter: inSubsFilter{Sort: "start_date", Order: "asc"},
+		Limit:        50, // nolint:mnd // default page size
+	}
+}
+
+// @description inSubsExportFilter is query-params with export format, filter and sort for subs.
+type inSubsExportFilter struct {
+	inSubsFilter
+	// export file format
+	Format string `query:"format" validate:"oneof=csv xlsx jsonl"`
+}
+
+// newInSubsExportFilter returns inSubsExportFilter with default format and sort values.
+func newInSubsExportFilter() *inSubsExportFilter {
+	return &inSubsExportFilter{
+		inSubsFilter: inSubsFilter{Sort: "start_date", Order: "asc"},
+		Format:       "csv",
+	}
+}
+
+// @description inSubsUpcoming is query-params of upcoming charges.
+type inSubsUpcoming struct {
+	// user uuid
+	UserID string `query:"user_id,omitempty" validate:"omitempty,uuid4"`
+	// number of days from the current one
+	Days int `query:"days" validate:"min=1,max=366"`
+	// max number of charges
+	Limit int `query:"limit" validate:"min=1,max=1000"`
+}
+
+// newInSubsUpcoming returns inSubsUpcoming with default number of days and charges.
+func newInSubsUpcoming() *inSubsUpcoming {
+	return &inSubsUpcoming{Days: _defaultUpcomingDays, Limit: _defaultUpcomingLimit}
+}
+
 // @description inSubSumFilter is query-params with user ans service.
 type inSubSumFilter struct {
 	// service name
 	ServiceName string `query:"service_name,omitempty" validate:"omitempty,max=100"`
 	// user uuid
 	UserID string `query:"user_id,omitempty" validate:"omitempty,uuid4"`
-	// start date
+	// start date (YYYY-MM-DD or MM-YYYY)
 	StartDate *string `query:"start_date,omitempty" validate:"omitempty"`
-	// end date
+	// end date (YYYY-MM-DD or MM-YYYY, the whole month is included)
 	EndDate *string `query:"end_date,omitempty" validate:"omitempty"`
+	// currency to convert prices to
+	Currency string `query:"currency,omitempty" validate:"omitempty,currency"`
 
 	// string start date parsed into time.Time
 	StartDateParsed *time.Time `json:"-"`
 	// string end date parsed into time.Time
 	EndDateParsed *time.Time `json:"-"`
+	// precision of the string end date
+	EndDatePrecision entity.DatePrecision `json:"-"`
 }
 
-// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields.
-// It returns parsing error if it occurs.
-func (c *inSubSumFilter) ParseDates() (err error) {
-	c.StartDateParsed, c.EndDateParsed, err = parseDates(c.StartDate, c.EndDate)
+// ParseDates parses given string dates into StartDateParsed and EndDateParsed fields
+// with end date precision. It returns parsing error if it occurs.
+func (c *inSubSumFilter) ParseDates() error {
+	dates, err := parseDates(c.StartDate, c.EndDate)
+	c.StartDateParsed, c.EndDateParsed = dates.start, dates.end
+	c.EndDatePrecision = dates.endPrecision
 	return err // err OR nil
 }
 
-// parseDates parses given start and end string dates into time.Time structs.
-// It returns parsing error if it occurs. Also it checks that end date is after startd date
+// @description inSubsGroupedFilter is query-params with inSubSumFilter params and grouping.
+type inSubsGroupedFilter struct {
+	inSubSumFilter
+	// field to group by
+	By string `query:"by" validate:"required,oneof=service_name user_id"`
+	// max number of groups (top-N)
+	Limit int `query:"limit,omitempty" validate:"min=0,max=1000"`
+}
+
+// @description inSubsMonthlyFilter is query-params with period, user and service.
+type inSubsMonthlyFilter struct {
+	// service name
+	ServiceName string `query:"service_name,omitempty" validate:"omitempty,max=100"`
+	// user uuid
+	UserID string `query:"user_id,omitempty" validate:"omitempty,uuid4"`
+	// first month of the period
+	From string `query:"from" validate:"required"`
+	// last month of the period
+	To string `query:"to" validate:"required"`
+	// currency to convert prices to
+	Currency string `query:"currency,omitempty" validate:"omitempty,currency"`
+
+	// string from date parsed into time.Time
+	FromParsed *time.Time `json:"-"`
+	// string to date parsed into time.Time
+	ToParsed *time.Time `json:"-"`
+}
+
+// ParseDates parses given string dates into FromParsed and ToParsed fields
+// (the first days of their months). It returns parsing error if it occurs.
+// Also it checks that period is not too long.
+func (c *inSubsMonthlyFilter) ParseDates() error {
+	dates, err := parseDates(&c.From, &c.To)
+	if err != nil {
+		return err
+	}
+	c.FromParsed, c.ToParsed = monthOf(dates.start), monthOf(dates.end)
+	if utils.MonthsBetween(*c.FromParsed, *c.ToParsed) > _maxMonthlyPeriod {
+		return fmt.Errorf("period must not be longer than %d months", _maxMonthlyPeriod)
+	}
+	return nil
+}
+
+// @description inAPIKeyCreate is body input data with API key data.
+type inAPIKeyCreate struct {
+	// key name
+	Name string `json:"name" validate:"required,max=100" maxLength:"100" example:"billing-cron"`
+	// allowed scopes
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=subs:read subs:write subs:sum" example:"subs:read,subs:sum"`
+	// uuid of the user whose subs are accessed by the key (all users if it is absent)
+	OwnerID *string `json:"owner_id,omitempty" validate:"omitempty,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	// expiration time (without expiration if it is absent)
+	ExpiresAt *time.Time `json:"expires_at,omitempty" validate:"omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+// @description inWebhookCreate is body input data with webhook data.
+type inWebhookCreate struct {
+	// receiver URL
+	URL string `json:"url" validate:"required,http_url,max=2000" maxLength:"2000" example:"https://example.com/hooks/subs"`
+	// secret to sign payload with HMAC-SHA256
+	Secret string `json:"secret" validate:"required,min=16,max=200" minLength:"16" maxLength:"200" example:"0123456789abcdef"`
+	// event types to send
+	EventTypes []string `json:"event_types" validate:"required,min=1,unique,dive,oneof=subs.created subs.updated subs.deleted subs.restored subs.ended subs.paused subs.resumed" example:"subs.created,subs.deleted"`
+	// events are sent only to active webhooks (true by default)
+	Active *bool `json:"active,omitempty" validate:"omitempty" example:"true"`
+}
+
+// @description inWebhookUpdate is body input data with optional webhook data.
+type inWebhookUpdate struct {
+	// receiver URL
+	URL *string `json:"url,omitempty" validate:"omitempty,http_url,max=2000" maxLength:"2000" example:"https://example.com/hooks/subs"`
+	// secret to sign payload with HMAC-SHA256
+	Secret *string `json:"secret,omitempty" validate:"omitempty,min=16,max=200" minLength:"16" maxLength:"200" example:"0123456789abcdef"`
+	// event types to send
+	EventTypes []string `json:"event_types,omitempty" validate:"omitempty,min=1,unique,dive,oneof=subs.created subs.updated subs.deleted subs.restored subs.ended subs.paused subs.resumed" example:"subs.created,subs.deleted"`
+	// events are sent only to active webhooks
+	Active *bool `json:"active,omitempty" validate:"omitempty" example:"false"`
+}
+
+// @description inWebhookDeliveriesFilter is query-params with filter and pagination for deliveries.
+type inWebhookDeliveriesFilter struct {
+	// delivery status
+	Status string `query:"status,omitempty" validate:"omitempty,oneof=pending delivered dead"`
+	// max number of items
+	Limit int `query:"limit" validate:"min=1,max=1000"`
+	// number of items to skip
+	Offset int `query:"offset,omitempty" validate:"min=0"`
+}
+
+// newInWebhookDeliveriesFilter returns inWebhookDeliveriesFilter with default pagination values.
+func newInWebhookDeliveriesFilter() *inWebhookDeliveriesFilter {
+	return &inWebhookDeliveriesFilter{
+		Limit: 50, // nolint:mnd // default page size
+	}
+}
+
+// @description inAuditPagination is query-params with pagination for audit log.
+type inAuditPagination struct {
+	// max number of items
+	Limit int `query:"limit" validate:"min=1,max=1000"`
+	// number of items to skip
+	Offset int `query:"offset,omitempty" validate:"min=0"`
+}
+
+// newInAuditPagination returns inAuditPagination with default pagination values.
+func newInAuditPagination() *inAuditPagination {
+	return &inAuditPagination{
+		Limit: 50, // nolint:mnd // default page size
+	}
+}
+
+// @description inAuditFilter is query-params with filter and pagination for audit log.
+type inAuditFilter struct {
+	// max number of items
+	Limit int `query:"limit" validate:"min=1,max=1000"`
+	// number of items to skip
+	Offset int `query:"offset,omitempty" validate:"min=0"`
+	// changed subs uuid
+	SubsID string `query:"subs_id,omitempty" validate:"omitempty,uuid4"`
+	// uuid of the subs owner
+	UserID string `query:"user_id,omitempty" validate:"omitempty,uuid4"`
+	// uuid of the user made the change
+	ActorID string `query:"actor_id,omitempty" validate:"omitempty,uuid4"`
+	// action
+	Action string `query:"action,omitempty" validate:"omitempty,oneof=create update delete restore pause resume"`
+	// min time of the change (RFC 3339, inclusive)
+	From string `query:"from,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// max time of the change (RFC 3339, exclusive)
+	To string `query:"to,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+
+	// string from time parsed into time.Time
+	FromParsed *time.Time `json:"-"`
+	// string to time parsed into time.Time
+	ToParsed *time.Time `json:"-"`
+}
+
+// newInAuditFilter returns inAuditFilter with default pagination values.
+func newInAuditFilter() *inAuditFilter {
+	return &inAuditFilter{
+		Limit: 50, // nolint:mnd // default page size
+	}
+}
+
+// ParseTimes parses given string times into FromParsed and ToParsed fields.
+// It returns parsing error if it occurs.
+func (c *inAuditFilter) ParseTimes() error {
+	for _, parse := range []struct {
+		value  string
+		parsed **time.Time
+	}{{c.From, &c.FromParsed}, {c.To, &c.ToParsed}} {
+		if parse.value == "" {
+			continue
+		}
+		parsedTime, err := time.Parse(time.RFC3339, parse.value)
+		if err != nil {
+			return fmt.Errorf("parse time: %w", err)
+		}
+		*parse.parsed = &parsedTime
+	}
+	return nil
+}
+
+// parsedDates are start and end string dates parsed into time.Time with their precisions.
+type parsedDates struct {
+	start, end *time.Time
+	// precisions of the parsed dates
+	startPrecision, endPrecision entity.DatePrecision
+}
+
+// startPrecisionPtr returns precision of the start date or nil if it is absent.
+func (d *parsedDates) startPrecisionPtr() *entity.DatePrecision {
+	if d.start == nil {
+		return nil
+	}
+	return &d.startPrecision
+}
+
+// endPrecisionPtr returns precision of the end date or nil if it is absent.
+func (d *parsedDates) endPrecisionPtr() *entity.DatePrecision {
+	if d.end == nil {
+		return nil
+	}
+	return &d.endPrecision
+}
+
+// parseDates parses given start and end string dates in YYYY-MM-DD or MM-YYYY format.
+// It returns parsing error if it occurs. Also it checks that end date is not before
+// start date (the whole month of the end date is included for month precision)
 // if both start and end dates is not nil.
-func parseDates(startStr, endStr *string) (startDate, endDate *time.Time, err error) {
+func parseDates(startStr, endStr *string) (parsedDates, error) {
+	var dates parsedDates
 	// parse start date if it is presented
 	if startStr != nil {
-		parsedStart, err := utils.ParseDate(*startStr)
+		parsedStart, withDay, err := utils.ParseDate(*startStr)
 		if err != nil {
-			return startDate, endDate, fmt.Errorf("parse start date: %w", err)
+			return dates, fmt.Errorf("parse start date: %w", err)
 		}
-		startDate = &parsedStart
+		dates.start, dates.startPrecision = &parsedStart, datePrecision(withDay)
 	}
 	// parse end date if it is presented
 	if endStr != nil {
-		parsedEnd, err := utils.ParseDate(*endStr)
+		parsedEnd, withDay, err := utils.ParseDate(*endStr)
 		if err != nil {
-			return startDate, endDate, fmt.Errorf("parse end date: %w", err)
+			return dates, fmt.Errorf("parse end date: %w", err)
 		}
-		endDate = &parsedEnd
+		dates.end, dates.endPrecision = &parsedEnd, datePrecision(withDay)
 	}
-	if startDate != nil && endDate != nil {
+	if dates.start != nil && dates.end != nil {
 		// if end date after start date
-		if startDate.After(*endDate) {
-			return startDate, endDate, errors.New("end date after start date")
+		if dates.start.After(dates.endPrecision.LastDay(*dates.end)) {
+			return dates, errors.New("end date after start date")
 		}
 	}
-	return startDate, endDate, nil
+	return dates, nil
+}
+
+// datePrecision returns precision of the date parsed with or without day.
+func datePrecision(withDay bool) entity.DatePrecision {
+	if withDay {
+		return entity.PrecisionDay
+	}
+	return entity.PrecisionMonth
+}
+
+// monthOf returns the first day of the date month or nil for nil date.
+func monthOf(date *time.Time) *time.Time {
+	if date == nil {
+		return nil
+	}
+	month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return &month
 }
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// setSubsETag sets ETag response header with the subs version.
+func setSubsETag(ctx *fiber.Ctx, subs *entity.Subscription) {
+	ctx.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatInt(subs.Version, 10)))
+}
+
+// parseIfMatch returns subs version from If-Match request header.
+// It returns nil version if header is absent (ErrNoPrecondition if it is required)
+// or it is "*". Only one strong ETag is supported.
+func parseIfMatch(ctx *fiber.Ctx, required bool) (*int64, error) {
+	ifMatch := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	switch {
+	case ifMatch == "" && required:
+		return nil, fmt.Errorf("%w: If-Match header is required", errors.ErrNoPrecondition)
+	case ifMatch == "" || ifMatch == "*":
+		return nil, nil // nolint:nilnil // version is not checked
+	case strings.HasPrefix(ifMatch, "W/"):
+		return nil, fmt.Errorf("%w: weak ETag does not match", errors.ErrPrecondition)
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid If-Match header", errors.ErrValidateData)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ETag does not match", errors.ErrPrecondition)
+	}
+	return &version, nil
+}
@@ -0,0 +1,218 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/utils"
+	"SubscriptionAggregator/internal/pkg/xlsx"
+)
+
+const _exportSheetName = "subs" // name of the XLSX sheet with exported subs
+
+// Media types of export formats.
+var _exportMIMETypes = map[string]string{
+	"csv":   "text/csv; charset=utf-8",
+	"xlsx":  xlsx.MIMEType,
+	"jsonl": "application/jsonl; charset=utf-8",
+}
+
+// exportColumn is a column of the exported subs table.
+type exportColumn struct {
+	name    string
+	numeric bool
+	value   func(subs *entity.SubscriptionExport) string
+}
+
+// Columns of the exported subs table (CSV and XLSX).
+var _exportColumns = []exportColumn{
+	{"id", false, func(s *entity.SubscriptionExport) string { return s.ID }},
+	{"service_name", false, func(s *entity.SubscriptionExport) string { return s.ServiceName }},
+	{"price", true, func(s *entity.SubscriptionExport) string { return s.Price.String() }},
+	{"currency", false, func(s *entity.SubscriptionExport) string { return s.Price.Currency }},
+	{"monthly_price", true, func(s *entity.SubscriptionExport) string {
+		return s.MonthlyPrice.String()
+	}},
+	{"billing_period", false, func(s *entity.SubscriptionExport) string {
+		return string(s.BillingPeriod)
+	}},
+	{"billing_interval", true, func(s *entity.SubscriptionExport) string {
+		return strconv.Itoa(s.BillingInterval)
+	}},
+	{"billing_day", true, func(s *entity.SubscriptionExport) string {
+		return strconv.Itoa(s.BillingDay)
+	}},
+	{"user_id", false, func(s *entity.SubscriptionExport) string { return s.UserID }},
+	{"start_date", false, func(s *entity.SubscriptionExport) string {
+		return formatExportDate(s.StartDate, &s.StartDatePrecision)
+	}},
+	{"end_date", false, func(s *entity.SubscriptionExport) string {
+		return formatExportDate(s.EndDate, s.EndDatePrecision)
+	}},
+	{"canceled_at", false, func(s *entity.SubscriptionExport) string {
+		return formatExportTime(s.CanceledAt)
+	}},
+	{"cancel_reason", false, func(s *entity.SubscriptionExport) string { return s.CancelReason }},
+	{"deleted_at", false, func(s *entity.SubscriptionExport) string {
+		if !s.DeletedAt.Valid {
+			return ""
+		}
+		return formatExportTime(&s.DeletedAt.Time)
+	}},
+	{"version", true, func(s *entity.SubscriptionExport) string {
+		return strconv.FormatInt(s.Version, 10)
+	}},
+	{"months_active", true, func(s *entity.SubscriptionExport) string {
+		return strconv.Itoa(s.MonthsActive)
+	}},
+	{"total_paid", true, func(s *entity.SubscriptionExport) string { return s.TotalPaid.String() }},
+}
+
+// formatExportDate formats subs date like in API by its precision
+// or returns empty string for nil date.
+func formatExportDate(date *time.Time, precision *entity.DatePrecision) string {
+	if date == nil {
+		return ""
+	}
+	return utils.FormatDate(*date, precision != nil && *precision == entity.PrecisionDay)
+}
+
+// formatExportTime formats time in RFC 3339 or returns empty string for nil time.
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// exportWriter writes exported subs in the file format.
+type exportWriter interface {
+	Write(subs *entity.SubscriptionExport) error
+	// Close finishes the file.
+	Close() error
+}
+
+// newExportWriter returns writer of the exported subs in the given format into w.
+// Writer of the table format writes header immediately.
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVExportWriter(w)
+	case "xlsx":
+		return newXLSXExportWriter(w)
+	case "jsonl":
+		return &jsonlExportWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// csvExportWriter writes exported subs into CSV table.
+type csvExportWriter struct {
+	csvWriter *csv.Writer
+	record    []string
+}
+
+// newCSVExportWriter returns CSV export writer with written header.
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	writer := &csvExportWriter{
+		csvWriter: csv.NewWriter(w),
+		record:    make([]string, len(_exportColumns)),
+	}
+	for i, column := range _exportColumns {
+		writer.record[i] = column.name
+	}
+	return writer, writer.csvWriter.Write(writer.record)
+}
+
+func (w *csvExportWriter) Write(subs *entity.SubscriptionExport) error {
+	for i, column := range _exportColumns {
+		w.record[i] = column.value(subs)
+	}
+	return w.csvWriter.Write(w.record)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.csvWriter.Flush()
+	return w.csvWriter.Error()
+}
+
+// xlsxExportWriter writes exported subs into XLSX sheet.
+type xlsxExportWriter struct {
+	xlsxWriter *xlsx.Writer
+	cells      []xlsx.Cell
+}
+
+// newXLSXExportWriter returns XLSX export writer with written header.
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	xlsxWriter, err := xlsx.NewWriter(w, _exportSheetName)
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxExportWriter{
+		xlsxWriter: xlsxWriter,
+		cells:      make([]xlsx.Cell, len(_exportColumns)),
+	}
+	for i, column := range _exportColumns {
+		writer.cells[i] = xlsx.String(column.name)
+	}
+	return writer, xlsxWriter.WriteRow(writer.cells...)
+}
+
+func (w *xlsxExportWriter) Write(subs *entity.SubscriptionExport) error {
+	for i, column := range _exportColumns {
+		value := column.value(subs)
+		if column.numeric {
+			w.cells[i] = xlsx.Number(value)
+			continue
+		}
+		w.cells[i] = xlsx.String(value)
+	}
+	return w.xlsxWriter.WriteRow(w.cells...)
+}
+
+func (w *xlsxExportWriter) Close() error {
+	return w.xlsxWriter.Close()
+}
+
+// jsonlExportWriter writes exported subs as JSON Lines (one JSON object per line).
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlExportWriter) Write(subs *entity.SubscriptionExport) error {
+	return w.encoder.Encode(subs)
+}
+
+func (w *jsonlExportWriter) Close() error {
+	return nil
+}
+
+// writeExport streams subs exported by the export func into w in the given format.
+// Only buffered part of the file is kept in memory, the rest is sent to the client.
+func writeExport(
+	ctx context.Context,
+	w *bufio.Writer,
+	format string,
+	export usecase.SubsExportFunc,
+) error {
+	writer, err := newExportWriter(format, w)
+	if err != nil {
+		return err
+	}
+	if err := export(ctx, writer.Write); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"mime"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/pkg/jsonpatch"
+)
+
+// mediaType returns request body media type without params.
+func mediaType(ctx *fiber.Ctx) string {
+	mediaType, _, err := mime.ParseMediaType(ctx.Get(fiber.HeaderContentType))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// bodySubsUpdate returns subs update with fields presented in request JSON body.
+func (c *SubsController) bodySubsUpdate(
+	ctx *fiber.Ctx,
+	id string,
+	version *int64,
+) (*entity.SubscriptionUpdate, error) {
+	bodyData := &inSubsUpdate{}
+	// parse body
+	if err := ctx.BodyParser(bodyData); err != nil {
+		return nil, fmt.Errorf("parse body: %w", err)
+	}
+	return c.parseSubsUpdate(bodyData, id, version)
+}
+
+// parseSubsUpdate validates input subs data and returns subs update with it.
+func (c *SubsController) parseSubsUpdate(
+	bodyData *inSubsUpdate,
+	id string,
+	version *int64,
+) (*entity.SubscriptionUpdate, error) {
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := bodyData.ParseDates(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	return &entity.SubscriptionUpdate{
+		ID:                 id,
+		ServiceName:        bodyData.ServiceName,
+		Price:              bodyData.Price,
+		Currency:           bodyData.Currency,
+		BillingPeriod:      (*entity.BillingPeriod)(bodyData.BillingPeriod),
+		BillingInterval:    bodyData.BillingInterval,
+		UserID:             bodyData.UserID,
+		StartDate:          bodyData.StartDateParsed,
+		StartDatePrecision: bodyData.StartDatePrecision,
+		EndDate:            bodyData.EndDateParsed,
+		EndDatePrecision:   bodyData.EndDatePrecision,
+		BillingDay:         bodyData.BillingDay,
+		Version:            version,
+	}, nil
+}
+
+// patchedSubsUpdate applies request body patch (JSON Merge Patch or JSON Patch)
+// to the current subs document and returns subs update with all document fields.
+// The update expects the patched subs version, so concurrent changes are not lost.
+func (c *SubsController) patchedSubsUpdate(
+	ctx *fiber.Ctx,
+	id string,
+	version *int64,
+) (*entity.SubscriptionUpdate, error) {
+	// get current subs
+	currentSubs, err := c.subsUC.GetByID(ctx.UserContext(), id, false)
+	if err != nil {
+		return nil, err
+	}
+	if version != nil && *version != currentSubs.Version {
+		return nil, fmt.Errorf("%w: ETag does not match", errors.ErrPrecondition)
+	}
+
+	doc, err := json.Marshal(newInSubsDocument(currentSubs))
+	if err != nil {
+		return nil, fmt.Errorf("marshal subs document: %w", err)
+	}
+	// apply patch
+	applyPatch := jsonpatch.MergePatch
+	if mediaType(ctx) == jsonpatch.MIMEJSONPatch {
+		applyPatch = jsonpatch.Apply
+	}
+	patchedDoc, err := applyPatch(doc, ctx.Body())
+	switch {
+	case goerrors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, fmt.Errorf("%w: %s", errors.ErrConflict, err.Error())
+	case err != nil:
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	bodyData := &inSubsDocument{}
+	// parse patched document
+	decoder := json.NewDecoder(bytes.NewReader(patchedDoc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(bodyData); err != nil {
+		return nil, fmt.Errorf("%w: patched subs: %s", errors.ErrValidateData, err.Error())
+	}
+	// validate patched data as a whole
+	if err := c.valid.Validate(bodyData); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	// parse dates
+	if err := bodyData.ParseDates(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	billingPeriod := entity.BillingPeriod(bodyData.BillingPeriod)
+	return &entity.SubscriptionUpdate{
+		ID:                 id,
+		ServiceName:        &bodyData.ServiceName,
+		Price:              &bodyData.Price,
+		Currency:           &bodyData.Currency,
+		BillingPeriod:      &billingPeriod,
+		BillingInterval:    &bodyData.BillingInterval,
+		UserID:             &bodyData.UserID,
+		StartDate:          bodyData.StartDateParsed,
+		StartDatePrecision: &bodyData.StartDatePrecision,
+		EndDate:            bodyData.EndDateParsed,
+		EndDatePrecision:   bodyData.EndDatePrecision,
+		ClearEndDate:       bodyData.EndDateParsed == nil,
+		BillingDay:         &bodyData.BillingDay,
+		Version:            &currentSubs.Version,
+	}, nil
+}
@@ -2,17 +2,90 @@ package v1
 
 import (
 	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/middleware"
 )
 
 // RegisterSubsEndpoints registers all endpoints for subs entity.
-func RegisterSubsEndpoints(router fiber.Router, controller *SubsController) {
+// Every endpoint requires its scope. Subs creation (single and batch)
+// is handled by idempotency middleware.
+func RegisterSubsEndpoints(
+	router fiber.Router,
+	controller *SubsController,
+	idempotency fiber.Handler,
+) {
+	read := middleware.RequireScope(entity.ScopeSubsRead)
+	write := middleware.RequireScope(entity.ScopeSubsWrite)
+	sum := middleware.RequireScope(entity.ScopeSubsSum)
+
 	crudlPrefix := router.Group("/subs")
 
-	crudlPrefix.Post("/", controller.Create)
-	crudlPrefix.Get("/:id", controller.GetByID)
-	crudlPrefix.Patch("/:id", controller.Update)
-	crudlPrefix.Delete("/:id", controller.Delete)
-	crudlPrefix.Get("/", controller.GetAll)
+	crudlPrefix.Post("/", write, idempotency, controller.Create)
+	// batch, import, export and upcoming routes are registered before routes with subs ID
+	crudlPrefix.Post("/batch", write, idempotency, controller.CreateBatch)
+	crudlPrefix.Patch("/batch", write, controller.UpdateBatch)
+	crudlPrefix.Delete("/batch", write, controller.DeleteBatch)
+	crudlPrefix.Post("/import", write, controller.Import)
+	crudlPrefix.Get("/export", read, controller.Export)
+	crudlPrefix.Get("/upcoming", read, controller.GetUpcoming)
+	crudlPrefix.Get("/:id", read, controller.GetByID)
+	crudlPrefix.Get("/:id/history", read, controller.GetHistory)
+	crudlPrefix.Patch("/:id", write, controller.Update)
+	crudlPrefix.Delete("/:id", write, controller.Delete)
+	crudlPrefix.Post("/:id/restore", write, controller.Restore)
+	crudlPrefix.Post("/:id/cancel", write, controller.Cancel)
+	crudlPrefix.Post("/:id/pause", write, controller.Pause)
+	crudlPrefix.Post("/:id/resume", write, controller.Resume)
+	crudlPrefix.Get("/:id/pauses", read, controller.GetPauses)
+	crudlPrefix.Get("/", read, controller.GetAll)
+
+	sumPrefix := router.Group("/subs-sum")
+
+	sumPrefix.Get("/", sum, controller.GetSum)
+	sumPrefix.Get("/grouped", sum, controller.GetGroupedSum)
+	sumPrefix.Get("/monthly", sum, controller.GetMonthlySum)
+}
+
+// RegisterAPIKeysEndpoints registers all endpoints for API keys entity.
+func RegisterAPIKeysEndpoints(router fiber.Router, controller *APIKeysController) {
+	apiKeysPrefix := router.Group("/api-keys")
+
+	apiKeysPrefix.Post("/", controller.Create)
+	apiKeysPrefix.Get("/", controller.GetAll)
+	apiKeysPrefix.Delete("/:id", controller.Revoke)
+}
+
+// RegisterWebhooksEndpoints registers all endpoints for webhooks entity.
+func RegisterWebhooksEndpoints(router fiber.Router, controller *WebhooksController) {
+	webhooksPrefix := router.Group("/webhooks")
+
+	webhooksPrefix.Post("/", controller.Create)
+	webhooksPrefix.Get("/:id", controller.GetByID)
+	webhooksPrefix.Patch("/:id", controller.Update)
+	webhooksPrefix.Delete("/:id", controller.Delete)
+	webhooksPrefix.Get("/", controller.GetAll)
+	webhooksPrefix.Get("/:id/deliveries", controller.GetDeliveries)
+}
+
+// RegisterPublicCalendarEndpoints registers calendar endpoints which do not require
+// authentication: calendar is requested by calendar apps with user token in URL.
+func RegisterPublicCalendarEndpoints(router fiber.Router, controller *CalendarController) {
+	router.Get("/users/:user_id/charges.ics", controller.GetCharges)
+}
+
+// RegisterCalendarEndpoints registers calendar endpoints which require authentication.
+func RegisterCalendarEndpoints(router fiber.Router, controller *CalendarController) {
+	read := middleware.RequireScope(entity.ScopeSubsRead)
+	write := middleware.RequireScope(entity.ScopeSubsWrite)
+
+	router.Get("/users/:user_id/calendar-token", read, controller.GetToken)
+	router.Post("/users/:user_id/calendar-token", write, controller.RegenerateToken)
+}
+
+// RegisterAuditEndpoints registers all endpoints for audit log.
+func RegisterAuditEndpoints(router fiber.Router, controller *AuditController) {
+	auditPrefix := router.Group("/audit")
 
-	router.Get("/subs-sum", controller.GetSum)
+	auditPrefix.Get("/", controller.GetAll)
 }
@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"fmt"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/usecase"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+// WebhooksController is a HTTP-controller for webhooks usecase.
+type WebhooksController struct {
+	webhooksUC usecase.WebhooksUsecase
+	valid      validator.Validator
+}
+
+// NewWebhooksController returns new WebhooksController.
+func NewWebhooksController(
+	webhooksUC usecase.WebhooksUsecase,
+	valid validator.Validator,
+) *WebhooksController {
+	return &WebhooksController{
+		webhooksUC: webhooksUC,
+		valid:      valid,
+	}
+}
+
+// @summary		Создать вебхук
+// @description	Создание вебхука на события жизненного цикла подписок (только для администратора).
+// @router			/webhooks [post]
+// @id				create-webhook
+// @tags			webhooks
+// @security		BearerAuth
+// @param			Webhook	body		inWebhookCreate	true	"Информация о вебхуке"
+// @success		201		{object}	entity.Webhook
+// @failure		400		"Невалидное тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет прав администратора"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) Create(ctx *fiber.Ctx) error {
+	bodyData := &inWebhookCreate{}
+	// parse body
+	if err := ctx.BodyParser(bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	webhook := entity.Webhook{
+		URL:        bodyData.URL,
+		Secret:     bodyData.Secret,
+		EventTypes: bodyData.EventTypes,
+		Active:     bodyData.Active == nil || *bodyData.Active,
+	}
+	// create webhook
+	if err := c.webhooksUC.Create(ctx.UserContext(), &webhook); err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(webhook)
+}
+
+// @summary		Получить вебхук
+// @description	Получение вебхука по его ID (только для администратора).
+// @router			/webhooks/{id} [get]
+// @id				get-webhook-by-id
+// @tags			webhooks
+// @security		BearerAuth
+// @param			id	path		string	true	"UUID вебхука"
+// @success		200	{object}	entity.Webhook
+// @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		404	"Вебхук не найден"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) GetByID(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// get webhook
+	webhook, err := c.webhooksUC.GetByID(ctx.UserContext(), pathData.ID)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(webhook)
+}
+
+// @summary		Обновить вебхук
+// @description	Обновление вебхука по его ID (только для администратора).
+// @router			/webhooks/{id} [patch]
+// @id				update-webhook
+// @tags			webhooks
+// @security		BearerAuth
+// @param			id		path		string			true	"UUID вебхука"
+// @param			Webhook	body		inWebhookUpdate	true	"Информация о вебхуке"
+// @success		200		{object}	entity.Webhook
+// @failure		400		"Невалидный параметр или тело запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет прав администратора"
+// @failure		404		"Вебхук не найден"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) Update(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	bodyData := &inWebhookUpdate{}
+	// parse body
+	if err := ctx.BodyParser(bodyData); err != nil {
+		return fmt.Errorf("parse body: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(bodyData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	webhook := entity.WebhookUpdate{
+		ID:     pathData.ID,
+		URL:    bodyData.URL,
+		Secret: bodyData.Secret,
+		Active: bodyData.Active,
+	}
+	if bodyData.EventTypes != nil {
+		eventTypes := entity.TextArray(bodyData.EventTypes)
+		webhook.EventTypes = &eventTypes
+	}
+	// update webhook
+	updatedWebhook, err := c.webhooksUC.Update(ctx.UserContext(), &webhook)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(updatedWebhook)
+}
+
+// @summary		Удалить вебхук
+// @description	Удаление вебхука вместе с журналом его доставок по его ID (только для администратора).
+// @router			/webhooks/{id} [delete]
+// @id				delete-webhook
+// @tags			webhooks
+// @security		BearerAuth
+// @param			id	path	string	true	"UUID вебхука"
+// @success		204	"Успешное удаление"
+// @failure		400	"Невалидный параметр запроса"
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		404	"Вебхук не найден"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) Delete(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	// delete webhook
+	if err := c.webhooksUC.Delete(ctx.UserContext(), pathData.ID); err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// @summary		Получить вебхуки
+// @description	Получение всех вебхуков (только для администратора).
+// @router			/webhooks [get]
+// @id				get-all-webhooks
+// @tags			webhooks
+// @security		BearerAuth
+// @success		200	{array}	entity.Webhook
+// @failure		401	"Не авторизован"
+// @failure		403	"Нет прав администратора"
+// @failure		504	"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) GetAll(ctx *fiber.Ctx) error {
+	webhookList, err := c.webhooksUC.GetAll(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(webhookList)
+}
+
+// @summary		Получить журнал доставок вебхука
+// @description	Получение доставок событий вебхуку от новых к старым (только для администратора).
+// @router			/webhooks/{id}/deliveries [get]
+// @id				get-webhook-deliveries
+// @tags			webhooks
+// @security		BearerAuth
+// @param			id		path	string	true	"UUID вебхука"
+// @param			status	query	string	false	"Статус доставки"	Enums(pending, delivered, dead)
+// @param			limit	query	int		false	"Размер страницы"	minimum(1)	maximum(1000)	default(50)
+// @param			offset	query	int		false	"Смещение"
+// @success		200		{array}	entity.WebhookDelivery
+// @failure		400		"Невалидный(ые) параметр(ы) запроса"
+// @failure		401		"Не авторизован"
+// @failure		403		"Нет прав администратора"
+// @failure		404		"Вебхук не найден"
+// @failure		504		"Превышено время выполнения запроса к БД"
+func (c *WebhooksController) GetDeliveries(ctx *fiber.Ctx) error {
+	pathData := &inPathUUID{}
+	// parse path-params
+	if err := ctx.ParamsParser(pathData); err != nil {
+		return fmt.Errorf("parse path: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(pathData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+	queryData := newInWebhookDeliveriesFilter()
+	// parse query-params
+	if err := ctx.QueryParser(queryData); err != nil {
+		return fmt.Errorf("parse query: %w", err)
+	}
+	// validate parsed data
+	if err := c.valid.Validate(queryData); err != nil {
+		return fmt.Errorf("%w: %s", errors.ErrValidateData, err.Error())
+	}
+
+	filter := entity.WebhookDeliveryFilter{
+		WebhookID: pathData.ID,
+		Status:    entity.WebhookDeliveryStatus(queryData.Status),
+		Limit:     queryData.Limit,
+		Offset:    queryData.Offset,
+	}
+	// get deliveries
+	deliveryList, err := c.webhooksUC.GetDeliveries(ctx.UserContext(), &filter)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(deliveryList)
+}
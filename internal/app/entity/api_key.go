@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// LastUsedPrecision is a precision of the API key last usage time:
+// it is saved only if the saved time is older.
+const LastUsedPrecision = time.Minute
+
+// Scopes of API keys.
+const (
+	ScopeSubsRead  = "subs:read"
+	ScopeSubsWrite = "subs:write"
+	ScopeSubsSum   = "subs:sum"
+)
+
+// AllScopes are all available scopes (authenticated by JWT users have all of them).
+var AllScopes = Scopes{ScopeSubsRead, ScopeSubsWrite, ScopeSubsSum}
+
+// Scopes is a list of scopes.
+type Scopes = TextArray
+
+// @description API key for service-to-service access.
+type APIKey struct {
+	// api key uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// api key name
+	Name string `json:"name" gorm:"name;not null"`
+	// SHA-256 hash of the key (hex)
+	KeyHash string `json:"-" gorm:"key_hash;not null"`
+	// allowed scopes
+	Scopes Scopes `json:"scopes" gorm:"scopes;type:text[];not null" swaggertype:"array,string"`
+	// uuid of the user whose subs are accessed by the key (all users if it is absent)
+	OwnerID *string `json:"owner_id,omitempty" gorm:"owner_id;type:uuid"`
+	// expiration time
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"expires_at"`
+	// time of the last key usage (with LastUsedPrecision)
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"last_used_at"`
+	// revocation time
+	RevokedAt *time.Time `json:"revoked_at,omitempty" gorm:"revoked_at"`
+	// creation time
+	CreatedAt time.Time `json:"created_at" gorm:"created_at;not null"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive returns true if key is not revoked and not expired at the given time.
+func (k *APIKey) IsActive(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
+
+// LastUsedOutdated returns true if the saved last usage time of the key
+// is older than LastUsedPrecision at the given time (or it is absent).
+func (k *APIKey) LastUsedOutdated(at time.Time) bool {
+	return k.LastUsedAt == nil || at.Sub(*k.LastUsedAt) >= LastUsedPrecision
+}
+
+// API key list.
+type APIKeyList []APIKey
+
+// @description Created API key with its plaintext value (it is shown only once).
+type APIKeyCreated struct {
+	APIKey
+	// plaintext key to use in "Authorization: ApiKey <key>" header
+	Key string `json:"key"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopes_ValueScan(t *testing.T) {
+	t.Log("Convert scopes to PostgreSQL array and back")
+
+	scopes := Scopes{ScopeSubsRead, ScopeSubsSum}
+	value, err := scopes.Value()
+	require.NoError(t, err)
+	require.Equal(t, "{subs:read,subs:sum}", value)
+
+	scannedScopes := Scopes{}
+	require.NoError(t, scannedScopes.Scan([]byte("{subs:read,subs:sum}")))
+	require.Equal(t, scopes, scannedScopes)
+	require.True(t, scannedScopes.Has(ScopeSubsSum))
+	require.False(t, scannedScopes.Has(ScopeSubsWrite))
+
+	require.NoError(t, scannedScopes.Scan("{}"))
+	require.Empty(t, scannedScopes)
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	t.Log("Check API key is not revoked and not expired")
+
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	require.True(t, (&APIKey{}).IsActive(now))
+	require.True(t, (&APIKey{ExpiresAt: &future}).IsActive(now))
+	require.False(t, (&APIKey{ExpiresAt: &past}).IsActive(now))
+	require.False(t, (&APIKey{RevokedAt: &past}).IsActive(now))
+}
+
+func TestAPIKey_LastUsedOutdated(t *testing.T) {
+	t.Log("Check API key last usage time must be saved")
+
+	now := time.Now().UTC()
+	recent, old := now.Add(-time.Second), now.Add(-LastUsedPrecision)
+
+	require.True(t, (&APIKey{}).LastUsedOutdated(now))
+	require.True(t, (&APIKey{LastUsedAt: &old}).LastUsedOutdated(now))
+	require.False(t, (&APIKey{LastUsedAt: &recent}).LastUsedOutdated(now))
+}
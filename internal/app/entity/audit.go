@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Action on subs recorded in audit log.
+type AuditAction string
+
+// Available audit actions.
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPause   AuditAction = "pause"
+	AuditResume  AuditAction = "resume"
+)
+
+// @description Audit log entry with one subs change.
+type AuditEntry struct {
+	// entry uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// changed subs uuid
+	SubsID string `json:"subs_id" gorm:"subs_id;not null;type:uuid"`
+	// uuid of the subs owner
+	UserID string `json:"user_id" gorm:"user_id;not null;type:uuid"`
+	// action
+	Action AuditAction `json:"action" gorm:"action;not null" enums:"create,update,delete,restore,pause,resume"` // nolint:lll // struct tags
+	// uuid of the user made the change (absent for API key without owner)
+	ActorID *string `json:"actor_id,omitempty" gorm:"actor_id;type:uuid"`
+	// role of the user made the change
+	ActorRole Role `json:"actor_role" gorm:"actor_role;not null"`
+	// uuid of the API key used for the change
+	APIKeyID *string `json:"api_key_id,omitempty" gorm:"api_key_id;type:uuid"`
+	// ID of the request made the change
+	RequestID string `json:"request_id" gorm:"request_id;not null"`
+	// subs state before the change (absent for create)
+	Before RawJSON `json:"before" gorm:"before;type:jsonb" swaggertype:"object"`
+	// subs state after the change (absent for delete)
+	After RawJSON `json:"after" gorm:"after;type:jsonb" swaggertype:"object"`
+	// changed fields with old and new values (subs pauses for pause and resume)
+	Changes RawJSON `json:"changes" gorm:"changes;type:jsonb;not null" swaggertype:"object"`
+	// time of the change
+	CreatedAt time.Time `json:"created_at" gorm:"created_at;not null"`
+}
+
+func (AuditEntry) TableName() string {
+	return "subs_audit"
+}
+
+// Audit entry list.
+type AuditEntryList []AuditEntry
+
+// @description Change of one field with its old and new values.
+type AuditChange struct {
+	// value before the change
+	Old any `json:"old"`
+	// value after the change
+	New any `json:"new"`
+}
+
+// @description Filter and pagination params for AuditEntryList result.
+type AuditFilter struct {
+	// changed subs uuid
+	SubsID string `json:"subs_id,omitempty"`
+	// uuid of the subs owner
+	UserID string `json:"user_id,omitempty"`
+	// uuid of the user made the change
+	ActorID string `json:"actor_id,omitempty"`
+	// action
+	Action AuditAction `json:"action,omitempty"`
+	// min time of the change (inclusive)
+	From *time.Time `json:"from,omitempty"`
+	// max time of the change (exclusive)
+	To *time.Time `json:"to,omitempty"`
+	// max number of items
+	Limit int `json:"limit,omitempty"`
+	// number of items to skip
+	Offset int `json:"offset,omitempty"`
+}
+
+// SubsAuditStates returns JSON subs states before and after the change
+// and changed fields (all fields of the present state for create and delete).
+func SubsAuditStates(
+	before, after *Subscription,
+) (beforeJSON, afterJSON, changes RawJSON, err error) {
+	beforeFields, beforeJSON, err := subsFields(before)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("before: %w", err)
+	}
+	afterFields, afterJSON, err := subsFields(after)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("after: %w", err)
+	}
+
+	changedFields := make(map[string]AuditChange)
+	for field, oldValue := range beforeFields {
+		if newValue := afterFields[field]; !reflect.DeepEqual(oldValue, newValue) {
+			changedFields[field] = AuditChange{Old: oldValue, New: newValue}
+		}
+	}
+	for field, newValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changedFields[field] = AuditChange{New: newValue}
+		}
+	}
+	changes, err = json.Marshal(changedFields)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("changes: %w", err)
+	}
+	return beforeJSON, afterJSON, changes, nil
+}
+
+// PausesAuditChanges returns subs pauses before and after pause or resume
+// as the changed field of the subs.
+func PausesAuditChanges(before, after SubscriptionPauseList) (RawJSON, error) {
+	changes, err := json.Marshal(map[string]AuditChange{
+		"pauses": {Old: before, New: after},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pauses changes: %w", err)
+	}
+	return changes, nil
+}
+
+// subsFields returns JSON subs and its fields values (nil for nil subs).
+func subsFields(subs *Subscription) (map[string]any, RawJSON, error) {
+	if subs == nil {
+		return nil, nil, nil
+	}
+	rawSubs, err := json.Marshal(subs)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := make(map[string]any)
+	if err := json.Unmarshal(rawSubs, &fields); err != nil {
+		return nil, nil, err
+	}
+	delete(fields, "monthly_price") // computed field is not changed itself
+	delete(fields, "version")       // version is changed on every update
+	return fields, rawSubs, nil
+}
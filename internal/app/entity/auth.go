@@ -0,0 +1,54 @@
+package entity
+
+import "context"
+
+// Role of the authenticated user.
+type Role string
+
+// Available roles.
+const (
+	RoleUser    Role = "user"
+	RoleAdmin   Role = "admin"
+	RoleService Role = "service" // API key without owner
+)
+
+// Authenticated user (token subject).
+type AuthUser struct {
+	// user uuid
+	ID string
+	// user role
+	Role Role
+	// allowed scopes
+	Scopes Scopes
+	// uuid of the API key used for authentication (empty for JWT)
+	APIKeyID string
+}
+
+// IsAdmin returns true if user is admin.
+func (u *AuthUser) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// HasAllUsersAccess returns true if user can access subs of all users.
+func (u *AuthUser) HasAllUsersAccess() bool {
+	return u.Role == RoleAdmin || u.Role == RoleService
+}
+
+// HasScope returns true if user is allowed to use the given scope.
+func (u *AuthUser) HasScope(scope string) bool {
+	return u.Scopes.Has(scope)
+}
+
+// Context key for authenticated user.
+type authUserCtxKey struct{}
+
+// ContextWithAuthUser returns copy of the context with the authenticated user.
+func ContextWithAuthUser(ctx context.Context, user *AuthUser) context.Context {
+	return context.WithValue(ctx, authUserCtxKey{}, user)
+}
+
+// AuthUserFromContext returns authenticated user from the context if it is presented.
+func AuthUserFromContext(ctx context.Context) (*AuthUser, bool) {
+	user, ok := ctx.Value(authUserCtxKey{}).(*AuthUser)
+	return user, ok
+}
@@ -0,0 +1,12 @@
+package entity
+
+// @description Calendar token of the user to subscribe to the calendar of his charges.
+type CalendarToken struct {
+	// user uuid
+	UserID string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	// secret token of the calendar (it is valid until it is regenerated
+	// or server calendar secret is changed)
+	Token string `json:"token"`
+	// URL of the calendar (iCalendar) with the token
+	URL string `json:"url"`
+}
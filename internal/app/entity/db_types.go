@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// TextArray is a list of simple strings stored as PostgreSQL text array.
+// Items must not contain commas, quotes, braces and spaces.
+type TextArray []string
+
+// Has returns true if array contains the given item.
+func (a TextArray) Has(item string) bool {
+	for _, arrayItem := range a {
+		if arrayItem == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer.
+func (a TextArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+// Scan implements sql.Scanner.
+func (a *TextArray) Scan(src any) error {
+	var rawArray string
+	switch value := src.(type) {
+	case string:
+		rawArray = value
+	case []byte:
+		rawArray = string(value)
+	default:
+		return errors.New("unsupported text array type")
+	}
+	rawArray = strings.Trim(rawArray, "{}")
+	if rawArray == "" {
+		*a = TextArray{}
+		return nil
+	}
+	*a = strings.Split(rawArray, ",")
+	return nil
+}
+
+// RawJSON is a raw JSON document stored as PostgreSQL jsonb.
+type RawJSON []byte
+
+// Value implements driver.Valuer.
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil // nolint:nilnil // NULL value
+	}
+	return string(j), nil
+}
+
+// Scan implements sql.Scanner.
+func (j *RawJSON) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*j = nil
+	case string:
+		*j = RawJSON(value)
+	case []byte:
+		*j = append(RawJSON(nil), value...)
+	default:
+		return errors.New("unsupported json type")
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
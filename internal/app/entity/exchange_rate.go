@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Base currency of exchange rates (ISO 4217).
+const ExchangeRateBase = "EUR"
+
+// @description Exchange rate of the currency against ExchangeRateBase.
+type ExchangeRate struct {
+	// currency (ISO 4217)
+	Currency string `json:"currency" gorm:"currency;primaryKey"`
+	// date of the rate
+	RateDate time.Time `json:"rate_date" gorm:"rate_date;primaryKey;type:date"`
+	// amount of currency for 1 unit of base currency
+	Rate float64 `json:"rate" gorm:"rate;not null"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
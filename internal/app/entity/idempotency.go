@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Idempotency key of the request with the saved response to replay it.
+type IdempotencyKey struct {
+	// key owner (authenticated user or API key)
+	Owner string `gorm:"owner;primaryKey"`
+	// key from Idempotency-Key header
+	Key string `gorm:"key;primaryKey"`
+	// hash of the request the key is used with
+	RequestHash string `gorm:"request_hash;not null"`
+	// response status (0 while the request is in progress)
+	ResponseStatus int `gorm:"response_status;not null"`
+	// saved response headers
+	ResponseHeaders RawJSON `gorm:"response_headers;type:jsonb"`
+	// saved response body
+	ResponseBody []byte `gorm:"response_body"`
+	// time of the request holding the key
+	CreatedAt time.Time `gorm:"created_at;not null"`
+	// time until which the request in progress holds the key
+	LockedUntil time.Time `gorm:"locked_until;not null"`
+	// time after which the key can be used again
+	ExpiresAt time.Time `gorm:"expires_at;not null"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IsCompleted returns true if the request with the key is completed and its response is saved.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.ResponseStatus != 0
+}
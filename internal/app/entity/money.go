@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+const _defaultCurrencyExponent = 2 // number of minor unit digits for most currencies
+
+// Number of minor unit digits of currencies (ISO 4217) which differ from the default one.
+// It must be kept in sync with currency_exponent DB function.
+var _currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns number of minor unit digits of the currency.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := _currencyExponents[currency]; ok {
+		return exponent
+	}
+	return _defaultCurrencyExponent
+}
+
+// @description Money amount in the currency.
+// Amount is serialized to JSON as a decimal string in major units (e.g. "199.99").
+//
+// Gorm columns are named as subs table columns where Money is embedded.
+type Money struct {
+	// amount in minor units (e.g. kopecks), decimal string in major units in JSON
+	Amount int64 `json:"amount" gorm:"column:price;not null" swaggertype:"string" example:"199.99"`
+	// currency (ISO 4217)
+	Currency string `json:"currency" gorm:"column:currency;not null;default:RUB" example:"RUB"`
+}
+
+// ParseMoney parses decimal amount string in major units (e.g. "199.99") of the currency.
+// It returns ErrValidateData if amount is not a decimal number, it has more fraction digits
+// than the currency has or it overflows minor units amount.
+func ParseMoney(amount, currency string) (Money, error) {
+	exponent := CurrencyExponent(currency)
+
+	digits := strings.TrimPrefix(amount, "-")
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart == "" || strings.ContainsAny(intPart+fracPart, "+-") {
+		return Money{}, fmt.Errorf("%w: invalid amount %q", errors.ErrValidateData, amount)
+	}
+	if len(fracPart) > exponent {
+		return Money{}, fmt.Errorf("%w: amount %q has more than %d fraction digits for %s",
+			errors.ErrValidateData, amount, exponent, currency)
+	}
+	// pad fraction part to the currency exponent and parse the whole number of minor units
+	minorUnits, err := strconv.ParseInt(intPart+fracPart+strings.Repeat("0", exponent-len(fracPart)),
+		10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: invalid amount %q: %s",
+			errors.ErrValidateData, amount, err.Error())
+	}
+	if strings.HasPrefix(amount, "-") {
+		minorUnits = -minorUnits
+	}
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// String returns decimal amount string in major units.
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	// uint64 is used to handle min int64 value properly
+	absAmount := uint64(m.Amount) // nolint:gosec // two's complement is handled below
+	sign := ""
+	if m.Amount < 0 {
+		absAmount = -absAmount
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absAmount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	// pad amount to have at least one digit in the integer part
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Divide returns money amount divided by the given divisor (rounded to minor units).
+func (m Money) Divide(divisor float64) Money {
+	return Money{
+		Amount:   int64(math.Round(float64(m.Amount) / divisor)),
+		Currency: m.Currency,
+	}
+}
+
+// moneyJSON is a JSON representation of Money.
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.String(), Currency: m.Currency})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := moneyJSON{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseMoney(raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
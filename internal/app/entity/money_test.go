@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Log("Parse decimal amount into minor units")
+
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "integer", amount: "400", currency: "RUB", expected: 40000},
+		{name: "fraction", amount: "199.99", currency: "RUB", expected: 19999},
+		{name: "short fraction", amount: "0.5", currency: "USD", expected: 50},
+		{name: "zero exponent", amount: "1500", currency: "JPY", expected: 1500},
+		{name: "three digits exponent", amount: "1.234", currency: "KWD", expected: 1234},
+		{name: "negative", amount: "-10.01", currency: "RUB", expected: -1001},
+		{name: "too many fraction digits", amount: "1.999", currency: "RUB", wantErr: true},
+		{name: "fraction for zero exponent", amount: "1.5", currency: "JPY", wantErr: true},
+		{name: "not a number", amount: "abc", currency: "RUB", wantErr: true},
+		{name: "empty integer part", amount: ".5", currency: "RUB", wantErr: true},
+		{name: "overflow", amount: "92233720368547758.08", currency: "RUB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := ParseMoney(tt.amount, tt.currency)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errors.ErrValidateData)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Money{Amount: tt.expected, Currency: tt.currency}, money)
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	t.Log("Format minor units as decimal amount")
+
+	require.Equal(t, "199.99", Money{Amount: 19999, Currency: "RUB"}.String())
+	require.Equal(t, "0.05", Money{Amount: 5, Currency: "USD"}.String())
+	require.Equal(t, "-0.50", Money{Amount: -50, Currency: "EUR"}.String())
+	require.Equal(t, "1500", Money{Amount: 1500, Currency: "JPY"}.String())
+	require.Equal(t, "1.234", Money{Amount: 1234, Currency: "KWD"}.String())
+	require.Equal(t, "-92233720368547758.08",
+		Money{Amount: -9223372036854775808, Currency: "RUB"}.String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	t.Log("Marshal and unmarshal money as decimal string")
+
+	money := Money{Amount: 19999, Currency: "RUB"}
+	rawMoney, err := json.Marshal(money)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"199.99","currency":"RUB"}`, string(rawMoney))
+
+	parsedMoney := Money{}
+	require.NoError(t, json.Unmarshal(rawMoney, &parsedMoney))
+	require.Equal(t, money, parsedMoney)
+
+	err = json.Unmarshal([]byte(`{"amount":"1.5","currency":"JPY"}`), &parsedMoney)
+	require.ErrorIs(t, err, errors.ErrValidateData)
+}
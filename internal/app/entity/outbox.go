@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// Status of outbox message.
+type OutboxStatus string
+
+// Available outbox message statuses.
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxDead    OutboxStatus = "dead" // all attempts are failed
+)
+
+// Event message saved in the same transaction with subs change
+// and relayed to publishers in background.
+type OutboxMessage struct {
+	// message uuid (the same as event uuid)
+	ID string `gorm:"id;primaryKey;type:uuid"`
+	// event type
+	EventType EventType `gorm:"event_type;not null"`
+	// JSON event (Event)
+	Payload RawJSON `gorm:"payload;type:jsonb;not null"`
+	// relay status
+	Status OutboxStatus `gorm:"status;not null"`
+	// number of failed relay attempts
+	Attempts int `gorm:"attempts;not null"`
+	// time of the next relay attempt (for pending message)
+	NextAttemptAt time.Time `gorm:"next_attempt_at;not null"`
+	// error of the last failed relay attempt
+	LastError string `gorm:"last_error;not null"`
+	// names of publishers which have published the message
+	PublishedTo TextArray `gorm:"published_to;not null"`
+	// creation time
+	CreatedAt time.Time `gorm:"created_at;not null"`
+	// time when message is published to all publishers
+	SentAt *time.Time `gorm:"sent_at"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "context"
+
+// Context key for request ID.
+type requestIDCtxKey struct{}
+
+// ContextWithRequestID returns copy of the context with the request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, requestID)
+}
+
+// RequestIDFromContext returns request ID from the context (empty if it is not presented).
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return requestID
+}
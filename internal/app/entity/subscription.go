@@ -1,7 +1,82 @@
 // Package entity contains all app entities.
 package entity
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// Billing period of the subscription.
+type BillingPeriod string
+
+// Available billing periods.
+const (
+	BillingWeekly    BillingPeriod = "weekly"
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+)
+
+// Precision of the subs date.
+type DatePrecision string
+
+// Available date precisions.
+const (
+	// only month is given, date is the first day of the month
+	PrecisionMonth DatePrecision = "month"
+	PrecisionDay   DatePrecision = "day"
+)
+
+// LastDay returns the last day of the period given by the date with the precision:
+// the date itself for day precision and the last day of its month otherwise.
+func (p DatePrecision) LastDay(date time.Time) time.Time {
+	if p == PrecisionDay {
+		return date
+	}
+	return lastMonthDay(date)
+}
+
+const (
+	_maxServiceNameLen = 100 // max number of service name characters
+	_maxBillingDay     = 31  // max day of month
+	_hoursInDay        = 24  // number of hours in one day
+	// max number of days which charge on the billing day can be before the same day
+	// of the start date month, e.g. on the 1st of the month for subs started on the 31st
+	_maxBillingDayShift = 30
+)
+
+// Currency of subs prices by default (ISO 4217).
+const DefaultCurrency = "RUB"
+
+// Number of months in the billing period (52 weeks in 12 months).
+var _billingPeriodMonths = map[BillingPeriod]float64{
+	BillingWeekly:    12.0 / 52.0,
+	BillingMonthly:   1,
+	BillingQuarterly: 3,
+	BillingYearly:    12,
+}
+
+// chargeStep is a step between subs charges: in months (charges on the billing day)
+// or in days.
+type chargeStep struct {
+	months, days int
+	// max number of days in the step
+	maxDays int
+}
+
+// Step between charges of the billing period.
+var _billingPeriodSteps = map[BillingPeriod]chargeStep{
+	BillingWeekly:    {days: 7, maxDays: 7},
+	BillingMonthly:   {months: 1, maxDays: 31},
+	BillingQuarterly: {months: 3, maxDays: 92},
+	BillingYearly:    {months: 12, maxDays: 366},
+}
 
 // @description Subscription object
 type Subscription struct {
@@ -9,37 +84,519 @@ type Subscription struct {
 	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
 	// service name
 	ServiceName string `json:"service_name" gorm:"service_name;not null"`
-	// price
-	Price int `json:"price" gorm:"price;not null"`
+	// price for one billing period
+	Price Money `json:"price" gorm:"embedded"`
+	// price normalized to one month
+	MonthlyPrice Money `json:"monthly_price" gorm:"-"`
+	// billing period
+	BillingPeriod BillingPeriod `json:"billing_period" gorm:"billing_period;not null;default:monthly"`
+	// number of billing periods between charges
+	BillingInterval int `json:"billing_interval" gorm:"billing_interval;not null;default:1"`
 	// user uuid
 	UserID string `json:"user_id" gorm:"user_id;not null"`
 	// start date
 	StartDate *time.Time `json:"start_date" gorm:"start_date;not null"`
-	// end date
+	// precision of the start date
+	StartDatePrecision DatePrecision `json:"start_date_precision" gorm:"default:month"`
+	// end date (the whole month is included for month precision)
 	EndDate *time.Time `json:"end_date,omitempty" gorm:"end_date"`
+	// precision of the end date (present only with end date, month precision if it is absent)
+	EndDatePrecision *DatePrecision `json:"end_date_precision,omitempty" gorm:"end_date_precision"`
+	// day of month of charges (the last day of the shorter month), it is not used for weekly subs
+	BillingDay int `json:"billing_day" gorm:"billing_day;not null;default:1" example:"17"`
+	// time of the cancellation
+	CanceledAt *time.Time `json:"canceled_at,omitempty" gorm:"canceled_at"`
+	// reason of the cancellation
+	CancelReason string `json:"cancel_reason,omitempty" gorm:"cancel_reason;not null;default:''"`
+	// date of the next charge from the current date (absent if there are no more charges)
+	NextChargeDate *time.Time `json:"next_charge_date,omitempty" gorm:"-"`
+	// version incremented on every update
+	Version int64 `json:"version" gorm:"version;not null;default:1"`
+	// time of the deletion (present only for deleted subs)
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitzero" gorm:"deleted_at" swaggertype:"string"`
 }
 
 func (Subscription) TableName() string {
 	return "subs"
 }
 
+// MonthlyEquivalent returns subs price normalized to one month (rounded to minor units).
+func (s *Subscription) MonthlyEquivalent() Money {
+	periodMonths, ok := _billingPeriodMonths[s.BillingPeriod]
+	if !ok || s.BillingInterval <= 0 {
+		return s.Price
+	}
+	return s.Price.Divide(periodMonths * float64(s.BillingInterval))
+}
+
+// ChargeDate returns date of the subs charge with the given zero-based number.
+// Subs is charged on its start date and then every billing period: weekly charges follow
+// the start date and other ones are on the billing day of the month (on the last day
+// of the month if it is shorter). Pauses are not considered.
+// It returns false for invalid billing period.
+func (s *Subscription) ChargeDate(n int) (time.Time, bool) {
+	step, ok := _billingPeriodSteps[s.BillingPeriod]
+	if !ok || s.BillingInterval <= 0 || s.StartDate == nil {
+		return time.Time{}, false
+	}
+	k := n * s.BillingInterval
+	if n == 0 || step.months == 0 {
+		return s.StartDate.AddDate(0, 0, k*step.days), true
+	}
+	month := time.Date(s.StartDate.Year(), s.StartDate.Month()+time.Month(k*step.months), 1,
+		0, 0, 0, 0, time.UTC)
+	billingDay := s.BillingDay
+	if billingDay <= 0 {
+		billingDay = s.StartDate.Day()
+	}
+	return month.AddDate(0, 0, min(billingDay, lastMonthDay(month).Day())-1), true
+}
+
+// LastDay returns the last day of the subs: its end date with day precision
+// or the last day of the end date month. It returns false for subs without end date.
+func (s *Subscription) LastDay() (time.Time, bool) {
+	if s.EndDate == nil {
+		return time.Time{}, false
+	}
+	precision := PrecisionMonth
+	if s.EndDatePrecision != nil {
+		precision = *s.EndDatePrecision
+	}
+	return precision.LastDay(*s.EndDate), true
+}
+
+// SetDefaults sets month precision of the subs dates if it is not set
+// and the start date day as the billing day if it is not set.
+func (s *Subscription) SetDefaults() {
+	if s.StartDatePrecision == "" {
+		s.StartDatePrecision = PrecisionMonth
+	}
+	switch {
+	case s.EndDate == nil:
+		s.EndDatePrecision = nil
+	case s.EndDatePrecision == nil:
+		precision := PrecisionMonth
+		s.EndDatePrecision = &precision
+	}
+	if s.BillingDay == 0 && s.StartDate != nil {
+		s.BillingDay = s.StartDate.Day()
+	}
+}
+
+// AfterFind fills computed fields after subs is got from DB.
+func (s *Subscription) AfterFind(_ *gorm.DB) error {
+	s.MonthlyPrice = s.MonthlyEquivalent()
+	return nil
+}
+
+// AfterSave fills computed fields after subs is saved to DB.
+func (s *Subscription) AfterSave(_ *gorm.DB) error {
+	s.MonthlyPrice = s.MonthlyEquivalent()
+	return nil
+}
+
 // Subscription list.
 type SubscriptionList []Subscription
 
+// @description Result of one item of the subs batch.
+type SubscriptionBatchResult struct {
+	// index of the item in the batch
+	Index int `json:"index"`
+	// HTTP status code of the item
+	Status int `json:"status"`
+	// subs uuid
+	ID string `json:"id,omitempty"`
+	// created or updated subs
+	Subs *Subscription `json:"subs,omitempty"`
+	// error message of the failed item
+	Error string `json:"error,omitempty"`
+	// error of the failed item
+	Err error `json:"-"`
+}
+
+// Results of the subs batch items.
+type SubscriptionBatchResultList []SubscriptionBatchResult
+
+// @description Report of subs import from file.
+type SubscriptionImportReport struct {
+	// subs were only validated, not created
+	DryRun bool `json:"dry_run"`
+	// number of data rows in the file
+	Total int `json:"total"`
+	// number of created subs (valid rows for dry run)
+	Imported int `json:"imported"`
+	// number of invalid rows
+	Failed int `json:"failed"`
+	// errors of the invalid rows
+	Errors []SubscriptionImportError `json:"errors"`
+}
+
+// @description Error of one row of the imported file.
+type SubscriptionImportError struct {
+	// line number of the row in the file
+	Line int `json:"line" example:"2"`
+	// HTTP status code of the row error
+	Status int `json:"status" example:"400"`
+	// error message
+	Error string `json:"error"`
+}
+
+// @description Subscription with values computed for export.
+type SubscriptionExport struct {
+	Subscription
+	// number of months from start date to end date or current month without paused months
+	MonthsActive int `json:"months_active" example:"3"`
+	// total charged from start date to current date in price currency
+	TotalPaid Money `json:"total_paid"`
+}
+
+// SubscriptionSchedule is a recurring schedule of the subs charges.
+type SubscriptionSchedule struct {
+	Subscription
+	// date of the last possible charge (nil for endless subs)
+	Until *time.Time
+	// charge dates skipped by pauses (before Until), sorted
+	Skipped []time.Time
+}
+
+// Subscription schedule list.
+type SubscriptionScheduleList []SubscriptionSchedule
+
+// @description Upcoming charge of the subscription.
+type SubscriptionCharge struct {
+	// charge date
+	Date time.Time `json:"date" example:"2025-07-17T00:00:00Z"`
+	// subscription uuid
+	SubsID string `json:"subs_id"`
+	// service name
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	// user uuid
+	UserID string `json:"user_id"`
+	// charged amount (subs price)
+	Amount Money `json:"amount"`
+}
+
+// Subscription charge list.
+type SubscriptionChargeList []SubscriptionCharge
+
+// NewSubscriptionSchedule returns charges schedule of the subs with the given pauses.
+// The last day of the subs is the last charged day, charges within paused months
+// are skipped and pause without end stops charges. It returns false if subs has no charges.
+func NewSubscriptionSchedule(
+	subs *Subscription,
+	pauses SubscriptionPauseList,
+) (*SubscriptionSchedule, bool) {
+	schedule := &SubscriptionSchedule{Subscription: *subs}
+	if _, ok := subs.ChargeDate(0); !ok {
+		return nil, false
+	}
+	if until, ok := subs.LastDay(); ok {
+		schedule.Until = &until
+	}
+
+	var skippedUntil time.Time // end of the last finished pause
+	for _, pause := range pauses {
+		if pause.EndDate == nil {
+			// charges are stopped before the month of the pause
+			until := pause.StartDate.AddDate(0, 0, -1)
+			if schedule.Until == nil || until.Before(*schedule.Until) {
+				schedule.Until = &until
+			}
+			continue
+		}
+		if pauseEnd := lastMonthDay(*pause.EndDate); pauseEnd.After(skippedUntil) {
+			skippedUntil = pauseEnd
+		}
+	}
+	if schedule.Until != nil && schedule.Until.Before(*subs.StartDate) {
+		return nil, false
+	}
+	if schedule.Until != nil && schedule.Until.Before(skippedUntil) {
+		skippedUntil = *schedule.Until
+	}
+
+	for n := 0; ; n++ {
+		chargeDate, _ := subs.ChargeDate(n)
+		if chargeDate.After(skippedUntil) {
+			break
+		}
+		if pauses.Covers(chargeDate) {
+			schedule.Skipped = append(schedule.Skipped, chargeDate)
+		}
+	}
+	return schedule, true
+}
+
+// Charges returns charge dates of the schedule within [from, to] dates sorted.
+func (s *SubscriptionSchedule) Charges(from, to time.Time) []time.Time {
+	var charges []time.Time
+	if s.Until != nil && s.Until.Before(to) {
+		to = *s.Until
+	}
+	for n := s.firstChargeNumber(from); ; n++ {
+		chargeDate, _ := s.ChargeDate(n)
+		if chargeDate.After(to) {
+			return charges
+		}
+		if !chargeDate.Before(from) && !slices.ContainsFunc(s.Skipped, chargeDate.Equal) {
+			charges = append(charges, chargeDate)
+		}
+	}
+}
+
+// NextCharge returns the first charge date of the schedule not before the given date.
+// It returns false if there are no more charges.
+func (s *SubscriptionSchedule) NextCharge(from time.Time) (time.Time, bool) {
+	for n := s.firstChargeNumber(from); ; n++ {
+		chargeDate, _ := s.ChargeDate(n)
+		if s.Until != nil && chargeDate.After(*s.Until) {
+			return time.Time{}, false
+		}
+		if !chargeDate.Before(from) && !slices.ContainsFunc(s.Skipped, chargeDate.Equal) {
+			return chargeDate, true
+		}
+	}
+}
+
+// firstChargeNumber returns number of the charge which is not after the given date
+// and close to it, so charges before it are not iterated.
+func (s *SubscriptionSchedule) firstChargeNumber(from time.Time) int {
+	step := _billingPeriodSteps[s.BillingPeriod]
+	days := int(from.Sub(*s.StartDate).Hours()/_hoursInDay) - _maxBillingDayShift
+	if days <= 0 {
+		return 0
+	}
+	return days / (step.maxDays * s.BillingInterval)
+}
+
+// lastMonthDay returns the last day of the date month.
+func lastMonthDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// @description Filter, sort and pagination params for SubscriptionList result.
+type SubscriptionListFilter struct {
+	// service name
+	ServiceName string `json:"service_name,omitempty"`
+	// user uuid
+	UserID string `json:"user_id,omitempty"`
+	// date at which subs must be active
+	ActiveAt *time.Time `json:"active_at,omitempty"`
+	// precision of the active at date (subs must be active at any day of the month
+	// for month precision)
+	ActiveAtPrecision DatePrecision `json:"active_at_precision,omitempty"`
+	// min price in minor units (inclusive)
+	PriceMin *int64 `json:"price_min,omitempty"`
+	// max price in minor units (inclusive)
+	PriceMax *int64 `json:"price_max,omitempty"`
+	// include deleted subs
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+	// field to sort by
+	Sort string `json:"sort,omitempty"`
+	// sort order (asc or desc)
+	Order string `json:"order,omitempty"`
+	// max number of items on the page
+	Limit int `json:"limit,omitempty"`
+	// number of items to skip (offset pagination)
+	Offset int `json:"offset,omitempty"`
+	// cursor from previous page (keyset pagination)
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// @description Page of subscriptions.
+type SubscriptionPage struct {
+	// subs on the page
+	Items SubscriptionList `json:"items"`
+	// total number of subs matched by filter
+	Total int64 `json:"total"`
+	// cursor to get next page (absent on the last page)
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 // @description Subscription object variant for update it.
 type SubscriptionUpdate struct {
 	// subscription uuid
 	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
 	// service name
 	ServiceName *string `json:"service_name" gorm:"service_name"`
-	// price
-	Price *int `json:"price" gorm:"price"`
+	// price for one billing period (decimal string in major units)
+	Price *string `json:"price" gorm:"-"`
+	// price for one billing period in minor units (it is set from Price and Currency)
+	PriceAmount *int64 `json:"-" gorm:"column:price"`
+	// price currency (ISO 4217)
+	Currency *string `json:"currency" gorm:"currency"`
+	// billing period
+	BillingPeriod *BillingPeriod `json:"billing_period" gorm:"billing_period"`
+	// number of billing periods between charges
+	BillingInterval *int `json:"billing_interval" gorm:"billing_interval"`
 	// user uuid
 	UserID *string `json:"user_id" gorm:"user_id"`
 	// start date
 	StartDate *time.Time `json:"start_date" gorm:"start_date"`
+	// precision of the start date (it must be set with start date)
+	StartDatePrecision *DatePrecision `json:"start_date_precision" gorm:"start_date_precision"`
 	// end date
 	EndDate *time.Time `json:"end_date" gorm:"end_date"`
+	// precision of the end date (it must be set with end date)
+	EndDatePrecision *DatePrecision `json:"end_date_precision" gorm:"end_date_precision"`
+	// clear end date (end date must be nil)
+	ClearEndDate bool `json:"-" gorm:"-"`
+	// day of month of charges
+	BillingDay *int `json:"billing_day" gorm:"billing_day"`
+	// time of the cancellation (it is set only by cancel)
+	CanceledAt *time.Time `json:"-" gorm:"canceled_at"`
+	// reason of the cancellation (it is set only by cancel)
+	CancelReason *string `json:"-" gorm:"cancel_reason"`
+	// expected current version (it is not checked if it is absent)
+	Version *int64 `json:"-" gorm:"-"`
+}
+
+// Merge returns copy of the subs with fields set in the update.
+// Price amount is taken from PriceAmount, so it must be resolved before.
+func (u *SubscriptionUpdate) Merge(subs *Subscription) *Subscription {
+	merged := *subs
+	if u.ServiceName != nil {
+		merged.ServiceName = *u.ServiceName
+	}
+	if u.PriceAmount != nil {
+		merged.Price.Amount = *u.PriceAmount
+	}
+	if u.Currency != nil {
+		merged.Price.Currency = *u.Currency
+	}
+	if u.BillingPeriod != nil {
+		merged.BillingPeriod = *u.BillingPeriod
+	}
+	if u.BillingInterval != nil {
+		merged.BillingInterval = *u.BillingInterval
+	}
+	if u.UserID != nil {
+		merged.UserID = *u.UserID
+	}
+	if u.StartDate != nil {
+		merged.StartDate = u.StartDate
+	}
+	if u.StartDatePrecision != nil {
+		merged.StartDatePrecision = *u.StartDatePrecision
+	}
+	if u.EndDate != nil || u.ClearEndDate {
+		merged.EndDate = u.EndDate
+		merged.EndDatePrecision = u.EndDatePrecision
+	}
+	if u.BillingDay != nil {
+		merged.BillingDay = *u.BillingDay
+	}
+	if u.CanceledAt != nil {
+		merged.CanceledAt = u.CanceledAt
+	}
+	if u.CancelReason != nil {
+		merged.CancelReason = *u.CancelReason
+	}
+	return &merged
+}
+
+// SetDefaults sets month precision of the update dates if it is not set.
+// If start date of the subs is changed without billing day, the billing day follows
+// the new start date day.
+func (u *SubscriptionUpdate) SetDefaults(subs *Subscription) {
+	if u.StartDate != nil && u.StartDatePrecision == nil {
+		precision := PrecisionMonth
+		u.StartDatePrecision = &precision
+	}
+	if u.EndDate != nil && u.EndDatePrecision == nil {
+		precision := PrecisionMonth
+		u.EndDatePrecision = &precision
+	}
+	if u.StartDate == nil || subs.StartDate == nil || u.StartDate.Equal(*subs.StartDate) {
+		return
+	}
+	if u.BillingDay == nil || *u.BillingDay == subs.BillingDay {
+		billingDay := u.StartDate.Day()
+		u.BillingDay = &billingDay
+	}
+}
+
+// NormalizeServiceName returns service name without leading, trailing and repeated spaces.
+func NormalizeServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// Validate checks subs invariants. It returns ValidationError with all invalid fields.
+func (s *Subscription) Validate() error {
+	var fields []errors.FieldError
+	switch {
+	case s.ServiceName == "":
+		fields = append(fields, errors.FieldError{Field: "service_name", Message: "must not be empty"})
+	case utf8.RuneCountInString(s.ServiceName) > _maxServiceNameLen:
+		fields = append(fields, errors.FieldError{Field: "service_name", Message: "is too long"})
+	}
+	if s.Price.Amount <= 0 {
+		fields = append(fields, errors.FieldError{Field: "price", Message: "must be positive"})
+	}
+	if s.StartDate == nil {
+		fields = append(fields, errors.FieldError{Field: "start_date", Message: "is required"})
+	} else if lastDay, ok := s.LastDay(); ok && s.StartDate.After(lastDay) {
+		fields = append(fields, errors.FieldError{
+			Field:   "end_date",
+			Message: "must not be before start_date",
+		})
+	}
+	if s.BillingDay < 1 || s.BillingDay > _maxBillingDay {
+		fields = append(fields, errors.FieldError{
+			Field:   "billing_day",
+			Message: "must be from 1 to 31",
+		})
+	}
+
+	if len(fields) != 0 {
+		return &errors.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// @description Cancellation of the subscription.
+type SubscriptionCancel struct {
+	// subscription uuid
+	ID string `json:"id"`
+	// the last month or day of the subs (the current month if it is absent)
+	EndDate *time.Time `json:"end_date,omitempty"`
+	// precision of the end date
+	EndDatePrecision DatePrecision `json:"end_date_precision,omitempty"`
+	// reason of the cancellation
+	Reason string `json:"reason,omitempty"`
+}
+
+// @description Interval of months when the subscription is paused and is not charged.
+type SubscriptionPause struct {
+	// pause uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// subscription uuid
+	SubsID string `json:"subs_id" gorm:"subs_id;not null;type:uuid"`
+	// the first paused month
+	StartDate *time.Time `json:"start_date" gorm:"start_date;not null"`
+	// the last paused month (absent until resume)
+	EndDate *time.Time `json:"end_date,omitempty" gorm:"end_date"`
+	// creation time
+	CreatedAt time.Time `json:"created_at" gorm:"created_at;not null"`
+}
+
+func (SubscriptionPause) TableName() string {
+	return "subs_pauses"
+}
+
+// Subscription pause list.
+type SubscriptionPauseList []SubscriptionPause
+
+// Covers returns true if the date month is paused by any pause of the list.
+func (l SubscriptionPauseList) Covers(date time.Time) bool {
+	month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, pause := range l {
+		if !month.Before(*pause.StartDate) &&
+			(pause.EndDate == nil || !month.After(*pause.EndDate)) {
+			return true
+		}
+	}
+	return false
 }
 
 // @description Filter for SubscriptionSum result.
@@ -52,6 +609,37 @@ type SubscriptionSumFilter struct {
 	StartDate *time.Time `json:"start_date,omitempty"`
 	// end date
 	EndDate *time.Time `json:"end_date,omitempty"`
+	// precision of the end date (the whole month is included for month precision)
+	EndDatePrecision DatePrecision `json:"end_date_precision,omitempty"`
+	// currency to convert prices to (ISO 4217)
+	Currency string `json:"currency,omitempty"`
+}
+
+// LastDay returns the last day of the filter period by its end date precision
+// or nil if end date is not set.
+func (f *SubscriptionSumFilter) LastDay() *time.Time {
+	if f.EndDate == nil {
+		return nil
+	}
+	lastDay := f.EndDatePrecision.LastDay(*f.EndDate)
+	return &lastDay
+}
+
+// TargetCurrency returns currency to convert prices to (DefaultCurrency if it is not set).
+func (f *SubscriptionSumFilter) TargetCurrency() string {
+	if f.Currency == "" {
+		return DefaultCurrency
+	}
+	return f.Currency
+}
+
+// @description Filter and grouping for SubscriptionSumGroupList result.
+type SubscriptionSumGroupFilter struct {
+	SubscriptionSumFilter
+	// field to group by
+	GroupBy string `json:"group_by"`
+	// max number of groups (0 - without limit)
+	Limit int `json:"limit,omitempty"`
 }
 
 // @description Sum of subs prices filtered by Filter.
@@ -59,5 +647,57 @@ type SubscriptionSum struct {
 	// filter fields
 	Filter *SubscriptionSumFilter `json:"filter,omitempty"`
 	// result
-	Sum int `json:"sum"`
+	Sum Money `json:"sum"`
+	// exchange rates used to convert subs prices
+	Rates []SubscriptionSumRate `json:"rates"`
+}
+
+// @description Exchange rate used to convert subs prices charged in the month.
+type SubscriptionSumRate struct {
+	// subs price currency
+	From string `json:"from"`
+	// result currency
+	To string `json:"to"`
+	// charge month (first day of month)
+	Month time.Time `json:"month"`
+	// amount of result currency for 1 unit of subs price currency
+	Rate float64 `json:"rate"`
+}
+
+// @description Subs costs of one service for one month.
+type SubscriptionServiceSum struct {
+	// service name
+	ServiceName string `json:"service_name"`
+	// total charged
+	Sum Money `json:"sum"`
+	// number of active subs
+	Count int `json:"count"`
 }
+
+// @description Subs costs for one month with per-service breakdown.
+type SubscriptionMonthlySum struct {
+	// month (first day of month)
+	Month time.Time `json:"month"`
+	// total charged
+	Sum Money `json:"sum"`
+	// number of active subs
+	Count int `json:"count"`
+	// per-service breakdown
+	Services []SubscriptionServiceSum `json:"services"`
+}
+
+// Subs costs time series with one item per month.
+type SubscriptionMonthlySumList []SubscriptionMonthlySum
+
+// @description Subs costs of one group.
+type SubscriptionSumGroup struct {
+	// value of the group field
+	Key string `json:"key"`
+	// total charged
+	Sum Money `json:"sum"`
+	// number of subs in the group
+	Count int `json:"count"`
+}
+
+// Subs costs groups sorted by sum.
+type SubscriptionSumGroupList []SubscriptionSumGroup
@@ -0,0 +1,228 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// rub returns RUB money with the given amount in kopecks.
+func rub(amount int64) Money {
+	return Money{Amount: amount, Currency: "RUB"}
+}
+
+func TestSubscription_MonthlyEquivalent(t *testing.T) {
+	t.Log("Normalize subs price to one month")
+
+	tests := []struct {
+		name     string
+		subs     Subscription
+		expected int64
+	}{
+		{
+			name:     "monthly",
+			subs:     Subscription{Price: rub(40000), BillingPeriod: BillingMonthly, BillingInterval: 1},
+			expected: 40000,
+		},
+		{
+			name:     "every two months",
+			subs:     Subscription{Price: rub(40000), BillingPeriod: BillingMonthly, BillingInterval: 2},
+			expected: 20000,
+		},
+		{
+			name:     "quarterly",
+			subs:     Subscription{Price: rub(90000), BillingPeriod: BillingQuarterly, BillingInterval: 1},
+			expected: 30000,
+		},
+		{
+			name:     "yearly",
+			subs:     Subscription{Price: rub(199000), BillingPeriod: BillingYearly, BillingInterval: 1},
+			expected: 16583,
+		},
+		{
+			name:     "weekly",
+			subs:     Subscription{Price: rub(12000), BillingPeriod: BillingWeekly, BillingInterval: 1},
+			expected: 52000,
+		},
+		{
+			name:     "unknown period",
+			subs:     Subscription{Price: rub(40000)},
+			expected: 40000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.subs.MonthlyEquivalent().Amount)
+		})
+	}
+}
+
+// date returns pointer to the date in UTC.
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestNewSubscriptionSchedule(t *testing.T) {
+	t.Log("Build subs charges schedule with pauses")
+
+	subs := &Subscription{StartDate: date(2025, 1, 1), EndDate: date(2025, 12, 1),
+		BillingPeriod: BillingMonthly, BillingInterval: 2}
+	pauses := SubscriptionPauseList{
+		{StartDate: date(2025, 3, 1), EndDate: date(2025, 4, 1)},
+		{StartDate: date(2025, 9, 1)},
+	}
+
+	schedule, ok := NewSubscriptionSchedule(subs, pauses)
+	require.True(t, ok)
+	// pause without end stops charges before its month
+	require.Equal(t, date(2025, 8, 31), schedule.Until)
+	require.Equal(t, []time.Time{*date(2025, 3, 1)}, schedule.Skipped)
+
+	// subs is paused from its start
+	pauses[1].StartDate = date(2025, 1, 1)
+	_, ok = NewSubscriptionSchedule(subs, pauses[1:])
+	require.False(t, ok)
+
+	// weekly subs without end and pauses
+	subs = &Subscription{StartDate: date(2025, 1, 1), BillingPeriod: BillingWeekly,
+		BillingInterval: 1}
+	schedule, ok = NewSubscriptionSchedule(subs, nil)
+	require.True(t, ok)
+	require.Nil(t, schedule.Until)
+	require.Empty(t, schedule.Skipped)
+	chargeDate, _ := subs.ChargeDate(5)
+	require.Equal(t, *date(2025, 2, 5), chargeDate)
+}
+
+func TestSubscription_ChargeDate(t *testing.T) {
+	t.Log("Charge subs on the billing day or the last day of the shorter month")
+
+	tests := []struct {
+		name     string
+		subs     Subscription
+		expected []time.Time
+	}{
+		{
+			name: "monthly on the 31st",
+			subs: Subscription{StartDate: date(2025, 12, 31), BillingDay: 31,
+				BillingPeriod: BillingMonthly, BillingInterval: 1},
+			expected: []time.Time{*date(2025, 12, 31), *date(2026, 1, 31), *date(2026, 2, 28),
+				*date(2026, 3, 31), *date(2026, 4, 30)},
+		},
+		{
+			name: "started on the last day of February with billing day",
+			subs: Subscription{StartDate: date(2027, 2, 28), BillingDay: 30,
+				BillingPeriod: BillingMonthly, BillingInterval: 1},
+			expected: []time.Time{*date(2027, 2, 28), *date(2027, 3, 30), *date(2027, 4, 30),
+				*date(2027, 5, 30), *date(2027, 6, 30)},
+		},
+		{
+			name: "billing day before start day",
+			subs: Subscription{StartDate: date(2025, 7, 17), BillingDay: 1,
+				BillingPeriod: BillingQuarterly, BillingInterval: 1},
+			expected: []time.Time{*date(2025, 7, 17), *date(2025, 10, 1), *date(2026, 1, 1),
+				*date(2026, 4, 1), *date(2026, 7, 1)},
+		},
+		{
+			name: "yearly from leap day without billing day",
+			subs: Subscription{StartDate: date(2028, 2, 29),
+				BillingPeriod: BillingYearly, BillingInterval: 1},
+			expected: []time.Time{*date(2028, 2, 29), *date(2029, 2, 28), *date(2030, 2, 28),
+				*date(2031, 2, 28), *date(2032, 2, 29)},
+		},
+		{
+			name: "weekly ignores billing day",
+			subs: Subscription{StartDate: date(2025, 7, 17), BillingDay: 1,
+				BillingPeriod: BillingWeekly, BillingInterval: 2},
+			expected: []time.Time{*date(2025, 7, 17), *date(2025, 7, 31), *date(2025, 8, 14),
+				*date(2025, 8, 28), *date(2025, 9, 11)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := make([]time.Time, len(tt.expected))
+			for n := range charges {
+				chargeDate, ok := tt.subs.ChargeDate(n)
+				require.True(t, ok)
+				charges[n] = chargeDate
+			}
+			require.Equal(t, tt.expected, charges)
+		})
+	}
+}
+
+func TestSubscriptionSchedule_DayPrecision(t *testing.T) {
+	t.Log("Charge subs until its end day and validate it by the last day")
+
+	precision := PrecisionDay
+	subs := &Subscription{ServiceName: "Ivi", Price: rub(19900),
+		StartDate: date(2025, 1, 31), EndDate: date(2025, 4, 29), EndDatePrecision: &precision,
+		BillingPeriod: BillingMonthly, BillingInterval: 1}
+	subs.SetDefaults()
+	require.Equal(t, 31, subs.BillingDay)
+	require.Equal(t, PrecisionMonth, subs.StartDatePrecision)
+
+	schedule, ok := NewSubscriptionSchedule(subs, nil)
+	require.True(t, ok)
+	require.Equal(t, date(2025, 4, 29), schedule.Until)
+	require.Equal(t, []time.Time{*date(2025, 2, 28), *date(2025, 3, 31)},
+		schedule.Charges(*date(2025, 2, 1), *date(2025, 12, 31)))
+	chargeDate, ok := schedule.NextCharge(*date(2025, 3, 1))
+	require.True(t, ok)
+	require.Equal(t, *date(2025, 3, 31), chargeDate)
+
+	// end month of month precision is included as a whole
+	subs.EndDate, subs.EndDatePrecision = date(2025, 1, 1), nil
+	require.NoError(t, subs.Validate())
+	lastDay, ok := subs.LastDay()
+	require.True(t, ok)
+	require.Equal(t, *date(2025, 1, 31), lastDay)
+	// end day before start day
+	subs.EndDate, subs.EndDatePrecision = date(2025, 1, 30), &precision
+	require.Error(t, subs.Validate())
+}
+
+func TestSubscription_DeletedAtJSON(t *testing.T) {
+	t.Log("Write deletion time of deleted subs only")
+
+	subs := Subscription{ID: "id"}
+	data, err := json.Marshal(subs)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "deleted_at")
+
+	deletedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	subs.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	data, err = json.Marshal(subs)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"deleted_at":"2025-03-01T12:00:00Z"`)
+}
+
+func TestSubsAuditStates(t *testing.T) {
+	t.Log("Collect changed subs fields for audit")
+
+	before := &Subscription{ID: "subs", ServiceName: "Yandex Plus", Price: rub(40000),
+		BillingPeriod: BillingMonthly, BillingInterval: 1}
+	after := *before
+	after.Price = rub(45000)
+
+	beforeJSON, afterJSON, changes, err := SubsAuditStates(before, &after)
+	require.NoError(t, err)
+	require.Contains(t, string(beforeJSON), `"amount":"400.00"`)
+	require.Contains(t, string(afterJSON), `"amount":"450.00"`)
+	require.JSONEq(t, `{"price": {
+		"old": {"amount": "400.00", "currency": "RUB"},
+		"new": {"amount": "450.00", "currency": "RUB"}
+	}}`, string(changes))
+
+	// all fields are changed on delete
+	_, afterJSON, changes, err = SubsAuditStates(before, nil)
+	require.NoError(t, err)
+	require.Nil(t, afterJSON)
+	require.Contains(t, string(changes), `"service_name":{"old":"Yandex Plus","new":null}`)
+}
@@ -0,0 +1,123 @@
+package entity
+
+import "time"
+
+// Type of subs lifecycle event.
+type EventType string
+
+// Available event types.
+const (
+	EventSubsCreated  EventType = "subs.created"
+	EventSubsUpdated  EventType = "subs.updated"
+	EventSubsDeleted  EventType = "subs.deleted"
+	EventSubsRestored EventType = "subs.restored"
+	EventSubsEnded    EventType = "subs.ended"
+	EventSubsPaused   EventType = "subs.paused"
+	EventSubsResumed  EventType = "subs.resumed"
+)
+
+// @description Subs lifecycle event sent to webhooks.
+type Event struct {
+	// event uuid
+	ID string `json:"id"`
+	// event type
+	Type EventType `json:"type"`
+	// time when event occurred
+	OccurredAt time.Time `json:"occurred_at"`
+	// subs state (the last one for deleted subs)
+	Data *Subscription `json:"data"`
+}
+
+// @description Webhook subscribed to subs lifecycle events.
+type Webhook struct {
+	// webhook uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// receiver URL
+	URL string `json:"url" gorm:"url;not null"`
+	// secret to sign payload with HMAC-SHA256
+	Secret string `json:"-" gorm:"secret;not null"`
+	// event types to send
+	EventTypes TextArray `json:"event_types" gorm:"event_types;not null" swaggertype:"array,string"`
+	// events are sent only to active webhooks
+	Active bool `json:"active" gorm:"active;not null"`
+	// creation time
+	CreatedAt time.Time `json:"created_at" gorm:"created_at;not null"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Webhook list.
+type WebhookList []Webhook
+
+// @description Webhook object variant for update it.
+type WebhookUpdate struct {
+	// webhook uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// receiver URL
+	URL *string `json:"url" gorm:"url"`
+	// secret to sign payload with HMAC-SHA256
+	Secret *string `json:"-" gorm:"secret"`
+	// event types to send
+	EventTypes *TextArray `json:"event_types" gorm:"event_types"`
+	// events are sent only to active webhooks
+	Active *bool `json:"active" gorm:"active"`
+}
+
+// Status of webhook delivery.
+type WebhookDeliveryStatus string
+
+// Available webhook delivery statuses.
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	DeliveryDead      WebhookDeliveryStatus = "dead" // all attempts are failed
+)
+
+// @description Delivery of one event to one webhook.
+type WebhookDelivery struct {
+	// delivery uuid
+	ID string `json:"id" gorm:"id;primaryKey;type:uuid"`
+	// webhook uuid
+	WebhookID string `json:"webhook_id" gorm:"webhook_id;not null;type:uuid"`
+	// event uuid
+	EventID string `json:"event_id" gorm:"event_id;not null;type:uuid"`
+	// event type
+	EventType EventType `json:"event_type" gorm:"event_type;not null"`
+	// sent JSON payload (Event)
+	Payload RawJSON `json:"payload" gorm:"payload;type:jsonb;not null" swaggertype:"object"`
+	// delivery status
+	Status WebhookDeliveryStatus `json:"status" gorm:"status;not null"`
+	// number of made attempts
+	Attempts int `json:"attempts" gorm:"attempts;not null"`
+	// time of the next attempt (for pending delivery)
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"next_attempt_at;not null"`
+	// error of the last failed attempt
+	LastError string `json:"last_error,omitempty" gorm:"last_error;not null"`
+	// HTTP status code of the last attempt response
+	LastStatusCode int `json:"last_status_code,omitempty" gorm:"last_status_code;not null"`
+	// creation time
+	CreatedAt time.Time `json:"created_at" gorm:"created_at;not null"`
+	// time of successful delivery
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" gorm:"delivered_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Webhook delivery list.
+type WebhookDeliveryList []WebhookDelivery
+
+// @description Filter and pagination params for WebhookDeliveryList result.
+type WebhookDeliveryFilter struct {
+	// webhook uuid
+	WebhookID string `json:"webhook_id"`
+	// delivery status
+	Status WebhookDeliveryStatus `json:"status,omitempty"`
+	// max number of items
+	Limit int `json:"limit,omitempty"`
+	// number of items to skip
+	Offset int `json:"offset,omitempty"`
+}
@@ -3,23 +3,74 @@
 package errors
 
 import (
+	"context"
 	goerrors "errors"
 	"net/http"
+	"strings"
 )
 
 var (
-	ErrValidateData = goerrors.New("validate data")    // HTTP code 400
-	ErrNotFound     = goerrors.New("record not found") // HTTP code 404
+	ErrValidateData   = goerrors.New("validate data")           // HTTP code 400
+	ErrUnauthorized   = goerrors.New("unauthorized")            // HTTP code 401
+	ErrForbidden      = goerrors.New("forbidden")               // HTTP code 403
+	ErrNotFound       = goerrors.New("record not found")        // HTTP code 404
+	ErrConflict       = goerrors.New("conflict")                // HTTP code 409
+	ErrPrecondition   = goerrors.New("precondition failed")     // HTTP code 412
+	ErrNoPrecondition = goerrors.New("precondition required")   // HTTP code 428
+	ErrNoExchangeRate = goerrors.New("exchange rate not found") // HTTP code 422
+	ErrOverflow       = goerrors.New("amount overflow")         // HTTP code 422
+	// HTTP code 422
+	ErrIdempotencyMismatch = goerrors.New("idempotency key is used with another request")
 )
 
+// @description Validation error of one data field.
+type FieldError struct {
+	// field name
+	Field string `json:"field" example:"end_date"`
+	// error message
+	Message string `json:"message" example:"must not be before start_date"`
+}
+
+// ValidationError is ErrValidateData with details about invalid fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	details := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		details = append(details, field.Field+" "+field.Message)
+	}
+	return ErrValidateData.Error() + ": " + strings.Join(details, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidateData
+}
+
 // ErrorCode returns HTTP-code for given error.
 // Given error is compared with the errors declared above.
 func ErrorCode(err error) int {
 	switch {
 	case goerrors.Is(err, ErrValidateData):
 		return http.StatusBadRequest
+	case goerrors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case goerrors.Is(err, ErrForbidden):
+		return http.StatusForbidden
 	case goerrors.Is(err, ErrNotFound):
 		return http.StatusNotFound
+	case goerrors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case goerrors.Is(err, ErrPrecondition):
+		return http.StatusPreconditionFailed
+	case goerrors.Is(err, ErrNoPrecondition):
+		return http.StatusPreconditionRequired
+	case goerrors.Is(err, ErrNoExchangeRate), goerrors.Is(err, ErrOverflow),
+		goerrors.Is(err, ErrIdempotencyMismatch):
+		return http.StatusUnprocessableEntity
+	case goerrors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusInternalServerError
 	}
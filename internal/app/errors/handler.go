@@ -7,7 +7,17 @@ import (
 	fiber "github.com/gofiber/fiber/v2"
 )
 
+// @description Validation error response with invalid fields.
+type ValidationErrorResponse struct {
+	// error message
+	Error string `json:"error" example:"update subs: validate data: end_date must not be before start_date"`
+	// invalid fields
+	Fields []FieldError `json:"fields"`
+}
+
 // CustomErrorHandler is a handler for http server errors.
+// Validation errors with invalid fields are sent as ValidationErrorResponse,
+// other errors are sent as JSON string messages.
 func CustomErrorHandler(ctx *fiber.Ctx, err error) error {
 	msg := err.Error()
 	// get http error code
@@ -19,6 +29,14 @@ func CustomErrorHandler(ctx *fiber.Ctx, err error) error {
 		msg = "resource not found"
 		errStatusCode = 404
 	}
+	// send validation error details
+	var validationErr *ValidationError
+	if goerrors.As(err, &validationErr) {
+		return ctx.Status(errStatusCode).JSON(ValidationErrorResponse{
+			Error:  msg,
+			Fields: validationErr.Fields,
+		})
+	}
 	// send error response
 	return ctx.Status(errStatusCode).JSON(msg)
 }
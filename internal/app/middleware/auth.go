@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/pkg/jwt"
+)
+
+// Authorization header schemes.
+const (
+	_bearerScheme = "Bearer "
+	_apiKeyScheme = "ApiKey "
+)
+
+// APIKeyAuthenticator authenticates user by plaintext API key.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*entity.AuthUser, error)
+}
+
+// Auth is a middleware for authentication by Authorization header.
+// It supports JWT (Bearer scheme) and API keys (ApiKey scheme).
+// JWT subject must be user uuid and its role claim is trusted only for admin and user roles.
+// Authenticated user is put into request user context.
+func Auth(verifier jwt.Verifier, apiKeys APIKeyAuthenticator) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		authHeader := ctx.Get(fiber.HeaderAuthorization)
+
+		var user *entity.AuthUser
+		if token, ok := strings.CutPrefix(authHeader, _bearerScheme); ok && token != "" {
+			claims, err := verifier.Verify(token)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errors.ErrUnauthorized, err.Error())
+			}
+			// subject is used as user uuid in subs queries
+			if !isUUID(claims.Subject) {
+				return fmt.Errorf("%w: token subject is not uuid", errors.ErrUnauthorized)
+			}
+			user = &entity.AuthUser{
+				ID:     claims.Subject,
+				Role:   tokenRole(claims.Role),
+				Scopes: entity.AllScopes,
+			}
+		} else if key, ok := strings.CutPrefix(authHeader, _apiKeyScheme); ok && key != "" {
+			var err error
+			if user, err = apiKeys.Authenticate(ctx.UserContext(), key); err != nil {
+				return err
+			}
+		} else {
+			return fmt.Errorf("%w: missing bearer token or api key", errors.ErrUnauthorized)
+		}
+
+		ctx.SetUserContext(entity.ContextWithAuthUser(ctx.UserContext(), user))
+		return ctx.Next()
+	}
+}
+
+// isUUID returns true if the string is uuid in the canonical form
+// (braced and URN forms are not accepted).
+func isUUID(s string) bool {
+	id, err := uuid.Parse(s)
+	return err == nil && strings.EqualFold(id.String(), s)
+}
+
+// tokenRole returns role of the JWT role claim. Tokens can have only admin and user roles,
+// any other claim (including service role of API keys without owner) means user role.
+func tokenRole(role string) entity.Role {
+	if entity.Role(role) == entity.RoleAdmin {
+		return entity.RoleAdmin
+	}
+	return entity.RoleUser
+}
+
+// RequireScope is a middleware for checking that authenticated user has the given scope.
+func RequireScope(scope string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user, ok := entity.AuthUserFromContext(ctx.UserContext())
+		if !ok {
+			return fmt.Errorf("%w: no authenticated user", errors.ErrUnauthorized)
+		}
+		if !user.HasScope(scope) {
+			return fmt.Errorf("%w: scope %q is required", errors.ErrForbidden, scope)
+		}
+		return ctx.Next()
+	}
+}
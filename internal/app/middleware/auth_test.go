@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/pkg/jwt"
+)
+
+const _testUserID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+// fakeVerifier returns claims of the token by the token itself.
+type fakeVerifier map[string]jwt.Claims
+
+func (v fakeVerifier) Verify(token string) (*jwt.Claims, error) {
+	claims, ok := v[token]
+	if !ok {
+		return nil, jwt.ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// fakeAPIKeys authenticates API keys without owner as service.
+type fakeAPIKeys struct{}
+
+func (fakeAPIKeys) Authenticate(_ context.Context, key string) (*entity.AuthUser, error) {
+	if key != "service-key" {
+		return nil, errors.ErrUnauthorized
+	}
+	return &entity.AuthUser{ID: "key", Role: entity.RoleService, Scopes: entity.AllScopes}, nil
+}
+
+// authUser sends request with the Authorization header to app with Auth middleware
+// and returns response status and role of the authenticated user.
+func authUser(t *testing.T, verifier fakeVerifier, authHeader string) (int, entity.Role) {
+	t.Helper()
+
+	var role entity.Role
+	app := fiber.New(fiber.Config{ErrorHandler: errors.CustomErrorHandler})
+	app.Get("/", Auth(verifier, fakeAPIKeys{}), func(ctx *fiber.Ctx) error {
+		user, _ := entity.AuthUserFromContext(ctx.UserContext())
+		role = user.Role
+		return ctx.SendStatus(fiber.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, authHeader)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp.StatusCode, role
+}
+
+func TestAuth_TokenRole(t *testing.T) {
+	t.Log("Trust only admin and user roles of JWT")
+
+	verifier := fakeVerifier{
+		"admin":   {Subject: _testUserID, Role: "admin"},
+		"user":    {Subject: _testUserID, Role: "user"},
+		"service": {Subject: _testUserID, Role: "service"},
+		"unknown": {Subject: _testUserID, Role: "root"},
+		"empty":   {Subject: _testUserID},
+	}
+	tests := []struct {
+		token    string
+		expected entity.Role
+	}{
+		{token: "admin", expected: entity.RoleAdmin},
+		{token: "user", expected: entity.RoleUser},
+		{token: "service", expected: entity.RoleUser},
+		{token: "unknown", expected: entity.RoleUser},
+		{token: "empty", expected: entity.RoleUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			status, role := authUser(t, verifier, "Bearer "+tt.token)
+			require.Equal(t, http.StatusNoContent, status)
+			require.Equal(t, tt.expected, role)
+		})
+	}
+
+	// service role is given only to API keys without owner
+	status, role := authUser(t, verifier, "ApiKey service-key")
+	require.Equal(t, http.StatusNoContent, status)
+	require.Equal(t, entity.RoleService, role)
+	status, _ = authUser(t, verifier, "Bearer invalid")
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuth_TokenSubject(t *testing.T) {
+	t.Log("Reject JWT with subject which is not user uuid")
+
+	verifier := fakeVerifier{
+		"uuid":     {Subject: _testUserID},
+		"not-uuid": {Subject: "user-1"},
+		"urn":      {Subject: "urn:uuid:" + _testUserID},
+	}
+
+	status, _ := authUser(t, verifier, "Bearer uuid")
+	require.Equal(t, http.StatusNoContent, status)
+	status, _ = authUser(t, verifier, "Bearer not-uuid")
+	require.Equal(t, http.StatusUnauthorized, status)
+	status, _ = authUser(t, verifier, "Bearer urn")
+	require.Equal(t, http.StatusUnauthorized, status)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"SubscriptionAggregator/internal/pkg/disconnect"
+)
+
+// RequestContext is a middleware for setting request user context.
+// User context is derived from the given base context (it is canceled at server shutdown
+// deadline) and it is canceled when the client closes the connection, so running
+// DB queries of the request are canceled too. Fasthttp request context is not used
+// because it is canceled as soon as server shutdown starts.
+func RequestContext(baseCtx context.Context) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userCtx, cancel := context.WithCancel(baseCtx)
+		defer cancel()
+
+		if conn := ctx.Context().Conn(); conn != nil {
+			stopWatch := disconnect.Watch(conn, cancel)
+			defer stopWatch()
+		}
+
+		ctx.SetUserContext(userCtx)
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// Idempotency headers.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+const _maxIdempotencyKeyLen = 255 // max length of idempotency key got from client
+
+// response headers saved with the response to replay them
+var _savedHeaders = []string{fiber.HeaderContentType, fiber.HeaderETag, fiber.HeaderLocation}
+
+// IdempotencyKeys starts and completes requests with idempotency keys.
+type IdempotencyKeys interface {
+	Begin(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, key *entity.IdempotencyKey) error
+	Release(ctx context.Context, key *entity.IdempotencyKey) error
+}
+
+// Idempotency is a middleware for handling request with Idempotency-Key header only once.
+// Successful response (2xx or 3xx status) is saved and it is replayed for the request
+// repeated with the same key. Requests without the header are handled as usual.
+// If the handler fails (error, 4xx or 5xx status) the request doesn't change data,
+// so the key is released and the request can be repeated (also with another body).
+// It must be used after Auth middleware because keys are scoped to the authenticated user.
+func Idempotency(keys IdempotencyKeys) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		keyValue := ctx.Get(HeaderIdempotencyKey)
+		if keyValue == "" {
+			return ctx.Next()
+		}
+		if len(keyValue) > _maxIdempotencyKeyLen {
+			return fmt.Errorf("%w: idempotency key is too long", errors.ErrValidateData)
+		}
+
+		key := &entity.IdempotencyKey{Key: keyValue, RequestHash: requestHash(ctx)}
+		savedKey, err := keys.Begin(ctx.UserContext(), key)
+		if err != nil {
+			return err
+		}
+		if savedKey != nil {
+			return replayResponse(ctx, savedKey)
+		}
+
+		err = ctx.Next()
+		// request context can be already canceled but the key must be released or completed
+		keyCtx := context.WithoutCancel(ctx.UserContext())
+		if err != nil || ctx.Response().StatusCode() >= fiber.StatusBadRequest {
+			if releaseErr := keys.Release(keyCtx, key); releaseErr != nil {
+				logrus.Errorf("Release idempotency key: %v", releaseErr)
+			}
+			return err
+		}
+
+		if err := saveResponse(ctx, key); err != nil {
+			logrus.Errorf("Save idempotent response: %v", err)
+			return nil
+		}
+		if err := keys.Complete(keyCtx, key); err != nil {
+			// request is handled, so its response is sent anyway
+			logrus.Errorf("Complete idempotency key: %v", err)
+		}
+		return nil
+	}
+}
+
+// requestHash returns hash of the request method, path and body.
+func requestHash(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method() + " " + ctx.OriginalURL() + "\n"))
+	hash.Write(ctx.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// saveResponse saves response status, headers and body into the key.
+func saveResponse(ctx *fiber.Ctx, key *entity.IdempotencyKey) error {
+	headers := make(map[string]string, len(_savedHeaders))
+	for _, name := range _savedHeaders {
+		if value := ctx.GetRespHeader(name); value != "" {
+			headers[name] = value
+		}
+	}
+	rawHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("marshal headers: %w", err)
+	}
+
+	key.ResponseStatus = ctx.Response().StatusCode()
+	key.ResponseHeaders = rawHeaders
+	// response body buffer is reused after the request
+	key.ResponseBody = bytes.Clone(ctx.Response().Body())
+	return nil
+}
+
+// replayResponse sends saved response of the key with Idempotent-Replayed header.
+func replayResponse(ctx *fiber.Ctx, key *entity.IdempotencyKey) error {
+	if len(key.ResponseHeaders) != 0 {
+		headers := make(map[string]string)
+		if err := json.Unmarshal(key.ResponseHeaders, &headers); err != nil {
+			return fmt.Errorf("unmarshal saved headers: %w", err)
+		}
+		for name, value := range headers {
+			ctx.Set(name, value)
+		}
+	}
+	ctx.Set(HeaderIdempotentReplayed, "true")
+	return ctx.Status(key.ResponseStatus).Send(key.ResponseBody)
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/app/usecase"
+)
+
+// fakeIdempotencyRepo is in-memory IdempotencyRepoDB.
+type fakeIdempotencyRepo struct {
+	repo.IdempotencyRepoDB
+	mu   sync.Mutex
+	keys map[string]entity.IdempotencyKey
+}
+
+func (r *fakeIdempotencyRepo) Create(_ context.Context, key *entity.IdempotencyKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	savedKey, ok := r.keys[key.Owner+key.Key]
+	if ok && savedKey.ExpiresAt.After(key.CreatedAt) && (savedKey.IsCompleted() ||
+		savedKey.LockedUntil.After(key.CreatedAt) || savedKey.RequestHash != key.RequestHash) {
+		return false, nil
+	}
+	r.keys[key.Owner+key.Key] = *key
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepo) Get(
+	_ context.Context,
+	owner, key string,
+) (*entity.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	savedKey, ok := r.keys[owner+key]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &savedKey, nil
+}
+
+func (r *fakeIdempotencyRepo) Update(_ context.Context, key *entity.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key.Owner+key.Key] = *key
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Delete(_ context.Context, key *entity.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if savedKey := r.keys[key.Owner+key.Key]; !savedKey.IsCompleted() {
+		delete(r.keys, key.Owner+key.Key)
+	}
+	return nil
+}
+
+// expire moves expiration and lock times of all keys to the past.
+func (r *fakeIdempotencyRepo) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, key := range r.keys {
+		key.LockedUntil = key.CreatedAt
+		key.ExpiresAt = key.CreatedAt
+		r.keys[id] = key
+	}
+}
+
+// newTestIdempotencyApp returns app with POST /subs handler behind Idempotency middleware.
+// Handler responds with its calls number (or 400 status for "bad" body)
+// and it waits for the returned channel to be closed if "slow" body is sent.
+func newTestIdempotencyApp() (*fiber.App, *fakeIdempotencyRepo, chan struct{}) {
+	idempotencyRepo := &fakeIdempotencyRepo{keys: make(map[string]entity.IdempotencyKey)}
+	keys := usecase.NewIdempotencyUsecase(idempotencyRepo,
+		usecase.IdempotencyConfig{TTL: time.Hour, Lease: time.Minute})
+	release := make(chan struct{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: errors.CustomErrorHandler})
+	app.Use(func(ctx *fiber.Ctx) error {
+		ctx.SetUserContext(entity.ContextWithAuthUser(ctx.UserContext(),
+			&entity.AuthUser{ID: "user", Role: entity.RoleUser}))
+		return ctx.Next()
+	})
+	calls := 0
+	var mu sync.Mutex
+	app.Post("/subs", Idempotency(keys), func(ctx *fiber.Ctx) error {
+		switch string(ctx.Body()) {
+		case "bad":
+			return errors.ErrValidateData
+		case "slow":
+			<-release
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		ctx.Set(fiber.HeaderETag, `"1"`)
+		return ctx.Status(fiber.StatusCreated).SendString(strings.Repeat("+", calls))
+	})
+	return app, idempotencyRepo, release
+}
+
+// sendIdempotent sends POST /subs request with the body and idempotency key
+// and returns response with read body.
+func sendIdempotent(t *testing.T, app *fiber.App, key, body string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/subs", strings.NewReader(body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp, string(respBody)
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	t.Log("Replay saved response of the request with the same key and body")
+
+	app, _, _ := newTestIdempotencyApp()
+
+	resp, body := sendIdempotent(t, app, "key", "subs")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "+", body)
+	require.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+
+	resp, body = sendIdempotent(t, app, "key", "subs")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "+", body, "handler is called again")
+	require.Equal(t, "true", resp.Header.Get(HeaderIdempotentReplayed))
+	require.Equal(t, `"1"`, resp.Header.Get(fiber.HeaderETag))
+
+	// another key is another request
+	_, body = sendIdempotent(t, app, "other", "subs")
+	require.Equal(t, "++", body)
+}
+
+func TestIdempotency_Mismatch(t *testing.T) {
+	t.Log("Reject the request with the same key but another body")
+
+	app, _, _ := newTestIdempotencyApp()
+
+	resp, _ := sendIdempotent(t, app, "key", "subs")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = sendIdempotent(t, app, "key", "other subs")
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestIdempotency_Concurrent(t *testing.T) {
+	t.Log("Reject the request while the request with the same key is in progress")
+
+	app, idempotencyRepo, release := newTestIdempotencyApp()
+
+	slowReq := httptest.NewRequest(http.MethodPost, "/subs", strings.NewReader("slow"))
+	slowReq.Header.Set(HeaderIdempotencyKey, "key")
+	done := make(chan error)
+	go func() {
+		resp, err := app.Test(slowReq, -1)
+		if err == nil {
+			err = resp.Body.Close()
+		}
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		_, err := idempotencyRepo.Get(context.Background(), "user", "key")
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	resp, _ := sendIdempotent(t, app, "key", "slow")
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	close(release)
+	require.NoError(t, <-done)
+	resp, body := sendIdempotent(t, app, "key", "slow")
+	require.Equal(t, "+", body)
+	require.Equal(t, "true", resp.Header.Get(HeaderIdempotentReplayed))
+}
+
+func TestIdempotency_Expiry(t *testing.T) {
+	t.Log("Handle the request again after its key is expired")
+
+	app, idempotencyRepo, _ := newTestIdempotencyApp()
+
+	_, body := sendIdempotent(t, app, "key", "subs")
+	require.Equal(t, "+", body)
+
+	idempotencyRepo.expire()
+	resp, body := sendIdempotent(t, app, "key", "other subs")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "++", body)
+	require.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+}
+
+func TestIdempotency_ClientError(t *testing.T) {
+	t.Log("Release the key of the request failed with client error")
+
+	app, _, _ := newTestIdempotencyApp()
+
+	resp, _ := sendIdempotent(t, app, "key", "bad")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// the key is not saved, so the request is repeated with fixed body
+	resp, body := sendIdempotent(t, app, "key", "subs")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "+", body)
+}
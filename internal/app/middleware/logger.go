@@ -11,8 +11,8 @@ import (
 
 // JSON-format for logs
 const (
-	_jsonLogFormat = `{"time": "${time}" "level": "info", "status": "${status}", "method": "${method}", "path": "${path}", "latency": "${latency}", "error": "${error}"}` // nolint:lll // output format
-	_textLogFormat = `INFO[${time}] ${status} | ${method} | ${path} | ${latency} | error: ${error}`
+	_jsonLogFormat = `{"time": "${time}" "level": "info", "status": "${status}", "method": "${method}", "path": "${path}", "latency": "${latency}", "request_id": "${respHeader:X-Request-Id}", "error": "${error}"}` // nolint:lll // output format
+	_textLogFormat = `INFO[${time}] ${status} | ${method} | ${path} | ${latency} | ${respHeader:X-Request-Id} | error: ${error}`
 )
 
 // Logger is a middleware for logging all request-response chains.
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"SubscriptionAggregator/internal/app/entity"
+)
+
+const _maxRequestIDLen = 100 // max length of request ID got from client
+
+// RequestID is a middleware for setting request ID into request user context
+// and X-Request-Id response header. Request ID is taken from X-Request-Id request header
+// or it is generated if header is absent.
+// It must be used after Timeout middleware which replaces user context.
+func RequestID() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(fiber.HeaderXRequestID)
+		if requestID == "" || len(requestID) > _maxRequestIDLen {
+			requestID = uuid.NewString()
+		}
+		ctx.Set(fiber.HeaderXRequestID, requestID)
+		ctx.SetUserContext(entity.ContextWithRequestID(ctx.UserContext(), requestID))
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ repo.APIKeysRepoDB = (*apiKeysRepoPG)(nil)
+
+// APIKeysRepoDB implementation.
+type apiKeysRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewAPIKeysRepoDB returns new APIKeysRepoDB instance.
+func NewAPIKeysRepoDB(dbStorage *gorm.DB) repo.APIKeysRepoDB {
+	return &apiKeysRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// Create creates new API key.
+// All necessary fields must be presented.
+func (r *apiKeysRepoPG) Create(ctx context.Context, key *entity.APIKey) error {
+	if err := dbFromContext(ctx, r.dbStorage).Create(key).Error; err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	return nil
+}
+
+// GetList returns all API keys sorted by creation time.
+func (r *apiKeysRepoPG) GetList(ctx context.Context) (entity.APIKeyList, error) {
+	keyList := entity.APIKeyList{}
+
+	err := dbFromContext(ctx, r.dbStorage).Order("created_at, id").Find(&keyList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get list: %w", err)
+	}
+	return keyList, nil
+}
+
+// GetByHash gets API key by given key hash and returns it.
+func (r *apiKeysRepoPG) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+
+	err := dbFromContext(ctx, r.dbStorage).Where("key_hash = ?", keyHash).First(key).Error
+	// if record not found
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get by hash: %w", err)
+	}
+	return key, nil
+}
+
+// Revoke sets revocation time of API key by its ID.
+// Already revoked key keeps its revocation time.
+func (r *apiKeysRepoPG) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	dbQuery := dbFromContext(ctx, r.dbStorage).Model(&entity.APIKey{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", revokedAt))
+	if dbQuery.Error != nil {
+		return fmt.Errorf("revoke: %w", dbQuery.Error)
+	}
+	if dbQuery.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateLastUsed sets last usage time of API key by its ID.
+// Saved time is not changed if it is newer than usedAt minus LastUsedPrecision,
+// so concurrent requests with the key do not rewrite it.
+func (r *apiKeysRepoPG) UpdateLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	err := dbFromContext(ctx, r.dbStorage).Model(&entity.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at <= ?)",
+			id, usedAt.Add(-entity.LastUsedPrecision)).
+		Update("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("update last used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+func TestAPIKeys_Lifecycle(t *testing.T) {
+	t.Log("Create, get, use and revoke API key")
+
+	apiKeysRepo := NewAPIKeysRepoDB(_dbStorage)
+	keyHash := sha256.Sum256([]byte(uuid.NewString()))
+	key := entity.APIKey{
+		ID:        uuid.NewString(),
+		Name:      "test-key",
+		KeyHash:   hex.EncodeToString(keyHash[:]),
+		Scopes:    entity.Scopes{entity.ScopeSubsRead, entity.ScopeSubsSum},
+		CreatedAt: time.Now().UTC(),
+	}
+	require.NoError(t, apiKeysRepo.Create(t.Context(), &key))
+	t.Cleanup(func() {
+		require.NoError(t, _dbStorage.WithContext(context.Background()).
+			Delete(&entity.APIKey{}, "id = ?", key.ID).Error)
+	})
+
+	keyFromDB, err := apiKeysRepo.GetByHash(t.Context(), key.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, key.Scopes, keyFromDB.Scopes)
+	require.Nil(t, keyFromDB.OwnerID)
+
+	usedAt := time.Now().UTC()
+	require.NoError(t, apiKeysRepo.UpdateLastUsed(t.Context(), key.ID, usedAt))
+	// recent last usage time is not rewritten
+	require.NoError(t, apiKeysRepo.UpdateLastUsed(t.Context(), key.ID, usedAt.Add(time.Second)))
+	keyFromDB, err = apiKeysRepo.GetByHash(t.Context(), key.KeyHash)
+	require.NoError(t, err)
+	require.WithinDuration(t, usedAt, *keyFromDB.LastUsedAt, time.Millisecond)
+	require.NoError(t, apiKeysRepo.Revoke(t.Context(), key.ID, usedAt))
+
+	keyList, err := apiKeysRepo.GetList(t.Context())
+	require.NoError(t, err)
+	for _, listKey := range keyList {
+		if listKey.ID == key.ID {
+			require.NotNil(t, listKey.LastUsedAt)
+			require.False(t, listKey.IsActive(time.Now()))
+		}
+	}
+
+	_, err = apiKeysRepo.GetByHash(t.Context(), "unknown")
+	require.ErrorIs(t, err, errors.ErrNotFound)
+	err = apiKeysRepo.Revoke(t.Context(), uuid.NewString(), usedAt)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+}
@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ repo.AuditRepoDB = (*auditRepoPG)(nil)
+
+// AuditRepoDB implementation.
+type auditRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewAuditRepoDB returns new AuditRepoDB instance.
+func NewAuditRepoDB(dbStorage *gorm.DB) repo.AuditRepoDB {
+	return &auditRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// Create creates new audit entry.
+// All necessary fields must be presented.
+func (r *auditRepoPG) Create(ctx context.Context, entry *entity.AuditEntry) error {
+	if err := dbFromContext(ctx, r.dbStorage).Create(entry).Error; err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch creates all audit entries of the list with multi-row inserts.
+func (r *auditRepoPG) CreateBatch(ctx context.Context, entries entity.AuditEntryList) error {
+	err := dbFromContext(ctx, r.dbStorage).CreateInBatches(entries, _insertBatchSize).Error
+	if err != nil {
+		return fmt.Errorf("create batch: %w", err)
+	}
+	return nil
+}
+
+// GetList returns audit entries filtered and paginated by given filter
+// sorted from the newest ones.
+func (r *auditRepoPG) GetList(
+	ctx context.Context,
+	filter *entity.AuditFilter,
+) (entity.AuditEntryList, error) {
+	entryList := entity.AuditEntryList{}
+
+	dbQuery := dbFromContext(ctx, r.dbStorage)
+	// apply filter conditions
+	if filter.SubsID != "" {
+		dbQuery = dbQuery.Where("subs_id = ?", filter.SubsID)
+	}
+	if filter.UserID != "" {
+		dbQuery = dbQuery.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ActorID != "" {
+		dbQuery = dbQuery.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		dbQuery = dbQuery.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		dbQuery = dbQuery.Where("created_at >= ?", filter.From)
+	}
+	if filter.To != nil {
+		dbQuery = dbQuery.Where("created_at < ?", filter.To)
+	}
+
+	err := dbQuery.
+		Order("created_at DESC, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&entryList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get list: %w", err)
+	}
+	return entryList, nil
+}
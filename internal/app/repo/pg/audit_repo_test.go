@@ -0,0 +1,79 @@
+package pg
+
+import (
+	"context"
+	goerrors "errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+)
+
+// newAuditEntry returns audit entry of the subs change with given action.
+func newAuditEntry(subsID string, action entity.AuditAction) entity.AuditEntry {
+	return entity.AuditEntry{
+		ID:        uuid.NewString(),
+		SubsID:    subsID,
+		UserID:    _userUUID,
+		Action:    action,
+		ActorRole: entity.RoleUser,
+		RequestID: uuid.NewString(),
+		Changes:   entity.RawJSON(`{}`),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func TestAudit_GetList(t *testing.T) {
+	t.Log("Get audit entries of subs filtered by action")
+
+	auditRepo := NewAuditRepoDB(_dbStorage)
+	subsID := uuid.NewString()
+	created := newAuditEntry(subsID, entity.AuditCreate)
+	created.After = entity.RawJSON(`{"id":"` + subsID + `"}`)
+	updated := newAuditEntry(subsID, entity.AuditUpdate)
+	updated.CreatedAt = created.CreatedAt.Add(time.Second)
+	require.NoError(t, auditRepo.Create(t.Context(), &created))
+	require.NoError(t, auditRepo.Create(t.Context(), &updated))
+
+	entryList, err := auditRepo.GetList(t.Context(), &entity.AuditFilter{SubsID: subsID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entryList, 2)
+	require.Equal(t, updated.ID, entryList[0].ID)
+	require.Equal(t, created.ID, entryList[1].ID)
+	require.Nil(t, entryList[1].Before)
+	require.JSONEq(t, string(created.After), string(entryList[1].After))
+
+	entryList, err = auditRepo.GetList(t.Context(), &entity.AuditFilter{
+		SubsID: subsID,
+		Action: entity.AuditCreate,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entryList, 1)
+	require.Equal(t, created.ID, entryList[0].ID)
+}
+
+func TestTxManager_WithinTx(t *testing.T) {
+	t.Log("Rollback audit entry on error within transaction")
+
+	txManager := NewTxManager(_dbStorage)
+	auditRepo := NewAuditRepoDB(_dbStorage)
+	entry := newAuditEntry(uuid.NewString(), entity.AuditDelete)
+
+	errRollback := goerrors.New("rollback")
+	err := txManager.WithinTx(t.Context(), func(ctx context.Context) error {
+		require.NoError(t, auditRepo.Create(ctx, &entry))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	entryList, err := auditRepo.GetList(t.Context(), &entity.AuditFilter{
+		SubsID: entry.SubsID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, entryList)
+}
@@ -0,0 +1,59 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ repo.CalendarTokensRepoDB = (*calendarTokensRepoPG)(nil)
+
+// CalendarTokensRepoDB implementation.
+type calendarTokensRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewCalendarTokensRepoDB returns new CalendarTokensRepoDB instance.
+func NewCalendarTokensRepoDB(dbStorage *gorm.DB) repo.CalendarTokensRepoDB {
+	return &calendarTokensRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// GetVersion returns version of the calendar token of the user.
+// Token of the user without saved version has version 0.
+func (r *calendarTokensRepoPG) GetVersion(ctx context.Context, userID string) (int64, error) {
+	var version int64
+	err := dbFromContext(ctx, r.dbStorage).
+		Raw("SELECT version FROM calendar_tokens WHERE user_id = ?", userID).
+		Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("get version: %w", err)
+	}
+	return version, nil
+}
+
+// IncVersion increments version of the calendar token of the user with regeneration time
+// and returns the new version.
+func (r *calendarTokensRepoPG) IncVersion(
+	ctx context.Context,
+	userID string,
+	regeneratedAt time.Time,
+) (int64, error) {
+	var version int64
+	err := dbFromContext(ctx, r.dbStorage).
+		Raw(`INSERT INTO calendar_tokens (user_id, version, regenerated_at) VALUES (?, 1, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+	version = calendar_tokens.version + 1,
+	regenerated_at = EXCLUDED.regenerated_at
+RETURNING version`, userID, regeneratedAt).
+		Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("inc version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,34 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarTokens_Version(t *testing.T) {
+	t.Log("Get and increment version of the user calendar token")
+
+	calendarTokensRepo := NewCalendarTokensRepoDB(_dbStorage)
+	userID := uuid.NewString()
+	t.Cleanup(func() {
+		require.NoError(t, _dbStorage.WithContext(context.Background()).
+			Exec("DELETE FROM calendar_tokens WHERE user_id = ?", userID).Error)
+	})
+
+	version, err := calendarTokensRepo.GetVersion(t.Context(), userID)
+	require.NoError(t, err)
+	require.Zero(t, version)
+
+	for expected := int64(1); expected <= 2; expected++ {
+		version, err = calendarTokensRepo.IncVersion(t.Context(), userID, time.Now().UTC())
+		require.NoError(t, err)
+		require.Equal(t, expected, version)
+	}
+	version, err = calendarTokensRepo.GetVersion(t.Context(), userID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), version)
+}
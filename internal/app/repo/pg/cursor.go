@@ -0,0 +1,75 @@
+package pg
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+const _cursorDateFmt = time.DateOnly // format of date values in cursor
+
+// listCursor is a decoded keyset pagination cursor.
+// It keeps sort params to be sure that cursor is used with the same sort.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// newListCursor returns cursor pointing to the given subs (the last one on the page).
+func newListCursor(sort, order string, subs *entity.Subscription) (string, error) {
+	cursor := listCursor{Sort: sort, Order: order, ID: subs.ID}
+	switch sort {
+	case "service_name":
+		cursor.Value = subs.ServiceName
+	case "price":
+		cursor.Value = strconv.FormatInt(subs.Price.Amount, 10)
+	case "start_date":
+		cursor.Value = subs.StartDate.Format(_cursorDateFmt)
+	case "id":
+		cursor.Value = subs.ID
+	default:
+		return "", fmt.Errorf("unsupported sort field %q", sort)
+	}
+
+	rawCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("marshal cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(rawCursor), nil
+}
+
+// parseListCursor decodes given cursor and checks that it matches given sort params.
+// It returns cursor ID and typed sort value to compare with.
+func parseListCursor(encoded, sort, order string) (value any, id string, err error) {
+	rawCursor, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: decode cursor: %s", errors.ErrValidateData, err.Error())
+	}
+	cursor := listCursor{}
+	if err := json.Unmarshal(rawCursor, &cursor); err != nil {
+		return nil, "", fmt.Errorf("%w: decode cursor: %s", errors.ErrValidateData, err.Error())
+	}
+	if cursor.Sort != sort || cursor.Order != order {
+		return nil, "", fmt.Errorf("%w: cursor does not match sort params", errors.ErrValidateData)
+	}
+
+	switch sort {
+	case "price":
+		value, err = strconv.ParseInt(cursor.Value, 10, 64)
+	case "start_date":
+		value, err = time.Parse(_cursorDateFmt, cursor.Value)
+	default:
+		value = cursor.Value
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: decode cursor value: %s", errors.ErrValidateData, err.Error())
+	}
+	return value, cursor.ID, nil
+}
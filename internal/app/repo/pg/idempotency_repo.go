@@ -0,0 +1,129 @@
+package pg
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ repo.IdempotencyRepoDB = (*idempotencyRepoPG)(nil)
+
+// IdempotencyRepoDB implementation.
+type idempotencyRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewIdempotencyRepoDB returns new IdempotencyRepoDB instance.
+func NewIdempotencyRepoDB(dbStorage *gorm.DB) repo.IdempotencyRepoDB {
+	return &idempotencyRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// Create creates new idempotency key without response. Expired key with the same
+// owner and key is replaced, the key of the same request in progress is taken over
+// after its lock time. It returns false if the key already exists and it is not replaced.
+func (r *idempotencyRepoPG) Create(ctx context.Context, key *entity.IdempotencyKey) (bool, error) {
+	result := dbFromContext(ctx, r.dbStorage).Exec(`INSERT INTO idempotency_keys
+    (owner, key, request_hash, created_at, locked_until, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (owner, key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    response_status = 0,
+    response_headers = NULL,
+    response_body = NULL,
+    created_at = EXCLUDED.created_at,
+    locked_until = EXCLUDED.locked_until,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at OR (
+    idempotency_keys.response_status = 0 AND
+    idempotency_keys.locked_until <= EXCLUDED.created_at AND
+    idempotency_keys.request_hash = EXCLUDED.request_hash
+)`,
+		key.Owner, key.Key, key.RequestHash, key.CreatedAt, key.LockedUntil, key.ExpiresAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("create: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Get gets idempotency key by given owner and key and returns it.
+func (r *idempotencyRepoPG) Get(
+	ctx context.Context,
+	owner, key string,
+) (*entity.IdempotencyKey, error) {
+	idempotencyKey := &entity.IdempotencyKey{}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Where("owner = ? AND key = ?", owner, key).
+		First(idempotencyKey).Error
+	// if record not found
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+	return idempotencyKey, nil
+}
+
+// Update saves response of the request holding idempotency key (the request is found
+// by key creation time). It returns ErrNotFound if the key is taken over by another request.
+func (r *idempotencyRepoPG) Update(ctx context.Context, key *entity.IdempotencyKey) error {
+	result := dbFromContext(ctx, r.dbStorage).
+		Model(&entity.IdempotencyKey{}).
+		Where("owner = ? AND key = ? AND created_at = ?", key.Owner, key.Key, key.CreatedAt).
+		Updates(map[string]any{
+			"response_status":  key.ResponseStatus,
+			"response_headers": key.ResponseHeaders,
+			"response_body":    key.ResponseBody,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("update: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes idempotency key of the request in progress holding it,
+// so the request with the key can be repeated.
+func (r *idempotencyRepoPG) Delete(ctx context.Context, key *entity.IdempotencyKey) error {
+	err := dbFromContext(ctx, r.dbStorage).
+		Where("owner = ? AND key = ? AND created_at = ? AND response_status = 0",
+			key.Owner, key.Key, key.CreatedAt).
+		Delete(&entity.IdempotencyKey{}).Error
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit keys expired before given time.
+// It returns number of deleted keys.
+func (r *idempotencyRepoPG) DeleteExpired(
+	ctx context.Context,
+	expiredBefore time.Time,
+	limit int,
+) (int, error) {
+	result := dbFromContext(ctx, r.dbStorage).Exec(`DELETE FROM idempotency_keys
+WHERE (owner, key) IN (
+    SELECT owner, key FROM idempotency_keys
+    WHERE expires_at < ?
+    ORDER BY expires_at
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)`, expiredBefore, limit)
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete expired: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,84 @@
+package pg
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+func TestIdempotency_Lifecycle(t *testing.T) {
+	t.Log("Create, complete, replace expired, take over locked and delete idempotency key")
+
+	idempotencyRepo := NewIdempotencyRepoDB(_dbStorage)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	key := entity.IdempotencyKey{
+		Owner:       _userUUID,
+		Key:         uuid.NewString(),
+		RequestHash: "hash",
+		CreatedAt:   now,
+		LockedUntil: now.Add(time.Minute),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, _dbStorage.WithContext(context.Background()).
+			Delete(&entity.IdempotencyKey{}, "owner = ? AND key = ?", key.Owner, key.Key).Error)
+	})
+
+	created, err := idempotencyRepo.Create(t.Context(), &key)
+	require.NoError(t, err)
+	require.True(t, created)
+	// the same key is not expired yet
+	created, err = idempotencyRepo.Create(t.Context(), &key)
+	require.NoError(t, err)
+	require.False(t, created)
+
+	key.ResponseStatus = http.StatusCreated
+	key.ResponseHeaders = entity.RawJSON(`{"Content-Type":"application/json"}`)
+	key.ResponseBody = []byte(`{"id":"1"}`)
+	require.NoError(t, idempotencyRepo.Update(t.Context(), &key))
+	// completed key is not deleted
+	require.NoError(t, idempotencyRepo.Delete(t.Context(), &key))
+	keyFromDB, err := idempotencyRepo.Get(t.Context(), key.Owner, key.Key)
+	require.NoError(t, err)
+	require.True(t, keyFromDB.IsCompleted())
+	require.Equal(t, key.ResponseBody, keyFromDB.ResponseBody)
+
+	// expired key is replaced
+	deleted, err := idempotencyRepo.DeleteExpired(t.Context(), now, 1000)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	key.CreatedAt, key.ExpiresAt = now.Add(2*time.Hour), now.Add(3*time.Hour)
+	key.LockedUntil = key.CreatedAt.Add(time.Minute)
+	created, err = idempotencyRepo.Create(t.Context(), &key)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// key of the request in progress is taken over by the same request after lock time
+	newKey := key
+	newKey.CreatedAt = key.LockedUntil
+	newKey.LockedUntil = newKey.CreatedAt.Add(time.Minute)
+	newKey.RequestHash = "other"
+	created, err = idempotencyRepo.Create(t.Context(), &newKey)
+	require.NoError(t, err)
+	require.False(t, created)
+	newKey.RequestHash = key.RequestHash
+	created, err = idempotencyRepo.Create(t.Context(), &newKey)
+	require.NoError(t, err)
+	require.True(t, created)
+	// stale request cannot complete or release the key
+	require.ErrorIs(t, idempotencyRepo.Update(t.Context(), &key), errors.ErrNotFound)
+	require.NoError(t, idempotencyRepo.Delete(t.Context(), &key))
+	_, err = idempotencyRepo.Get(t.Context(), key.Owner, key.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, idempotencyRepo.Delete(t.Context(), &newKey))
+	_, err = idempotencyRepo.Get(t.Context(), key.Owner, key.Key)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+}
@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+// Query to claim due pending messages: their next attempt time is moved forward by lease
+// so other relays skip them while they are published. Messages are returned in creation order.
+const _claimPendingQuery = `WITH claimed AS (
+    UPDATE outbox AS o
+    SET next_attempt_at = now() + ?::interval
+    WHERE o.id IN (
+        SELECT id FROM outbox
+        WHERE status = 'pending' AND next_attempt_at <= now()
+        ORDER BY next_attempt_at, created_at, id
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING o.*
+)
+SELECT * FROM claimed ORDER BY created_at, id`
+
+var _ repo.OutboxRepoDB = (*outboxRepoPG)(nil)
+
+// OutboxRepoDB implementation.
+type outboxRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewOutboxRepoDB returns new OutboxRepoDB instance.
+func NewOutboxRepoDB(dbStorage *gorm.DB) repo.OutboxRepoDB {
+	return &outboxRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// ClaimPending returns up to limit pending messages which next attempt time has come
+// in creation order. Claimed messages are not returned again until lease is expired,
+// so messages are published without holding DB locks and transaction.
+func (r *outboxRepoPG) ClaimPending(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]entity.OutboxMessage, error) {
+	messages := []entity.OutboxMessage{}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Raw(_claimPendingQuery, lease.String(), limit).
+		Scan(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("claim pending: %w", err)
+	}
+	return messages, nil
+}
+
+// UpdateMessage saves relay attempt result fields of the message.
+func (r *outboxRepoPG) UpdateMessage(ctx context.Context, msg *entity.OutboxMessage) error {
+	err := dbFromContext(ctx, r.dbStorage).Model(msg).
+		Select("status", "attempts", "next_attempt_at", "last_error",
+			"published_to", "sent_at").
+		Updates(msg).Error
+	if err != nil {
+		return fmt.Errorf("update message: %w", err)
+	}
+	return nil
+}
+
+// createSubsEvent saves event of the given type with subs state into outbox
+// using given DB transaction.
+func createSubsEvent(tx *gorm.DB, eventType entity.EventType, subs *entity.Subscription) error {
+	msg, err := newSubsEventMessage(eventType, subs)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(msg).Error; err != nil {
+		return fmt.Errorf("create %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// createSubsEvents saves events of the given type with states of all subs into outbox
+// with multi-row inserts using given DB transaction.
+func createSubsEvents(
+	tx *gorm.DB,
+	eventType entity.EventType,
+	subsList entity.SubscriptionList,
+) error {
+	msgs := make([]*entity.OutboxMessage, 0, len(subsList))
+	for i := range subsList {
+		msg, err := newSubsEventMessage(eventType, &subsList[i])
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := tx.CreateInBatches(msgs, _insertBatchSize).Error; err != nil {
+		return fmt.Errorf("create %s events: %w", eventType, err)
+	}
+	return nil
+}
+
+// newSubsEventMessage returns outbox message with event of the given type with subs state.
+func newSubsEventMessage(
+	eventType entity.EventType,
+	subs *entity.Subscription,
+) (*entity.OutboxMessage, error) {
+	event := entity.Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       subs,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+
+	return &entity.OutboxMessage{
+		ID:            event.ID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        entity.OutboxPending,
+		NextAttemptAt: event.OccurredAt,
+		CreatedAt:     event.OccurredAt,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package pg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+)
+
+func TestOutbox_ClaimPending(t *testing.T) {
+	t.Log("Claim pending subs events from outbox and save relay results")
+
+	outboxRepo := NewOutboxRepoDB(_dbStorage)
+	startDate := time.Now().UTC()
+	subs := entity.Subscription{
+		ID:          uuid.NewString(),
+		ServiceName: "Outbox test",
+		Price:       rub(40000),
+		UserID:      _userUUID,
+		StartDate:   &startDate,
+	}
+	require.NoError(t, _repo.Create(t.Context(), &subs))
+	require.NoError(t, _repo.Delete(t.Context(), subs.ID))
+
+	// claim returns messages of the subs in creation order
+	claim := func(lease time.Duration) []entity.OutboxMessage {
+		messages, err := outboxRepo.ClaimPending(t.Context(), 1000, lease)
+		require.NoError(t, err)
+		subsMessages := []entity.OutboxMessage{}
+		for _, msg := range messages {
+			event := entity.Event{}
+			require.NoError(t, json.Unmarshal(msg.Payload, &event))
+			if event.Data.ID == subs.ID {
+				subsMessages = append(subsMessages, msg)
+			}
+		}
+		return subsMessages
+	}
+
+	messages := claim(-time.Second) // expired lease to claim messages again
+	require.Len(t, messages, 2)
+	require.Equal(t, entity.EventSubsCreated, messages[0].EventType)
+	require.Equal(t, entity.EventSubsDeleted, messages[1].EventType)
+	require.Equal(t, entity.OutboxPending, messages[0].Status)
+	require.Empty(t, messages[0].PublishedTo)
+
+	// the first message is failed for one of publishers, the second one is sent
+	sentAt := time.Now().UTC()
+	messages[0].Attempts = 1
+	messages[0].LastError = "nats: publisher is unavailable"
+	messages[0].PublishedTo = entity.TextArray{"webhooks"}
+	messages[1].Status = entity.OutboxSent
+	messages[1].PublishedTo = entity.TextArray{"webhooks", "nats"}
+	messages[1].SentAt = &sentAt
+	for i := range messages {
+		require.NoError(t, outboxRepo.UpdateMessage(t.Context(), &messages[i]))
+	}
+
+	// only failed message is claimed again, then its lease is not expired
+	retried := claim(time.Hour)
+	require.Len(t, retried, 1)
+	require.Equal(t, messages[0].ID, retried[0].ID)
+	require.Equal(t, 1, retried[0].Attempts)
+	require.Equal(t, "nats: publisher is unavailable", retried[0].LastError)
+	require.Equal(t, entity.TextArray{"webhooks"}, retried[0].PublishedTo)
+	require.Empty(t, claim(time.Hour))
+
+	sentMsg := entity.OutboxMessage{}
+	require.NoError(t, _dbStorage.Where("id = ?", messages[1].ID).First(&sentMsg).Error)
+	require.Equal(t, entity.OutboxSent, sentMsg.Status)
+	require.NotNil(t, sentMsg.SentAt)
+}
@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+const _ratesBatchSize = 1000 // max number of rates inserted with one query
+
+var _ repo.ExchangeRatesRepoDB = (*ratesRepoPG)(nil)
+
+// ExchangeRatesRepoDB implementation.
+type ratesRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewExchangeRatesRepoDB returns new ExchangeRatesRepoDB instance.
+func NewExchangeRatesRepoDB(dbStorage *gorm.DB) repo.ExchangeRatesRepoDB {
+	return &ratesRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// Save saves given rates in one transaction.
+// Existing rates for the same currency and date are replaced.
+func (r *ratesRepoPG) Save(ctx context.Context, rates []entity.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := dbFromContext(ctx, r.dbStorage).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "currency"}, {Name: "rate_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+		}).
+		CreateInBatches(rates, _ratesBatchSize).Error
+	if err != nil {
+		return fmt.Errorf("save rates: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+)
+
+// SQL-parts of subs values computed for export on the current date.
+const (
+	// number of months from start month to end month (or current month if it is earlier)
+	// without paused months
+	_exportMonthsActive = "(SELECT COUNT(*) FROM generate_series(" +
+		"date_trunc('month', subs.start_date), " +
+		"date_trunc('month', LEAST(COALESCE(subs.end_date, CURRENT_DATE), CURRENT_DATE)), " +
+		"INTERVAL '1 month') AS active(month) " +
+		"WHERE NOT EXISTS (SELECT 1 FROM subs_pauses AS pause WHERE pause.subs_id = subs.id " +
+		"AND active.month >= pause.start_date " +
+		"AND (pause.end_date IS NULL OR active.month <= pause.end_date))) AS months_active"
+	// subs price multiplied by the number of its charges until the current date
+	// (multiplication fails on overflow instead of wrapping)
+	_exportTotalPaid = "(subs.price * (SELECT COUNT(*) " +
+		"FROM subs_charge_dates(subs, NULL::date, CURRENT_DATE))) AS total_paid"
+)
+
+// exportRow is a subs row with values computed for export.
+type exportRow struct {
+	entity.Subscription
+	MonthsActive int   `gorm:"column:months_active"`
+	TotalPaid    int64 `gorm:"column:total_paid"`
+}
+
+// Export calls fn for every subs filtered and sorted by given filter (pagination is ignored)
+// with its values computed for export. Subs are read from DB cursor one by one,
+// so the whole list is not loaded into memory. The first fn error stops export and is returned.
+// Export is limited by the context only, DB statement timeout is disabled for it.
+func (r *subsRepoPG) Export(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+	fn func(subs *entity.SubscriptionExport) error,
+) error {
+	// error of fn is returned as is
+	return dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL statement_timeout = 0").Error; err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		return r.export(context.WithValue(ctx, txCtxKey{}, tx), filter, fn)
+	})
+}
+
+// export calls fn for every subs filtered and sorted by given filter like Export.
+func (r *subsRepoPG) export(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+	fn func(subs *entity.SubscriptionExport) error,
+) error {
+	dbQuery, err := r.listQuery(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	rows, err := dbQuery.
+		Select(strings.Join([]string{"subs.*", _exportMonthsActive, _exportTotalPaid}, ", ")).
+		Order(fmt.Sprintf("%s %s, id %s", filter.Sort, filter.Order, filter.Order)).
+		Rows()
+	if err != nil {
+		return fmt.Errorf("export: %w", sumError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := exportRow{}
+		if err := dbQuery.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		subs := &entity.SubscriptionExport{
+			Subscription: row.Subscription,
+			MonthsActive: row.MonthsActive,
+			TotalPaid:    entity.Money{Amount: row.TotalPaid, Currency: row.Price.Currency},
+		}
+		subs.MonthlyPrice = subs.MonthlyEquivalent()
+		if err := fn(subs); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("export: %w", sumError(err))
+	}
+	return nil
+}
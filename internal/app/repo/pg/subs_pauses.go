@@ -0,0 +1,123 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// CreatePause creates new pause of the subs and saves subs.paused event into outbox.
+// It returns ErrNotFound if subs does not exist and ErrConflict
+// if pause overlaps another pause of the subs.
+func (r *subsRepoPG) CreatePause(ctx context.Context, pause *entity.SubscriptionPause) error {
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		// lock subs to check overlapping with concurrent pauses
+		subs, err := getSubsByID(tx.Clauses(clause.Locking{Strength: "UPDATE"}), pause.SubsID)
+		if err != nil {
+			return err
+		}
+
+		var overlapping int64
+		dbQuery := tx.Model(&entity.SubscriptionPause{}).
+			Where("subs_id = ?", pause.SubsID).
+			Where("end_date IS NULL OR end_date >= ?", pause.StartDate)
+		if pause.EndDate != nil {
+			dbQuery = dbQuery.Where("start_date <= ?", pause.EndDate)
+		}
+		if err := dbQuery.Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping != 0 {
+			return fmt.Errorf("%w: subs is already paused in these months", errors.ErrConflict)
+		}
+		if err := tx.Create(pause).Error; err != nil {
+			return err
+		}
+		return createSubsEvent(tx, entity.EventSubsPaused, subs)
+	})
+	if err != nil {
+		return fmt.Errorf("create pause: %w", err)
+	}
+	return nil
+}
+
+// GetPauses returns all pauses of the subs sorted by start date.
+func (r *subsRepoPG) GetPauses(
+	ctx context.Context,
+	subsID string,
+) (entity.SubscriptionPauseList, error) {
+	pauseList := entity.SubscriptionPauseList{}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Where("subs_id = ?", subsID).
+		Order("start_date").
+		Find(&pauseList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get pauses: %w", err)
+	}
+	return pauseList, nil
+}
+
+// GetPausesList returns all pauses of the given subs sorted by start date.
+func (r *subsRepoPG) GetPausesList(
+	ctx context.Context,
+	subsIDs []string,
+) (entity.SubscriptionPauseList, error) {
+	pauseList := entity.SubscriptionPauseList{}
+	if len(subsIDs) == 0 {
+		return pauseList, nil
+	}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Where("subs_id IN ?", subsIDs).
+		Order("start_date").
+		Find(&pauseList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get pauses list: %w", err)
+	}
+	return pauseList, nil
+}
+
+// Resume resumes the subs from the given month: pauses which start from this month
+// are deleted and pauses which cover it end in the previous month.
+// If any pause is changed subs.resumed event is saved into outbox.
+// It returns number of changed pauses.
+func (r *subsRepoPG) Resume(ctx context.Context, subsID string, month time.Time) (int, error) {
+	var changed int64
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("subs_id = ? AND start_date >= ?", subsID, month).
+			Delete(&entity.SubscriptionPause{})
+		if result.Error != nil {
+			return result.Error
+		}
+		changed += result.RowsAffected
+
+		result = tx.Model(&entity.SubscriptionPause{}).
+			Where("subs_id = ? AND start_date < ?", subsID, month).
+			Where("end_date IS NULL OR end_date >= ?", month).
+			Update("end_date", month.AddDate(0, -1, 0))
+		if result.Error != nil {
+			return result.Error
+		}
+		changed += result.RowsAffected
+		if changed == 0 {
+			return nil
+		}
+
+		subs, err := getSubsByID(tx, subsID)
+		if err != nil {
+			return err
+		}
+		return createSubsEvent(tx, entity.EventSubsResumed, subs)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("resume: %w", err)
+	}
+	return int(changed), nil
+}
@@ -2,18 +2,32 @@
 package pg
 
 import (
+	"context"
 	goerrors "errors"
 	"fmt"
+	"reflect"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"SubscriptionAggregator/internal/app/entity"
 	"SubscriptionAggregator/internal/app/errors"
 	"SubscriptionAggregator/internal/app/repo"
 )
 
+const _insertBatchSize = 500 // max number of rows in one multi-row insert
+
 var _ repo.SubsRepoDB = (*subsRepoPG)(nil)
 
+// Fields allowed to sort subs list by.
+var _listSortFields = map[string]struct{}{
+	"id":           {},
+	"service_name": {},
+	"price":        {},
+	"start_date":   {},
+}
+
 // SubsRepoDB implementation.
 type subsRepoPG struct {
 	dbStorage *gorm.DB
@@ -26,108 +40,442 @@ func NewSubsRepoDB(dbStorage *gorm.DB) repo.SubsRepoDB {
 	}
 }
 
-// Create creates new subscription.
+// Create creates new subscription with subs.created event in outbox.
 // All necessary fields must be presented.
-func (r *subsRepoPG) Create(subs *entity.Subscription) error {
-	if err := r.dbStorage.Create(subs).Error; err != nil {
+func (r *subsRepoPG) Create(ctx context.Context, subs *entity.Subscription) error {
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(subs).Error; err != nil {
+			return err
+		}
+		return createSubsEvent(tx, entity.EventSubsCreated, subs)
+	})
+	if err != nil {
 		return fmt.Errorf("create: %w", err)
 	}
 	return nil
 }
 
+// CreateBatch creates all subs of the list with multi-row inserts.
+// All necessary fields of every subs must be presented.
+func (r *subsRepoPG) CreateBatch(ctx context.Context, subsList entity.SubscriptionList) error {
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(subsList, _insertBatchSize).Error; err != nil {
+			return err
+		}
+		return createSubsEvents(tx, entity.EventSubsCreated, subsList)
+	})
+	if err != nil {
+		return fmt.Errorf("create batch: %w", err)
+	}
+	return nil
+}
+
 // GetByID gets subscription by given ID and returns it.
-func (r *subsRepoPG) GetByID(id string) (*entity.Subscription, error) {
+// Deleted subs is not found unless includeDeleted is true.
+func (r *subsRepoPG) GetByID(
+	ctx context.Context,
+	id string,
+	includeDeleted bool,
+) (*entity.Subscription, error) {
+	dbQuery := dbFromContext(ctx, r.dbStorage)
+	if includeDeleted {
+		dbQuery = dbQuery.Unscoped()
+	}
+	subs, err := getSubsByID(dbQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("get by id: %w", err)
+	}
+	return subs, nil
+}
+
+// LockByID gets not deleted subs by given ID and locks it until the end of the transaction,
+// so it cannot be changed concurrently.
+func (r *subsRepoPG) LockByID(ctx context.Context, id string) (*entity.Subscription, error) {
+	dbQuery := dbFromContext(ctx, r.dbStorage).Clauses(clause.Locking{Strength: "UPDATE"})
+	subs, err := getSubsByID(dbQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("lock by id: %w", err)
+	}
+	return subs, nil
+}
+
+// getSubsByID gets subscription by given ID using given DB session.
+func getSubsByID(db *gorm.DB, id string) (*entity.Subscription, error) {
 	subs := &entity.Subscription{}
 
-	err := r.dbStorage.Where("id = ?", id).First(subs).Error
+	err := db.Where("id = ?", id).First(subs).Error
 	// if record not found
 	if goerrors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.ErrNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("get by id: %w", err)
+		return nil, err
 	}
 	return subs, nil
 }
 
-// Update updates subscription.
-// It selects subs by given ID and replace all old values (from DB) to new (given).
-// It returns full filled updated subs.
-func (r *subsRepoPG) Update(subs *entity.SubscriptionUpdate) (*entity.Subscription, error) {
-	// update subs
-	err := r.dbStorage.Model(&entity.Subscription{}).
-		Where("id = ?", subs.ID).
-		Updates(subs).Error
+// Update updates subscription with subs.updated event in outbox.
+// It updates given (not nil) subs fields and increments subs version in one query
+// returning full filled updated subs.
+// If update version is given subs is updated only if it has this version,
+// otherwise ErrPrecondition is returned.
+func (r *subsRepoPG) Update(
+	ctx context.Context,
+	subs *entity.SubscriptionUpdate,
+) (*entity.Subscription, error) {
+	var updatedSubs entity.SubscriptionList
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		values, err := subsUpdateValues(tx, subs)
+		if err != nil {
+			return err
+		}
+		dbQuery := tx.Model(&updatedSubs).
+			Clauses(clause.Returning{}).
+			Where("id = ?", subs.ID)
+		if subs.Version != nil {
+			dbQuery = dbQuery.Where("version = ?", *subs.Version)
+		}
+		// update subs
+		if err := dbQuery.Updates(values).Error; err != nil {
+			return err
+		}
+		if len(updatedSubs) == 0 {
+			return notUpdatedError(tx, subs.ID)
+		}
+		return createSubsEvent(tx, entity.EventSubsUpdated, &updatedSubs[0])
+	})
 	if err != nil {
 		return nil, fmt.Errorf("update: %w", err)
 	}
+	return &updatedSubs[0], nil
+}
 
-	// get updated subs by ID
-	subsFromDB, err := r.GetByID(subs.ID)
-	if err != nil {
-		return nil, fmt.Errorf("update: %w", err)
+// subsUpdateValues returns values of the given update fields by their columns
+// with version increment and cleared fields.
+func subsUpdateValues(db *gorm.DB, subs *entity.SubscriptionUpdate) (map[string]any, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(subs); err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{"version": gorm.Expr("version + 1")}
+	subsValue := reflect.ValueOf(subs).Elem()
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.PrimaryKey || !field.Updatable {
+			continue
+		}
+		if value, isZero := field.ValueOf(db.Statement.Context, subsValue); !isZero {
+			values[field.DBName] = value
+		}
 	}
-	return subsFromDB, nil
+	if subs.ClearEndDate {
+		values["end_date"] = nil
+		values["end_date_precision"] = nil
+	}
+	return values, nil
 }
 
-// Delete deletes subscription by its ID.
-func (r *subsRepoPG) Delete(id string) error {
-	if err := r.dbStorage.Delete(&entity.Subscription{}, "id = ?", id).Error; err != nil {
+// notUpdatedError returns error of the subs update which has not updated any row:
+// ErrNotFound if subs does not exist or ErrPrecondition if it has another version.
+func notUpdatedError(db *gorm.DB, id string) error {
+	if _, err := getSubsByID(db, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: subs version is changed", errors.ErrPrecondition)
+}
+
+// Delete softly deletes subscription by its ID with subs.deleted event in outbox.
+// Deleted subs is kept until it is purged.
+// Event is not created if subs does not exist or it is already deleted.
+func (r *subsRepoPG) Delete(ctx context.Context, id string) error {
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		var deletedSubs entity.SubscriptionList
+		err := tx.Clauses(clause.Returning{}).
+			Where("id = ?", id).
+			Delete(&deletedSubs).Error
+		if err != nil || len(deletedSubs) == 0 {
+			return err
+		}
+		return createSubsEvent(tx, entity.EventSubsDeleted, &deletedSubs[0])
+	})
+	if err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 	return nil
 }
 
-// GetList gets all subscriptions and returns it.
-func (r *subsRepoPG) GetList() (entity.SubscriptionList, error) {
-	var subsList entity.SubscriptionList
+// Restore restores softly deleted subscription by its ID with subs.restored event in outbox.
+// It returns ErrNotFound if there is no deleted subs with given ID.
+func (r *subsRepoPG) Restore(ctx context.Context, id string) (*entity.Subscription, error) {
+	var restoredSubs entity.SubscriptionList
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Model(&restoredSubs).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		if len(restoredSubs) == 0 {
+			return errors.ErrNotFound
+		}
+		return createSubsEvent(tx, entity.EventSubsRestored, &restoredSubs[0])
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	return &restoredSubs[0], nil
+}
+
+// Purge permanently deletes up to limit subs deleted before the given time
+// and returns number of purged subs.
+func (r *subsRepoPG) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	result := dbFromContext(ctx, r.dbStorage).Exec(`DELETE FROM subs
+WHERE id IN (
+    SELECT id FROM subs
+    WHERE deleted_at < ?
+    ORDER BY deleted_at, id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)`, deletedBefore, limit)
+	if result.Error != nil {
+		return 0, fmt.Errorf("purge: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
 
-	if err := r.dbStorage.Find(&subsList).Error; err != nil {
+// GetList gets subscriptions filtered, sorted and paginated by given filter and returns it.
+// If filter cursor is presented keyset pagination is used, otherwise offset one.
+func (r *subsRepoPG) GetList(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+) (*entity.SubscriptionPage, error) {
+	dbQuery, err := r.listQuery(ctx, filter)
+	if err != nil {
 		return nil, fmt.Errorf("get list: %w", err)
 	}
+	page := &entity.SubscriptionPage{Items: entity.SubscriptionList{}}
+
+	// count all filtered subs
+	if err := dbQuery.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("get list: count: %w", err)
+	}
+
+	// apply pagination
+	if filter.Cursor != "" {
+		cursorValue, cursorID, err := parseListCursor(filter.Cursor, filter.Sort, filter.Order)
+		if err != nil {
+			return nil, fmt.Errorf("get list: %w", err)
+		}
+		compareOp := ">"
+		if filter.Order == "desc" {
+			compareOp = "<"
+		}
+		dbQuery = dbQuery.Where(fmt.Sprintf("(%s, id) %s (?, ?)", filter.Sort, compareOp),
+			cursorValue, cursorID)
+	} else {
+		dbQuery = dbQuery.Offset(filter.Offset)
+	}
+	// apply sort (id is used as a tie-breaker for stable keyset pagination)
+	dbQuery = dbQuery.Order(fmt.Sprintf("%s %s, id %s", filter.Sort, filter.Order, filter.Order)).
+		Limit(filter.Limit)
+
+	if err := dbQuery.Find(&page.Items).Error; err != nil {
+		return nil, fmt.Errorf("get list: %w", err)
+	}
+
+	// create cursor for the next page if current page is full
+	if len(page.Items) != 0 && len(page.Items) == filter.Limit {
+		nextCursor, err := newListCursor(filter.Sort, filter.Order, &page.Items[len(page.Items)-1])
+		if err != nil {
+			return nil, fmt.Errorf("get list: %w", err)
+		}
+		page.NextCursor = nextCursor
+	}
+	return page, nil
+}
+
+// GetUpcoming returns not deleted subs of the user (of all users for empty user ID)
+// which are not ended before the given date sorted by start date.
+func (r *subsRepoPG) GetUpcoming(
+	ctx context.Context,
+	userID string,
+	from time.Time,
+) (entity.SubscriptionList, error) {
+	subsList := entity.SubscriptionList{}
+
+	dbQuery := dbFromContext(ctx, r.dbStorage).
+		Where("end_date IS NULL OR subs_last_day(subs) >= ?::date", from)
+	if userID != "" {
+		dbQuery = dbQuery.Where("user_id = ?", userID)
+	}
+	err := dbQuery.Order("start_date, id").Find(&subsList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get upcoming: %w", err)
+	}
 	return subsList, nil
 }
 
-// GetSum returns sum of subs prices filtered by given filter.
-func (r *subsRepoPG) GetSum(filter *entity.SubscriptionSumFilter) (int, error) {
-	var prices []int
+// chargeRow is a row of the charges query.
+type chargeRow struct {
+	Date        time.Time
+	SubsID      string
+	ServiceName string
+	UserID      string
+	Price       int64
+	Currency    string
+}
+
+// GetCharges returns up to limit charges of not deleted subs of the user
+// (of all users for empty user ID) within the days window [from, to] sorted by date.
+// Charges are billing dates returned by subs_charge_dates DB function.
+func (r *subsRepoPG) GetCharges(
+	ctx context.Context,
+	userID string,
+	from, to time.Time,
+	limit int,
+) (entity.SubscriptionChargeList, error) {
+	var rows []chargeRow
+
+	dbQuery := dbFromContext(ctx, r.dbStorage).Model(&entity.Subscription{}).
+		Joins("CROSS JOIN LATERAL subs_charge_dates(subs, ?::date, ?::date) AS charge_date",
+			from, to).
+		Where("subs.start_date <= ?::date AND "+
+			"(subs.end_date IS NULL OR subs_last_day(subs) >= ?::date)", to, from)
+	if userID != "" {
+		dbQuery = dbQuery.Where("subs.user_id = ?", userID)
+	}
+	err := dbQuery.
+		Select("charge_date AS date, subs.id AS subs_id, subs.service_name, subs.user_id, " +
+			"subs.price, subs.currency").
+		Order("charge_date, subs.service_name, subs.id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("get charges: %w", err)
+	}
+
+	charges := make(entity.SubscriptionChargeList, len(rows))
+	for i, row := range rows {
+		charges[i] = entity.SubscriptionCharge{
+			Date:        row.Date.UTC(),
+			SubsID:      row.SubsID,
+			ServiceName: row.ServiceName,
+			UserID:      row.UserID,
+			Amount:      entity.Money{Amount: row.Price, Currency: row.Currency},
+		}
+	}
+	return charges, nil
+}
 
-	dbQuery := r.dbStorage.Model(&entity.Subscription{})
-	// apply main conditions
+// nextChargeRow is a row of the next charge dates query.
+type nextChargeRow struct {
+	ID             string
+	NextChargeDate *time.Time
+}
+
+// GetNextChargeDates returns the first charge dates not before the given date of the given
+// not deleted subs by their IDs (subs without more charges are absent). Charge dates are
+// returned by subs_next_charge_date DB function like charges of GetCharges.
+func (r *subsRepoPG) GetNextChargeDates(
+	ctx context.Context,
+	ids []string,
+	from time.Time,
+) (map[string]time.Time, error) {
+	chargeDates := make(map[string]time.Time, len(ids))
+	if len(ids) == 0 {
+		return chargeDates, nil
+	}
+	var rows []nextChargeRow
+
+	err := dbFromContext(ctx, r.dbStorage).Model(&entity.Subscription{}).
+		Select("subs.id, subs_next_charge_date(subs, ?::date) AS next_charge_date", from).
+		Where("subs.id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("get next charge dates: %w", err)
+	}
+	for _, row := range rows {
+		if row.NextChargeDate != nil {
+			chargeDates[row.ID] = row.NextChargeDate.UTC()
+		}
+	}
+	return chargeDates, nil
+}
+
+// listQuery returns query for subs filtered by given filter conditions.
+// It checks filter sort params because they are inserted into queries as is.
+func (r *subsRepoPG) listQuery(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+) (*gorm.DB, error) {
+	if _, ok := _listSortFields[filter.Sort]; !ok {
+		return nil, fmt.Errorf("%w: unsupported sort field %q", errors.ErrValidateData, filter.Sort)
+	}
+	if filter.Order != "asc" && filter.Order != "desc" {
+		return nil, fmt.Errorf("%w: unsupported sort order %q", errors.ErrValidateData, filter.Order)
+	}
+
+	dbQuery := dbFromContext(ctx, r.dbStorage).Model(&entity.Subscription{})
+	if filter.IncludeDeleted {
+		dbQuery = dbQuery.Unscoped()
+	}
+	// apply filter conditions
 	if filter.UserID != "" {
 		dbQuery = dbQuery.Where("user_id = ?", filter.UserID)
 	}
 	if filter.ServiceName != "" {
 		dbQuery = dbQuery.Where("service_name = ?", filter.ServiceName)
 	}
-
-	dateCond := r.dbStorage.Model(&entity.Subscription{})
-	// collect date condition
-	if filter.StartDate != nil {
-		dateCond = dateCond.Or("start_date <= ?::date AND end_date IS NULL", filter.StartDate)
+	if filter.ActiveAt != nil {
+		// subs is active at any day of the date month for month precision
+		activeUntil := filter.ActiveAtPrecision.LastDay(*filter.ActiveAt)
+		dbQuery = dbQuery.Where("start_date <= ?::date AND "+
+			"(end_date IS NULL OR subs_last_day(subs) >= ?::date)", activeUntil, filter.ActiveAt)
 	}
-	if filter.EndDate != nil {
-		dateCond = dateCond.Or("end_date = ?::date", filter.EndDate)
+	if filter.PriceMin != nil {
+		dbQuery = dbQuery.Where("price >= ?", *filter.PriceMin)
 	}
-	if filter.StartDate != nil && filter.EndDate != nil {
-		dateCond = dateCond.Or("start_date >= ?::date AND start_date < ?::date",
-			filter.StartDate, filter.EndDate).
-			Or("start_date <= ?::date AND end_date >= ?::date", filter.StartDate, filter.EndDate).
-			Or("end_date >= ?::date AND end_date <= ?::date", filter.StartDate, filter.EndDate)
+	if filter.PriceMax != nil {
+		dbQuery = dbQuery.Where("price <= ?", *filter.PriceMax)
 	}
-	// connect date condition to main conditions
-	dbQuery = dbQuery.Where(dateCond)
+	return dbQuery, nil
+}
 
-	// select prices
-	err := dbQuery.Pluck("price", &prices).Error
-	if err != nil {
-		return 0, fmt.Errorf("get sum: %w", err)
-	}
+// MarkEnded marks up to limit subs ended before the given date as notified
+// about their ending with subs.ended events in outbox and returns them.
+// Marked subs are not returned again.
+func (r *subsRepoPG) MarkEnded(
+	ctx context.Context,
+	endedBefore time.Time,
+	limit int,
+) (entity.SubscriptionList, error) {
+	subsList := entity.SubscriptionList{}
 
-	// sum gotten prices
-	var totalPrice int
-	for _, price := range prices {
-		totalPrice += price
+	err := dbFromContext(ctx, r.dbStorage).Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`UPDATE subs SET ended_notified_at = now()
+WHERE id IN (
+    SELECT id FROM subs
+    WHERE subs_last_day(subs) < ? AND ended_notified_at IS NULL AND deleted_at IS NULL
+    ORDER BY end_date, id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *`, endedBefore, limit).
+			Scan(&subsList).Error
+		if err != nil {
+			return err
+		}
+		for i := range subsList {
+			if err := createSubsEvent(tx, entity.EventSubsEnded, &subsList[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mark ended: %w", err)
 	}
-	return totalPrice, nil
+	return subsList, nil
 }
@@ -1,13 +1,17 @@
 package pg
 
 import (
+	"cmp"
+	"context"
 	"log"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"SubscriptionAggregator/internal/app/entity"
 	"SubscriptionAggregator/internal/app/errors"
@@ -20,7 +24,8 @@ const (
 )
 
 var (
-	_repo repo.SubsRepoDB
+	_dbStorage *gorm.DB
+	_repo      repo.SubsRepoDB
 
 	_subsUUID = uuid.NewString()
 	_userUUID = "44601fee-2bf1-4721-ae6f-7636e79a0cba"
@@ -28,14 +33,15 @@ var (
 
 func TestMain(m *testing.M) {
 	// open DB connection
-	dbStorage, err := database.New(_dbDSN,
+	var err error
+	_dbStorage, err = database.New(_dbDSN,
 		database.WithTranslateError(),
 		database.WithIgnoreNotFound(),
 	)
 	if err != nil {
 		log.Fatalf("get db connection: %v", err)
 	}
-	_repo = NewSubsRepoDB(dbStorage)
+	_repo = NewSubsRepoDB(_dbStorage)
 	// run tests
 	os.Exit(m.Run())
 }
@@ -47,51 +53,126 @@ func TestSubs_Create(t *testing.T) {
 	newSubs := entity.Subscription{
 		ID:          _subsUUID,
 		ServiceName: "Yandex Plus",
-		Price:       400,
+		Price:       rub(40000),
 		UserID:      _userUUID,
 		StartDate:   &startDate,
 	}
 
-	err := _repo.Create(&newSubs)
+	err := _repo.Create(t.Context(), &newSubs)
 	require.NoError(t, err)
 
 	_subsUUID = newSubs.ID
 	t.Logf("New subs: %+v", newSubs)
 }
 
+func TestSubs_CreateBatch(t *testing.T) {
+	t.Log("Create subs batch with one multi-row insert")
+
+	startDate := time.Now().UTC()
+	subsList := entity.SubscriptionList{
+		{ID: uuid.NewString(), ServiceName: "Okko", Price: rub(29900),
+			UserID: _userUUID, StartDate: &startDate},
+		{ID: uuid.NewString(), ServiceName: "Kion", Price: rub(19900),
+			UserID: _userUUID, StartDate: &startDate},
+	}
+	require.NoError(t, _repo.CreateBatch(t.Context(), subsList))
+	t.Cleanup(func() {
+		require.NoError(t, _dbStorage.WithContext(context.Background()).Unscoped().
+			Delete(&entity.Subscription{}, "id IN ?", []string{subsList[0].ID, subsList[1].ID}).Error)
+	})
+
+	for _, subs := range subsList {
+		require.Equal(t, entity.BillingMonthly, subs.BillingPeriod)
+		require.EqualValues(t, 1, subs.Version)
+		subsFromDB, err := _repo.GetByID(t.Context(), subs.ID, false)
+		require.NoError(t, err)
+		require.Equal(t, subs.ServiceName, subsFromDB.ServiceName)
+	}
+}
+
 func TestSubs_GetByID(t *testing.T) {
 	t.Log("Get subs by ID")
 
-	subs, err := _repo.GetByID(_subsUUID)
+	subs, err := _repo.GetByID(t.Context(), _subsUUID, false)
 	require.NoError(t, err)
 
 	t.Logf("Subscription: %+v", subs)
 }
 
 func TestSubs_GetList(t *testing.T) {
-	t.Log("Get all subs")
+	t.Log("Get subs page")
 
-	subsList, err := _repo.GetList()
+	filter := entity.SubscriptionListFilter{
+		Sort:  "start_date",
+		Order: "asc",
+		Limit: 50,
+	}
+
+	subsPage, err := _repo.GetList(t.Context(), &filter)
 	require.NoError(t, err)
 
-	t.Logf("All subs: %v", subsList)
+	t.Logf("Subs page: %+v", subsPage)
+}
+
+func TestSubs_GetListFiltered(t *testing.T) {
+	t.Log("Get subs pages filtered by user using cursor")
+
+	priceMin := int64(10000)
+	filter := entity.SubscriptionListFilter{
+		UserID:   _userUUID,
+		PriceMin: &priceMin,
+		Sort:     "price",
+		Order:    "desc",
+		Limit:    1,
+	}
+
+	firstPage, err := _repo.GetList(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage.Items, 1)
+	require.Equal(t, _subsUUID, firstPage.Items[0].ID)
+	require.NotEmpty(t, firstPage.NextCursor)
+
+	filter.Cursor = firstPage.NextCursor
+	nextPage, err := _repo.GetList(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Empty(t, nextPage.Items)
+	require.Equal(t, firstPage.Total, nextPage.Total)
+
+	t.Logf("Subs pages: %+v, %+v", firstPage, nextPage)
+}
+
+func TestSubs_GetListTimeout(t *testing.T) {
+	t.Log("Get subs page with expired context")
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	filter := entity.SubscriptionListFilter{
+		Sort:  "start_date",
+		Order: "asc",
+		Limit: 50,
+	}
+
+	_, err := _repo.GetList(ctx, &filter)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
 func TestSubs_Update(t *testing.T) {
 	t.Log("Update subs")
 
 	serviceName := "Kinopoisk"
-	price := 350
+	price := int64(35000)
 	startDate := time.Now().UTC()
 	updateValues := entity.SubscriptionUpdate{
 		ID:          _subsUUID,
 		ServiceName: &serviceName,
-		Price:       &price,
+		PriceAmount: &price,
 		UserID:      &_userUUID,
 		StartDate:   &startDate,
 	}
 
-	updatedSubs, err := _repo.Update(&updateValues)
+	updatedSubs, err := _repo.Update(t.Context(), &updateValues)
 	require.NoError(t, err)
 
 	t.Logf("Updated subs: %+v", updatedSubs)
@@ -101,17 +182,17 @@ func TestSubs_UpdateUnexisting(t *testing.T) {
 	t.Log("Try to update unexisting subs")
 
 	serviceName := "Kinopoisk"
-	price := 350
+	price := int64(35000)
 	startDate := time.Now().UTC()
 	updateValues := entity.SubscriptionUpdate{
 		ID:          uuid.NewString(),
 		ServiceName: &serviceName,
-		Price:       &price,
+		PriceAmount: &price,
 		UserID:      &_userUUID,
 		StartDate:   &startDate,
 	}
 
-	_, err := _repo.Update(&updateValues)
+	_, err := _repo.Update(t.Context(), &updateValues)
 	require.Error(t, err)
 	require.ErrorIs(t, err, errors.ErrNotFound)
 
@@ -127,12 +208,33 @@ func TestSubs_UpdateOneField(t *testing.T) {
 		ServiceName: &serviceName,
 	}
 
-	updatedSubs, err := _repo.Update(&updateValues)
+	updatedSubs, err := _repo.Update(t.Context(), &updateValues)
 	require.NoError(t, err)
 
 	t.Logf("Updated subs: %+v", updatedSubs)
 }
 
+func TestSubs_UpdateVersion(t *testing.T) {
+	t.Log("Update subs with expected version")
+
+	subs, err := _repo.GetByID(t.Context(), _subsUUID, false)
+	require.NoError(t, err)
+
+	serviceName := "Ivi"
+	updateValues := entity.SubscriptionUpdate{
+		ID:          _subsUUID,
+		ServiceName: &serviceName,
+		Version:     &subs.Version,
+	}
+	updatedSubs, err := _repo.Update(t.Context(), &updateValues)
+	require.NoError(t, err)
+	require.Equal(t, subs.Version+1, updatedSubs.Version)
+
+	// subs has been already updated with this version
+	_, err = _repo.Update(t.Context(), &updateValues)
+	require.ErrorIs(t, err, errors.ErrPrecondition)
+}
+
 func TestSubs_GetSum(t *testing.T) {
 	t.Log("Get sum of prices")
 
@@ -141,18 +243,243 @@ func TestSubs_GetSum(t *testing.T) {
 		ServiceName: "Ivi",
 	}
 
-	total, err := _repo.GetSum(&subs)
+	subsSum, err := _repo.GetSum(t.Context(), &subs)
 	require.NoError(t, err)
 
-	t.Logf("Total subs prices: %v", total)
-	require.Equal(t, total, 350)
+	t.Logf("Total subs prices: %v", subsSum.Sum)
+	require.Equal(t, rub(35000), subsSum.Sum)
 }
 
 func TestSubs_Delete(t *testing.T) {
 	t.Log("Remove subs by ID")
 
-	err := _repo.Delete(_subsUUID)
+	err := _repo.Delete(t.Context(), _subsUUID)
 	require.NoError(t, err)
 
 	t.Logf("Subs with ID %s was deleted successfully", _subsUUID)
 }
+
+func TestSubs_RestoreAndPurge(t *testing.T) {
+	t.Log("Restore deleted subs then delete and purge it")
+
+	_, err := _repo.GetByID(t.Context(), _subsUUID, false)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+	deletedSubs, err := _repo.GetByID(t.Context(), _subsUUID, true)
+	require.NoError(t, err)
+	require.True(t, deletedSubs.DeletedAt.Valid)
+
+	restoredSubs, err := _repo.Restore(t.Context(), _subsUUID)
+	require.NoError(t, err)
+	require.False(t, restoredSubs.DeletedAt.Valid)
+	_, err = _repo.Restore(t.Context(), _subsUUID)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+
+	require.NoError(t, _repo.Delete(t.Context(), _subsUUID))
+	purged, err := _repo.Purge(t.Context(), time.Now().Add(time.Minute), 1000)
+	require.NoError(t, err)
+	require.Positive(t, purged)
+	_, err = _repo.GetByID(t.Context(), _subsUUID, true)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestSubs_GetUpcoming(t *testing.T) {
+	t.Log("Get not ended subs of the user with their pauses")
+
+	userID := uuid.NewString()
+	subsList := entity.SubscriptionList{
+		{ID: uuid.NewString(), ServiceName: "Ended", Price: rub(10000), UserID: userID,
+			StartDate: month(2024, time.January), EndDate: month(2024, time.June)},
+		{ID: uuid.NewString(), ServiceName: "Endless", Price: rub(10000), UserID: userID,
+			StartDate: month(2024, time.February)},
+	}
+	require.NoError(t, _repo.CreateBatch(t.Context(), subsList))
+	t.Cleanup(func() {
+		for _, subs := range subsList {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+	pause := entity.SubscriptionPause{
+		ID:        uuid.NewString(),
+		SubsID:    subsList[1].ID,
+		StartDate: month(2024, time.March),
+		EndDate:   month(2024, time.April),
+	}
+	require.NoError(t, _repo.CreatePause(t.Context(), &pause))
+
+	upcoming, err := _repo.GetUpcoming(t.Context(), userID, *month(2024, time.July))
+	require.NoError(t, err)
+	require.Len(t, upcoming, 1)
+	require.Equal(t, subsList[1].ID, upcoming[0].ID)
+
+	// ended subs is got within its end month
+	midJune := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	upcoming, err = _repo.GetUpcoming(t.Context(), userID, midJune)
+	require.NoError(t, err)
+	require.Len(t, upcoming, 2)
+
+	pauses, err := _repo.GetPausesList(t.Context(), []string{subsList[0].ID, subsList[1].ID})
+	require.NoError(t, err)
+	require.Len(t, pauses, 1)
+	require.Equal(t, pause.ID, pauses[0].ID)
+}
+
+func TestSubs_GetCharges(t *testing.T) {
+	t.Log("Get charges computed by DB equal to charges of subs schedules")
+
+	userID := uuid.NewString()
+	dayPrecision := entity.PrecisionDay
+	subsList := entity.SubscriptionList{
+		{ServiceName: "Weekly", BillingPeriod: entity.BillingWeekly, BillingInterval: 2,
+			StartDate: day(2024, time.January, 10), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Last day", BillingPeriod: entity.BillingMonthly, BillingDay: 31,
+			StartDate: day(2024, time.January, 31), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Billing day", BillingPeriod: entity.BillingMonthly, BillingDay: 5,
+			StartDate: day(2024, time.January, 20), StartDatePrecision: entity.PrecisionDay,
+			EndDate: day(2024, time.September, 15), EndDatePrecision: &dayPrecision},
+		{ServiceName: "Quarterly", BillingPeriod: entity.BillingQuarterly,
+			StartDate: month(2024, time.February)},
+		{ServiceName: "Leap yearly", BillingPeriod: entity.BillingYearly, BillingDay: 29,
+			StartDate: day(2024, time.February, 29), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Paused", BillingPeriod: entity.BillingMonthly, BillingInterval: 2,
+			StartDate: month(2024, time.January), EndDate: month(2025, time.March)},
+	}
+	for i := range subsList {
+		subsList[i].ID = uuid.NewString()
+		subsList[i].UserID = userID
+		subsList[i].Price = rub(int64(i+1) * 10000)
+		require.NoError(t, _repo.Create(t.Context(), &subsList[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range subsList {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+	pause := entity.SubscriptionPause{
+		ID:        uuid.NewString(),
+		SubsID:    subsList[len(subsList)-1].ID,
+		StartDate: month(2024, time.March),
+		EndDate:   month(2024, time.April),
+	}
+	require.NoError(t, _repo.CreatePause(t.Context(), &pause))
+
+	from, to := *day(2024, time.February, 10), *day(2025, time.March, 31)
+	charges, err := _repo.GetCharges(t.Context(), userID, from, to, 1000)
+	require.NoError(t, err)
+	actual := make([]string, len(charges))
+	for i, charge := range charges {
+		actual[i] = charge.Date.Format(time.DateOnly) + " " + charge.ServiceName
+	}
+
+	// charges of the schedules are computed in Go by the same rules
+	savedList, err := _repo.GetUpcoming(t.Context(), userID, from)
+	require.NoError(t, err)
+	pauses, err := _repo.GetPausesList(t.Context(), []string{pause.SubsID})
+	require.NoError(t, err)
+	var expected []entity.SubscriptionCharge
+	for i := range savedList {
+		schedule, ok := entity.NewSubscriptionSchedule(&savedList[i], pauses)
+		require.True(t, ok)
+		for _, chargeDate := range schedule.Charges(from, to) {
+			expected = append(expected,
+				entity.SubscriptionCharge{Date: chargeDate, ServiceName: schedule.ServiceName})
+		}
+	}
+	slices.SortFunc(expected, func(a, b entity.SubscriptionCharge) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ServiceName, b.ServiceName))
+	})
+	expectedCharges := make([]string, len(expected))
+	for i, charge := range expected {
+		expectedCharges[i] = charge.Date.Format(time.DateOnly) + " " + charge.ServiceName
+	}
+	require.Equal(t, expectedCharges, actual)
+	require.Contains(t, actual, "2024-02-29 Last day")
+	require.NotContains(t, actual, "2024-03-01 Paused")
+
+	// charges are limited from the earliest one
+	limited, err := _repo.GetCharges(t.Context(), userID, from, to, 3)
+	require.NoError(t, err)
+	require.Equal(t, charges[:3], limited)
+}
+
+func TestSubs_GetNextChargeDates(t *testing.T) {
+	t.Log("Get next charge dates computed by DB equal to the first charges of subs")
+
+	userID := uuid.NewString()
+	subsList := entity.SubscriptionList{
+		{ServiceName: "Weekly", BillingPeriod: entity.BillingWeekly, BillingInterval: 2,
+			StartDate: day(2024, time.January, 10), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Last day", BillingPeriod: entity.BillingMonthly, BillingDay: 31,
+			StartDate: day(2024, time.January, 31), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Future", BillingPeriod: entity.BillingMonthly, BillingDay: 5,
+			StartDate: day(2024, time.August, 20), StartDatePrecision: entity.PrecisionDay},
+		{ServiceName: "Ended", BillingPeriod: entity.BillingMonthly,
+			StartDate: month(2024, time.January), EndDate: month(2024, time.May)},
+		{ServiceName: "Paused", BillingPeriod: entity.BillingYearly, BillingInterval: 2,
+			StartDate: month(2023, time.July)},
+		{ServiceName: "Paused until resume", BillingPeriod: entity.BillingMonthly,
+			StartDate: month(2024, time.January)},
+	}
+	for i := range subsList {
+		subsList[i].ID = uuid.NewString()
+		subsList[i].UserID = userID
+		subsList[i].Price = rub(10000)
+		subsList[i].BillingInterval = max(subsList[i].BillingInterval, 1)
+		subsList[i].SetDefaults()
+		require.NoError(t, _repo.Create(t.Context(), &subsList[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range subsList {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+	// the next charge is skipped, so the one after it is in two years
+	pauses := entity.SubscriptionPauseList{{
+		ID:        uuid.NewString(),
+		SubsID:    subsList[4].ID,
+		StartDate: month(2025, time.July),
+		EndDate:   month(2025, time.July),
+	}, {
+		ID:        uuid.NewString(),
+		SubsID:    subsList[5].ID,
+		StartDate: month(2024, time.June),
+	}}
+	for i := range pauses {
+		require.NoError(t, _repo.CreatePause(t.Context(), &pauses[i]))
+	}
+
+	ids := make([]string, len(subsList))
+	for i, subs := range subsList {
+		ids[i] = subs.ID
+	}
+	from := *day(2024, time.June, 10)
+	chargeDates, err := _repo.GetNextChargeDates(t.Context(), ids, from)
+	require.NoError(t, err)
+
+	// the first charges of the same subs computed by DB and in Go
+	charges, err := _repo.GetCharges(t.Context(), userID, from, *day(2030, time.December, 31), 1000)
+	require.NoError(t, err)
+	firstCharges := make(map[string]time.Time)
+	for _, charge := range charges {
+		if _, ok := firstCharges[charge.SubsID]; !ok {
+			firstCharges[charge.SubsID] = charge.Date
+		}
+	}
+	require.Equal(t, firstCharges, chargeDates)
+	for _, subs := range subsList {
+		var subsPauses entity.SubscriptionPauseList
+		for _, pause := range pauses {
+			if pause.SubsID == subs.ID {
+				subsPauses = append(subsPauses, pause)
+			}
+		}
+		schedule, ok := entity.NewSubscriptionSchedule(&subs, subsPauses)
+		require.True(t, ok)
+		chargeDate, ok := schedule.NextCharge(from)
+		savedDate, saved := chargeDates[subs.ID]
+		require.Equal(t, ok, saved, subs.ServiceName)
+		require.Equal(t, chargeDate, savedDate, subs.ServiceName)
+	}
+	require.Equal(t, *day(2027, time.July, 1), chargeDates[subsList[4].ID])
+	require.NotContains(t, chargeDates, subsList[3].ID)
+	require.NotContains(t, chargeDates, subsList[5].ID)
+}
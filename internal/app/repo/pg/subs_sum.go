@@ -0,0 +1,267 @@
+package pg
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// SQL-parts to calculate subs costs within the window.
+// Window is a days interval [win.win_start, win.win_end] (both days are inclusive).
+// If window start is NULL then the whole subs period before window end is used.
+// If window end is not presented then the last day of the current month is used.
+// Subs is charged on its billing dates returned by subs_charge_dates DB function
+// (charges within paused months are skipped) and
+// every charge is converted to the window currency using the rate for the charge month.
+const (
+	// subquery with window bounds and currency
+	_sumWindowJoin = "CROSS JOIN (SELECT ?::date AS win_start, " +
+		"COALESCE(?::date, (date_trunc('month', CURRENT_DATE) " +
+		"+ INTERVAL '1 month' - INTERVAL '1 day')::date) AS win_end, " +
+		"?::char(3) AS currency) AS win"
+	// subs charges within the window given by format args with charged amount in the currency
+	// given by query arg (one row per charge, subs without charges are kept)
+	_sumChargesJoinFmt = "LEFT JOIN LATERAL (SELECT charge_date, " +
+		"ROUND(convert_amount(subs.price, subs.currency, ?, charge_date)) AS amount " +
+		"FROM subs_charge_dates(subs, %s, %s) AS charge_date) AS charge ON true"
+	// total charged in minor units for all joined charges
+	// (cast to bigint fails on overflow instead of wrapping)
+	_sumCharged = "COALESCE(SUM(charge.amount), 0)::bigint"
+	// subs overlaps the window: it starts not after the window end day and its last day
+	// is not before the window start day (such subs is counted even without charges)
+	_sumOverlapCond = "subs.start_date <= win.win_end AND " +
+		"(win.win_start IS NULL OR subs.end_date IS NULL OR subs_last_day(subs) >= win.win_start)"
+)
+
+// PostgreSQL error codes.
+const (
+	_pgNoDataFound       = "P0002" // raised by exchange_rate DB function if rate is not found
+	_pgNumericOutOfRange = "22003" // raised on sum cast to bigint if it overflows
+)
+
+// Fields allowed to group subs sum by.
+var _sumGroupFields = map[string]struct{}{
+	"service_name": {},
+	"user_id":      {},
+}
+
+// sumQuery returns query for subs overlapping the window filtered by given filter
+// joined with their charges within the window.
+// Window bounds and currency are available in the query as win.win_start, win.win_end and
+// win.currency, charges are available as charge.charge_date and charge.amount.
+func (r *subsRepoPG) sumQuery(ctx context.Context, filter *entity.SubscriptionSumFilter) *gorm.DB {
+	currency := filter.TargetCurrency()
+	dbQuery := dbFromContext(ctx, r.dbStorage).Model(&entity.Subscription{}).
+		Joins(_sumWindowJoin, filter.StartDate, filter.LastDay(), currency).
+		Joins(fmt.Sprintf(_sumChargesJoinFmt, "win.win_start", "win.win_end"), currency).
+		Where(_sumOverlapCond)
+	// apply main conditions
+	if filter.UserID != "" {
+		dbQuery = dbQuery.Where("subs.user_id = ?", filter.UserID)
+	}
+	if filter.ServiceName != "" {
+		dbQuery = dbQuery.Where("subs.service_name = ?", filter.ServiceName)
+	}
+	return dbQuery
+}
+
+// sumRateRow is a row of the sum query with charges of one currency within one month.
+// Every row has the total of all charges, row without month is a row of subs without charges.
+type sumRateRow struct {
+	From  string
+	To    string
+	Month *time.Time
+	Rate  *float64
+	Total int64
+}
+
+// GetSum returns total cost of subs filtered by given filter within the window
+// from filter start date to filter end date with exchange rates used to calculate it.
+// Every subs costs its price multiplied by the number of its billing dates within the window.
+// Prices are converted into the filter currency.
+// Total and rates are calculated by one query, so they are always consistent.
+func (r *subsRepoPG) GetSum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumFilter,
+) (*entity.SubscriptionSum, error) {
+	var rows []sumRateRow
+
+	chargeMonth := "date_trunc('month', charge.charge_date)::date"
+	err := r.sumQuery(ctx, filter).
+		Select(fmt.Sprintf("subs.currency AS \"from\", win.currency AS \"to\", %[1]s AS month, "+
+			"CASE WHEN subs.currency <> win.currency AND %[1]s IS NOT NULL "+
+			"THEN exchange_rate(subs.currency, win.currency, %[1]s) END AS rate, "+
+			"COALESCE(SUM(SUM(charge.amount)) OVER (), 0)::bigint AS total", chargeMonth)).
+		Group("subs.currency, win.currency, " + chargeMonth).
+		Order("month, \"from\"").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("get sum: %w", sumError(err))
+	}
+
+	subsSum := &entity.SubscriptionSum{
+		Sum:   entity.Money{Currency: filter.TargetCurrency()},
+		Rates: []entity.SubscriptionSumRate{},
+	}
+	for _, row := range rows {
+		subsSum.Sum.Amount = row.Total
+		if row.Month == nil || row.Rate == nil {
+			continue
+		}
+		subsSum.Rates = append(subsSum.Rates, entity.SubscriptionSumRate{
+			From:  row.From,
+			To:    row.To,
+			Month: *row.Month,
+			Rate:  *row.Rate,
+		})
+	}
+	return subsSum, nil
+}
+
+// groupedSumRow is a row of the grouped sum query.
+type groupedSumRow struct {
+	Key   string
+	Sum   int64
+	Count int
+}
+
+// GetGroupedSum returns total cost of subs filtered by given filter within the window
+// grouped by the filter group field. Groups are sorted by sum from the most expensive.
+// Costs are calculated the same way as in GetSum.
+func (r *subsRepoPG) GetGroupedSum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumGroupFilter,
+) (entity.SubscriptionSumGroupList, error) {
+	// check group field because it is inserted into query as is
+	if _, ok := _sumGroupFields[filter.GroupBy]; !ok {
+		return nil, fmt.Errorf("get grouped sum: %w: unsupported group field %q",
+			errors.ErrValidateData, filter.GroupBy)
+	}
+	var rows []groupedSumRow
+
+	dbQuery := r.sumQuery(ctx, &filter.SubscriptionSumFilter).
+		Select(fmt.Sprintf("subs.%s::text AS key, %s AS sum, COUNT(DISTINCT subs.id) AS count",
+			filter.GroupBy, _sumCharged)).
+		Group("subs." + filter.GroupBy).
+		Order("sum DESC, key")
+	if filter.Limit > 0 {
+		dbQuery = dbQuery.Limit(filter.Limit)
+	}
+
+	if err := dbQuery.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("get grouped sum: %w", sumError(err))
+	}
+
+	currency := filter.TargetCurrency()
+	groupList := make(entity.SubscriptionSumGroupList, 0, len(rows))
+	for _, row := range rows {
+		groupList = append(groupList, entity.SubscriptionSumGroup{
+			Key:   row.Key,
+			Sum:   entity.Money{Amount: row.Sum, Currency: currency},
+			Count: row.Count,
+		})
+	}
+	return groupList, nil
+}
+
+// monthlySumRow is a row of the monthly sum query.
+// Row with IsTotal is a total for the month, other rows are per-service sums.
+type monthlySumRow struct {
+	Month       time.Time
+	ServiceName *string
+	IsTotal     bool
+	Sum         int64
+	Count       int
+}
+
+// GetMonthlySum returns subs costs filtered by given filter for every month
+// from filter start date month to filter end date month (both dates are required).
+// Subs is active from its start month to its end month (inclusive) except paused months
+// and it is charged on its billing dates. Prices are converted into the filter currency.
+func (r *subsRepoPG) GetMonthlySum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumFilter,
+) (entity.SubscriptionMonthlySumList, error) {
+	var rows []monthlySumRow
+
+	// collect subs join condition
+	joinCond := "date_trunc('month', subs.start_date) <= m.month AND " +
+		"(subs.end_date IS NULL OR subs.end_date >= m.month) AND subs.deleted_at IS NULL AND " +
+		"NOT EXISTS (SELECT 1 FROM subs_pauses AS pause WHERE pause.subs_id = subs.id AND " +
+		"pause.start_date <= m.month AND (pause.end_date IS NULL OR pause.end_date >= m.month))"
+	joinArgs := make([]any, 0, 2) // nolint:mnd // max number of filter args
+	if filter.UserID != "" {
+		joinCond += " AND subs.user_id = ?"
+		joinArgs = append(joinArgs, filter.UserID)
+	}
+	if filter.ServiceName != "" {
+		joinCond += " AND subs.service_name = ?"
+		joinArgs = append(joinArgs, filter.ServiceName)
+	}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Table("generate_series(date_trunc('month', ?::date), date_trunc('month', ?::date), "+
+			"interval '1 month') AS m(month)", filter.StartDate, filter.EndDate).
+		Joins("LEFT JOIN subs ON "+joinCond, joinArgs...).
+		Joins(fmt.Sprintf(_sumChargesJoinFmt, "m.month::date",
+			"(m.month + interval '1 month' - interval '1 day')::date"), filter.TargetCurrency()).
+		Select("m.month::date AS month, subs.service_name, " +
+			"GROUPING(subs.service_name) = 1 AS is_total, " +
+			_sumCharged + " AS sum, COUNT(DISTINCT subs.id) AS count").
+		Group("GROUPING SETS ((m.month), (m.month, subs.service_name))").
+		Order("month, is_total DESC, subs.service_name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("get monthly sum: %w", sumError(err))
+	}
+
+	// collect rows into months with per-service breakdown
+	// (rows are sorted by month and the total row goes first)
+	currency := filter.TargetCurrency()
+	monthlySumList := entity.SubscriptionMonthlySumList{}
+	for _, row := range rows {
+		if row.IsTotal {
+			monthlySumList = append(monthlySumList, entity.SubscriptionMonthlySum{
+				Month:    row.Month,
+				Sum:      entity.Money{Amount: row.Sum, Currency: currency},
+				Count:    row.Count,
+				Services: []entity.SubscriptionServiceSum{},
+			})
+			continue
+		}
+		// skip empty month row from the left join
+		if row.ServiceName == nil || len(monthlySumList) == 0 {
+			continue
+		}
+		lastMonth := &monthlySumList[len(monthlySumList)-1]
+		lastMonth.Services = append(lastMonth.Services, entity.SubscriptionServiceSum{
+			ServiceName: *row.ServiceName,
+			Sum:         entity.Money{Amount: row.Sum, Currency: currency},
+			Count:       row.Count,
+		})
+	}
+	return monthlySumList, nil
+}
+
+// sumError converts error of the sum query into app error if it is possible.
+func sumError(err error) error {
+	var pgErr *pgconn.PgError
+	if !goerrors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case _pgNoDataFound:
+		return fmt.Errorf("%w: %s", errors.ErrNoExchangeRate, pgErr.Message)
+	case _pgNumericOutOfRange:
+		return fmt.Errorf("%w: %s", errors.ErrOverflow, pgErr.Message)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,506 @@
+package pg
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// month returns pointer to the first day of the given month.
+func month(year int, m time.Month) *time.Time {
+	date := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+// day returns pointer to the given date.
+func day(year int, m time.Month, d int) *time.Time {
+	date := time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+// monthsTillNow returns number of months from the given month to the current one (inclusive).
+func monthsTillNow(from *time.Time) int {
+	now := time.Now().UTC()
+	return (now.Year()-from.Year())*12 + int(now.Month()-from.Month()) + 1
+}
+
+// rub returns RUB money with the given amount in kopecks.
+func rub(amount int64) entity.Money {
+	return entity.Money{Amount: amount, Currency: "RUB"}
+}
+
+// createSumFixtures creates subs of the new user for sum tests and returns user ID.
+// Created subs are removed on test cleanup.
+func createSumFixtures(t *testing.T) string {
+	t.Helper()
+
+	userID := uuid.NewString()
+	fixtures := []entity.Subscription{
+		// open-ended subs started in Jan 2025
+		{ServiceName: "Open", Price: rub(40000), StartDate: month(2025, time.January)},
+		// subs for the whole 2024 year
+		{ServiceName: "Year2024", Price: rub(10000), StartDate: month(2024, time.January),
+			EndDate: month(2024, time.December)},
+		// one-month subs
+		{ServiceName: "OneMonth", Price: rub(25000), StartDate: month(2024, time.June),
+			EndDate: month(2024, time.June)},
+	}
+	for i := range fixtures {
+		fixtures[i].ID = uuid.NewString()
+		fixtures[i].UserID = userID
+		require.NoError(t, _repo.Create(t.Context(), &fixtures[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range fixtures {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+	return userID
+}
+
+func TestSubs_GetSumMonths(t *testing.T) {
+	t.Log("Get sum of subs costs within different windows")
+
+	userID := createSumFixtures(t)
+
+	tests := []struct {
+		name        string
+		serviceName string
+		start       *time.Time
+		end         *time.Time
+		expected    int64
+	}{
+		{
+			name:        "open-ended subs for the whole year",
+			serviceName: "Open",
+			start:       month(2025, time.January),
+			end:         month(2025, time.December),
+			expected:    40000 * 12,
+		},
+		{
+			name:        "open-ended subs without window is clipped to the current month",
+			serviceName: "Open",
+			expected:    40000 * int64(monthsTillNow(month(2025, time.January))),
+		},
+		{
+			name:        "open-ended subs started before window start",
+			serviceName: "Open",
+			start:       month(2025, time.March),
+			end:         month(2025, time.May),
+			expected:    40000 * 3,
+		},
+		{
+			name:        "window ends before subs start",
+			serviceName: "Open",
+			start:       month(2024, time.January),
+			end:         month(2024, time.December),
+			expected:    0,
+		},
+		{
+			name:        "subs started before window and ended after it with only start date",
+			serviceName: "Year2024",
+			start:       month(2024, time.June),
+			expected:    10000 * 7,
+		},
+		{
+			name:        "subs with only end date",
+			serviceName: "Year2024",
+			end:         month(2024, time.March),
+			expected:    10000 * 3,
+		},
+		{
+			name:        "subs ended inside the window",
+			serviceName: "Year2024",
+			start:       month(2024, time.October),
+			end:         month(2025, time.March),
+			expected:    10000 * 3,
+		},
+		{
+			name:        "window starts after subs end",
+			serviceName: "Year2024",
+			start:       month(2025, time.January),
+			expected:    0,
+		},
+		{
+			name:        "one-month subs inside the window",
+			serviceName: "OneMonth",
+			start:       month(2024, time.January),
+			end:         month(2024, time.December),
+			expected:    25000,
+		},
+		{
+			name:        "one-month window matching subs month",
+			serviceName: "OneMonth",
+			start:       month(2024, time.June),
+			end:         month(2024, time.June),
+			expected:    25000,
+		},
+		{
+			name:     "all user subs within 2024 year",
+			start:    month(2024, time.January),
+			end:      month(2024, time.December),
+			expected: 10000*12 + 25000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := entity.SubscriptionSumFilter{
+				UserID:      userID,
+				ServiceName: tt.serviceName,
+				StartDate:   tt.start,
+				EndDate:     tt.end,
+			}
+
+			subsSum, err := _repo.GetSum(t.Context(), &filter)
+			require.NoError(t, err)
+			require.Equal(t, rub(tt.expected), subsSum.Sum)
+		})
+	}
+}
+
+func TestSubs_GetMonthlySum(t *testing.T) {
+	t.Log("Get subs costs for every month")
+
+	userID := createSumFixtures(t)
+	filter := entity.SubscriptionSumFilter{
+		UserID:    userID,
+		StartDate: month(2024, time.May),
+		EndDate:   month(2025, time.February),
+	}
+
+	monthlySumList, err := _repo.GetMonthlySum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Len(t, monthlySumList, 10)
+
+	// May 2024: only Year2024
+	require.True(t, month(2024, time.May).Equal(monthlySumList[0].Month))
+	require.Equal(t, rub(10000), monthlySumList[0].Sum)
+	require.Equal(t, 1, monthlySumList[0].Count)
+	// June 2024: Year2024 and OneMonth
+	require.Equal(t, rub(35000), monthlySumList[1].Sum)
+	require.Equal(t, 2, monthlySumList[1].Count)
+	require.Equal(t, []entity.SubscriptionServiceSum{
+		{ServiceName: "OneMonth", Sum: rub(25000), Count: 1},
+		{ServiceName: "Year2024", Sum: rub(10000), Count: 1},
+	}, monthlySumList[1].Services)
+	// January 2025: only Open
+	require.Equal(t, rub(40000), monthlySumList[8].Sum)
+	require.Equal(t, "Open", monthlySumList[8].Services[0].ServiceName)
+
+	// total of time series must be equal to the sum within the same window
+	subsSum, err := _repo.GetSum(t.Context(), &filter)
+	require.NoError(t, err)
+	var seriesTotal int64
+	for _, monthlySum := range monthlySumList {
+		seriesTotal += monthlySum.Sum.Amount
+	}
+	require.Equal(t, subsSum.Sum.Amount, seriesTotal)
+}
+
+func TestSubs_GetGroupedSum(t *testing.T) {
+	t.Log("Get subs costs grouped by service")
+
+	userID := createSumFixtures(t)
+	filter := entity.SubscriptionSumGroupFilter{
+		SubscriptionSumFilter: entity.SubscriptionSumFilter{
+			UserID:    userID,
+			StartDate: month(2024, time.January),
+			EndDate:   month(2025, time.June),
+		},
+		GroupBy: "service_name",
+	}
+
+	groupList, err := _repo.GetGroupedSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, entity.SubscriptionSumGroupList{
+		{Key: "Open", Sum: rub(40000 * 6), Count: 1},
+		{Key: "Year2024", Sum: rub(10000 * 12), Count: 1},
+		{Key: "OneMonth", Sum: rub(25000), Count: 1},
+	}, groupList)
+
+	// top-1 by user
+	filter.GroupBy = "user_id"
+	filter.Limit = 1
+	groupList, err = _repo.GetGroupedSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, entity.SubscriptionSumGroupList{
+		{Key: userID, Sum: rub(40000*6 + 10000*12 + 25000), Count: 3},
+	}, groupList)
+}
+
+func TestSubs_GetGroupedSumOverlap(t *testing.T) {
+	t.Log("Get subs costs grouped by service for subs overlapping the days window")
+
+	dayPrecision := entity.PrecisionDay
+	userID := uuid.NewString()
+	fixtures := []entity.Subscription{
+		// ended by day before the window start
+		{ServiceName: "DayEnd", Price: rub(10000), StartDate: month(2025, time.January),
+			EndDate: day(2025, time.March, 5), EndDatePrecision: &dayPrecision},
+		// ended by month including the window without charges within it
+		{ServiceName: "MonthEnd", Price: rub(20000), StartDate: month(2025, time.January),
+			EndDate: month(2025, time.March)},
+		// started after the window end within the same month
+		{ServiceName: "LateStart", Price: rub(30000), StartDate: day(2025, time.March, 20),
+			StartDatePrecision: entity.PrecisionDay, BillingDay: 20},
+		// charged on the billing day within the window
+		{ServiceName: "MidCharge", Price: rub(40000), StartDate: day(2025, time.January, 10),
+			StartDatePrecision: entity.PrecisionDay, BillingDay: 10},
+	}
+	for i := range fixtures {
+		fixtures[i].ID = uuid.NewString()
+		fixtures[i].UserID = userID
+		require.NoError(t, _repo.Create(t.Context(), &fixtures[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range fixtures {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+
+	// subs overlaps the window by its start date day and its last day
+	// (the last day of the end month for month precision)
+	filter := entity.SubscriptionSumGroupFilter{
+		SubscriptionSumFilter: entity.SubscriptionSumFilter{
+			UserID:           userID,
+			StartDate:        day(2025, time.March, 6),
+			EndDate:          day(2025, time.March, 15),
+			EndDatePrecision: entity.PrecisionDay,
+		},
+		GroupBy: "service_name",
+	}
+	groupList, err := _repo.GetGroupedSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, entity.SubscriptionSumGroupList{
+		{Key: "MidCharge", Sum: rub(40000), Count: 1},
+		{Key: "MonthEnd", Sum: rub(0), Count: 1},
+	}, groupList)
+}
+
+func TestSubs_GetSumBillingPeriods(t *testing.T) {
+	t.Log("Get sum of subs costs with different billing periods")
+
+	userID := uuid.NewString()
+	fixtures := []entity.Subscription{
+		{ServiceName: "Yearly", Price: rub(120000), BillingPeriod: entity.BillingYearly,
+			StartDate: month(2024, time.March)},
+		{ServiceName: "Quarterly", Price: rub(30000), BillingPeriod: entity.BillingQuarterly,
+			StartDate: month(2025, time.January), EndDate: month(2025, time.December)},
+		{ServiceName: "Weekly", Price: rub(10000), BillingPeriod: entity.BillingWeekly,
+			StartDate: month(2025, time.January), EndDate: month(2025, time.January)},
+		{ServiceName: "Bimonthly", Price: rub(50000), BillingPeriod: entity.BillingMonthly,
+			BillingInterval: 2, StartDate: month(2025, time.January), EndDate: month(2025, time.June)},
+	}
+	for i := range fixtures {
+		fixtures[i].ID = uuid.NewString()
+		fixtures[i].UserID = userID
+		require.NoError(t, _repo.Create(t.Context(), &fixtures[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range fixtures {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+
+	tests := []struct {
+		name        string
+		serviceName string
+		start       *time.Time
+		end         *time.Time
+		expected    int64
+	}{
+		{
+			name:        "yearly subs charged on start month of every year",
+			serviceName: "Yearly",
+			start:       month(2024, time.January),
+			end:         month(2025, time.December),
+			expected:    120000 * 2,
+		},
+		{
+			name:        "yearly subs without charges within the window",
+			serviceName: "Yearly",
+			start:       month(2024, time.April),
+			end:         month(2025, time.February),
+			expected:    0,
+		},
+		{
+			name:        "quarterly subs for the whole year",
+			serviceName: "Quarterly",
+			start:       month(2025, time.January),
+			end:         month(2025, time.December),
+			expected:    30000 * 4,
+		},
+		{
+			name:        "weekly subs for one month",
+			serviceName: "Weekly",
+			start:       month(2025, time.January),
+			end:         month(2025, time.January),
+			expected:    10000 * 5, // Jan 1, 8, 15, 22, 29
+		},
+		{
+			name:        "monthly subs charged every two months",
+			serviceName: "Bimonthly",
+			start:       month(2025, time.January),
+			end:         month(2025, time.December),
+			expected:    50000 * 3, // Jan, Mar, May
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := entity.SubscriptionSumFilter{
+				UserID:      userID,
+				ServiceName: tt.serviceName,
+				StartDate:   tt.start,
+				EndDate:     tt.end,
+			}
+
+			subsSum, err := _repo.GetSum(t.Context(), &filter)
+			require.NoError(t, err)
+			require.Equal(t, rub(tt.expected), subsSum.Sum)
+		})
+	}
+}
+
+func TestSubs_GetSumCurrency(t *testing.T) {
+	t.Log("Get sum of subs costs converted into another currency")
+
+	rateDate := month(1990, time.January)
+	rates := []entity.ExchangeRate{
+		{Currency: "USD", RateDate: *rateDate, Rate: 1.1},
+		{Currency: "RUB", RateDate: *rateDate, Rate: 100},
+	}
+	require.NoError(t, NewExchangeRatesRepoDB(_dbStorage).Save(t.Context(), rates))
+
+	subs := entity.Subscription{
+		ID:          uuid.NewString(),
+		ServiceName: "Foreign",
+		Price:       entity.Money{Amount: 1000, Currency: "USD"},
+		UserID:      uuid.NewString(),
+		StartDate:   month(1990, time.January),
+		EndDate:     month(1990, time.February),
+	}
+	require.NoError(t, _repo.Create(t.Context(), &subs))
+	t.Cleanup(func() {
+		require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		require.NoError(t, _dbStorage.Delete(&entity.ExchangeRate{}, "rate_date = ?", rateDate).Error)
+	})
+
+	filter := entity.SubscriptionSumFilter{
+		UserID:    subs.UserID,
+		StartDate: month(1990, time.January),
+		EndDate:   month(1990, time.December),
+		Currency:  "RUB",
+	}
+	// 10 USD = 10 * 100 / 1.1 RUB (909.09 RUB) for every of two months
+	subsSum, err := _repo.GetSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, rub(90909*2), subsSum.Sum)
+
+	// rates are used for every of two charge months
+	require.Len(t, subsSum.Rates, 2)
+	require.Equal(t, "USD", subsSum.Rates[0].From)
+	require.Equal(t, "RUB", subsSum.Rates[0].To)
+	require.True(t, month(1990, time.January).Equal(subsSum.Rates[0].Month))
+	require.InDelta(t, 100/1.1, subsSum.Rates[0].Rate, 0.0001)
+
+	// no rates are used without conversion
+	filter.Currency = "USD"
+	subsSum, err = _repo.GetSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, entity.Money{Amount: 2000, Currency: "USD"}, subsSum.Sum)
+	require.Empty(t, subsSum.Rates)
+
+	// there are no rates for JPY
+	filter.Currency = "JPY"
+	_, err = _repo.GetSum(t.Context(), &filter)
+	require.ErrorIs(t, err, errors.ErrNoExchangeRate)
+}
+
+func TestSubs_GetSumOverflow(t *testing.T) {
+	t.Log("Get sum of subs costs which overflows int64")
+
+	userID := uuid.NewString()
+	fixtures := []entity.Subscription{
+		{ServiceName: "Huge", Price: rub(math.MaxInt64), StartDate: month(1991, time.January),
+			EndDate: month(1991, time.February)},
+	}
+	for i := range fixtures {
+		fixtures[i].ID = uuid.NewString()
+		fixtures[i].UserID = userID
+		require.NoError(t, _repo.Create(t.Context(), &fixtures[i]))
+	}
+	t.Cleanup(func() {
+		for _, subs := range fixtures {
+			require.NoError(t, _repo.Delete(context.Background(), subs.ID))
+		}
+	})
+
+	filter := entity.SubscriptionSumFilter{
+		UserID:    userID,
+		StartDate: month(1991, time.January),
+		EndDate:   month(1991, time.December),
+	}
+	// two charges of max int64 amount
+	_, err := _repo.GetSum(t.Context(), &filter)
+	require.ErrorIs(t, err, errors.ErrOverflow)
+}
+
+func TestSubs_GetSumPaused(t *testing.T) {
+	t.Log("Get sum of subs without paused months")
+
+	subs := entity.Subscription{
+		ID:          uuid.NewString(),
+		ServiceName: "Paused",
+		Price:       rub(10000),
+		UserID:      uuid.NewString(),
+		StartDate:   month(2024, time.January),
+		EndDate:     month(2024, time.June),
+	}
+	require.NoError(t, _repo.Create(t.Context(), &subs))
+	t.Cleanup(func() { require.NoError(t, _repo.Delete(context.Background(), subs.ID)) })
+
+	pause := entity.SubscriptionPause{
+		ID:        uuid.NewString(),
+		SubsID:    subs.ID,
+		StartDate: month(2024, time.March),
+	}
+	require.NoError(t, _repo.CreatePause(t.Context(), &pause))
+	overlapping := entity.SubscriptionPause{
+		ID:        uuid.NewString(),
+		SubsID:    subs.ID,
+		StartDate: month(2024, time.May),
+		EndDate:   month(2024, time.May),
+	}
+	require.ErrorIs(t, _repo.CreatePause(t.Context(), &overlapping), errors.ErrConflict)
+
+	filter := entity.SubscriptionSumFilter{
+		UserID:    subs.UserID,
+		StartDate: month(2024, time.January),
+		EndDate:   month(2024, time.December),
+	}
+	subsSum, err := _repo.GetSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, rub(20000), subsSum.Sum)
+
+	// resume from May
+	changed, err := _repo.Resume(t.Context(), subs.ID, *month(2024, time.May))
+	require.NoError(t, err)
+	require.Equal(t, 1, changed)
+	subsSum, err = _repo.GetSum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Equal(t, rub(40000), subsSum.Sum)
+
+	monthlySum, err := _repo.GetMonthlySum(t.Context(), &filter)
+	require.NoError(t, err)
+	require.Zero(t, monthlySum[2].Count)
+	require.Equal(t, 1, monthlySum[4].Count)
+}
@@ -0,0 +1,45 @@
+package pg
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ repo.TxManager = (*txManagerPG)(nil)
+
+// Context key for DB transaction.
+type txCtxKey struct{}
+
+// TxManager implementation.
+type txManagerPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewTxManager returns new TxManager instance.
+func NewTxManager(dbStorage *gorm.DB) repo.TxManager {
+	return &txManagerPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// WithinTx runs fn in DB transaction. All repos called with the context passed to fn
+// use this transaction. Transaction is rolled back if fn returns error.
+// Nested call runs fn in savepoint of the outer transaction.
+func (m *txManagerPG) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// error of fn is returned as is
+	return dbFromContext(ctx, m.dbStorage).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txCtxKey{}, tx))
+	})
+}
+
+// dbFromContext returns DB session of the transaction from the context
+// (if it is presented) or of the given DB.
+func dbFromContext(ctx context.Context, dbStorage *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txCtxKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return dbStorage.WithContext(ctx)
+}
@@ -0,0 +1,205 @@
+package pg
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+const _deliveriesBatchSize = 500 // max number of deliveries inserted with one query
+
+// Query to claim due pending deliveries: their next attempt time is moved forward by lease
+// so other workers skip them while they are sent.
+const _claimDeliveriesQuery = `UPDATE webhook_deliveries AS d
+SET next_attempt_at = now() + ?::interval
+WHERE d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.*`
+
+var _ repo.WebhooksRepoDB = (*webhooksRepoPG)(nil)
+
+// WebhooksRepoDB implementation.
+type webhooksRepoPG struct {
+	dbStorage *gorm.DB
+}
+
+// NewWebhooksRepoDB returns new WebhooksRepoDB instance.
+func NewWebhooksRepoDB(dbStorage *gorm.DB) repo.WebhooksRepoDB {
+	return &webhooksRepoPG{
+		dbStorage: dbStorage,
+	}
+}
+
+// Create creates new webhook.
+// All necessary fields must be presented.
+func (r *webhooksRepoPG) Create(ctx context.Context, webhook *entity.Webhook) error {
+	if err := dbFromContext(ctx, r.dbStorage).Create(webhook).Error; err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets webhook by given ID and returns it.
+func (r *webhooksRepoPG) GetByID(ctx context.Context, id string) (*entity.Webhook, error) {
+	webhook := &entity.Webhook{}
+
+	err := dbFromContext(ctx, r.dbStorage).Where("id = ?", id).First(webhook).Error
+	// if record not found
+	if goerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get by id: %w", err)
+	}
+	return webhook, nil
+}
+
+// Update updates webhook by given ID with given not nil values.
+// It returns full filled updated webhook.
+func (r *webhooksRepoPG) Update(
+	ctx context.Context,
+	webhook *entity.WebhookUpdate,
+) (*entity.Webhook, error) {
+	err := dbFromContext(ctx, r.dbStorage).Model(&entity.Webhook{}).
+		Where("id = ?", webhook.ID).
+		Updates(webhook).Error
+	if err != nil {
+		return nil, fmt.Errorf("update: %w", err)
+	}
+
+	// get updated webhook by ID
+	webhookFromDB, err := r.GetByID(ctx, webhook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update: %w", err)
+	}
+	return webhookFromDB, nil
+}
+
+// Delete deletes webhook with all its deliveries by its ID.
+func (r *webhooksRepoPG) Delete(ctx context.Context, id string) error {
+	dbQuery := dbFromContext(ctx, r.dbStorage).Delete(&entity.Webhook{}, "id = ?", id)
+	if dbQuery.Error != nil {
+		return fmt.Errorf("delete: %w", dbQuery.Error)
+	}
+	if dbQuery.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// GetList returns all webhooks sorted by creation time.
+func (r *webhooksRepoPG) GetList(ctx context.Context) (entity.WebhookList, error) {
+	webhookList := entity.WebhookList{}
+
+	err := dbFromContext(ctx, r.dbStorage).Order("created_at, id").Find(&webhookList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get list: %w", err)
+	}
+	return webhookList, nil
+}
+
+// GetActiveByEvent returns active webhooks subscribed to the event type.
+func (r *webhooksRepoPG) GetActiveByEvent(
+	ctx context.Context,
+	eventType entity.EventType,
+) (entity.WebhookList, error) {
+	webhookList := entity.WebhookList{}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Where("active AND ? = ANY(event_types)", string(eventType)).
+		Find(&webhookList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get active by event: %w", err)
+	}
+	return webhookList, nil
+}
+
+// CreateDeliveries creates given deliveries in one transaction.
+// Delivery of the event which already exists for the webhook is skipped.
+func (r *webhooksRepoPG) CreateDeliveries(
+	ctx context.Context,
+	deliveries []entity.WebhookDelivery,
+) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := dbFromContext(ctx, r.dbStorage).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		CreateInBatches(deliveries, _deliveriesBatchSize).Error
+	if err != nil {
+		return fmt.Errorf("create deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries which next attempt time has come.
+// Claimed deliveries are not returned again until lease is expired.
+func (r *webhooksRepoPG) ClaimDueDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+
+	err := dbFromContext(ctx, r.dbStorage).
+		Raw(_claimDeliveriesQuery, lease.String(), limit).
+		Scan(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("claim due deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves attempt result fields of the delivery.
+func (r *webhooksRepoPG) UpdateDelivery(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	err := dbFromContext(ctx, r.dbStorage).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_error",
+			"last_status_code", "delivered_at").
+		Updates(delivery).Error
+	if err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries returns deliveries of the webhook sorted from the newest ones.
+func (r *webhooksRepoPG) GetDeliveries(
+	ctx context.Context,
+	filter *entity.WebhookDeliveryFilter,
+) (entity.WebhookDeliveryList, error) {
+	deliveryList := entity.WebhookDeliveryList{}
+
+	dbQuery := dbFromContext(ctx, r.dbStorage).
+		Where("webhook_id = ?", filter.WebhookID)
+	if filter.Status != "" {
+		dbQuery = dbQuery.Where("status = ?", filter.Status)
+	}
+	err := dbQuery.
+		Order("created_at DESC, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&deliveryList).Error
+	if err != nil {
+		return nil, fmt.Errorf("get deliveries: %w", err)
+	}
+	return deliveryList, nil
+}
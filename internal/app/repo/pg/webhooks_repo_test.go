@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+func TestWebhooks_Deliveries(t *testing.T) {
+	t.Log("Create webhook, claim and update its deliveries")
+
+	webhooksRepo := NewWebhooksRepoDB(_dbStorage)
+	webhook := entity.Webhook{
+		ID:         uuid.NewString(),
+		URL:        "http://127.0.0.1:9000/hooks",
+		Secret:     "test-webhook-secret",
+		EventTypes: entity.TextArray{string(entity.EventSubsCreated)},
+		Active:     true,
+		CreatedAt:  time.Now().UTC(),
+	}
+	require.NoError(t, webhooksRepo.Create(t.Context(), &webhook))
+	t.Cleanup(func() {
+		// deliveries are deleted with webhook
+		_ = webhooksRepo.Delete(context.Background(), webhook.ID)
+	})
+
+	webhookList, err := webhooksRepo.GetActiveByEvent(t.Context(), entity.EventSubsCreated)
+	require.NoError(t, err)
+	require.True(t, containsWebhook(webhookList, webhook.ID))
+	webhookList, err = webhooksRepo.GetActiveByEvent(t.Context(), entity.EventSubsDeleted)
+	require.NoError(t, err)
+	require.False(t, containsWebhook(webhookList, webhook.ID))
+
+	now := time.Now().UTC()
+	delivery := entity.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookID:     webhook.ID,
+		EventID:       uuid.NewString(),
+		EventType:     entity.EventSubsCreated,
+		Payload:       entity.RawJSON(`{"type": "subs.created"}`),
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: now.Add(-time.Second),
+		CreatedAt:     now,
+	}
+	require.NoError(t, webhooksRepo.CreateDeliveries(t.Context(),
+		[]entity.WebhookDelivery{delivery}))
+
+	claimed, err := webhooksRepo.ClaimDueDeliveries(t.Context(), 1000, time.Minute)
+	require.NoError(t, err)
+	require.True(t, containsDelivery(claimed, delivery.ID))
+	// claimed delivery is leased
+	claimed, err = webhooksRepo.ClaimDueDeliveries(t.Context(), 1000, time.Minute)
+	require.NoError(t, err)
+	require.False(t, containsDelivery(claimed, delivery.ID))
+
+	delivery.Status = entity.DeliveryDead
+	delivery.Attempts = 3
+	delivery.LastError = "unexpected status 503"
+	delivery.LastStatusCode = 503
+	require.NoError(t, webhooksRepo.UpdateDelivery(t.Context(), &delivery))
+
+	deliveryList, err := webhooksRepo.GetDeliveries(t.Context(), &entity.WebhookDeliveryFilter{
+		WebhookID: webhook.ID,
+		Status:    entity.DeliveryDead,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveryList, 1)
+	require.Equal(t, 3, deliveryList[0].Attempts)
+	require.JSONEq(t, string(delivery.Payload), string(deliveryList[0].Payload))
+
+	require.NoError(t, webhooksRepo.Delete(t.Context(), webhook.ID))
+	_, err = webhooksRepo.GetByID(t.Context(), webhook.ID)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+// containsDelivery returns true if deliveries contain delivery with the given ID.
+func containsDelivery(deliveries []entity.WebhookDelivery, id string) bool {
+	for _, delivery := range deliveries {
+		if delivery.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWebhook returns true if webhooks contain webhook with the given ID.
+func containsWebhook(webhooks entity.WebhookList, id string) bool {
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -4,14 +4,104 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	"SubscriptionAggregator/internal/app/entity"
 )
 
 type SubsRepoDB interface {
-	Create(subs *entity.Subscription) error
-	GetByID(id string) (*entity.Subscription, error)
-	Update(subs *entity.SubscriptionUpdate) (*entity.Subscription, error)
-	Delete(id string) error
-	GetList() (entity.SubscriptionList, error)
-	GetSum(filter *entity.SubscriptionSumFilter) (int, error)
+	Create(ctx context.Context, subs *entity.Subscription) error
+	CreateBatch(ctx context.Context, subsList entity.SubscriptionList) error
+	GetByID(ctx context.Context, id string, includeDeleted bool) (*entity.Subscription, error)
+	LockByID(ctx context.Context, id string) (*entity.Subscription, error)
+	Update(ctx context.Context, subs *entity.SubscriptionUpdate) (*entity.Subscription, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*entity.Subscription, error)
+	Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	GetList(ctx context.Context,
+		filter *entity.SubscriptionListFilter) (*entity.SubscriptionPage, error)
+	Export(ctx context.Context, filter *entity.SubscriptionListFilter,
+		fn func(subs *entity.SubscriptionExport) error) error
+	GetUpcoming(ctx context.Context, userID string, from time.Time) (entity.SubscriptionList, error)
+	GetCharges(ctx context.Context, userID string,
+		from, to time.Time, limit int) (entity.SubscriptionChargeList, error)
+	GetNextChargeDates(ctx context.Context,
+		ids []string, from time.Time) (map[string]time.Time, error)
+	GetSum(ctx context.Context,
+		filter *entity.SubscriptionSumFilter) (*entity.SubscriptionSum, error)
+	GetGroupedSum(ctx context.Context,
+		filter *entity.SubscriptionSumGroupFilter) (entity.SubscriptionSumGroupList, error)
+	GetMonthlySum(ctx context.Context,
+		filter *entity.SubscriptionSumFilter) (entity.SubscriptionMonthlySumList, error)
+	MarkEnded(ctx context.Context, endedBefore time.Time, limit int) (entity.SubscriptionList, error)
+	CreatePause(ctx context.Context, pause *entity.SubscriptionPause) error
+	GetPauses(ctx context.Context, subsID string) (entity.SubscriptionPauseList, error)
+	GetPausesList(ctx context.Context, subsIDs []string) (entity.SubscriptionPauseList, error)
+	Resume(ctx context.Context, subsID string, month time.Time) (int, error)
+}
+
+type ExchangeRatesRepoDB interface {
+	Save(ctx context.Context, rates []entity.ExchangeRate) error
+}
+
+type APIKeysRepoDB interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	GetList(ctx context.Context) (entity.APIKeyList, error)
+	GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	UpdateLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type WebhooksRepoDB interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	GetByID(ctx context.Context, id string) (*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.WebhookUpdate) (*entity.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	GetList(ctx context.Context) (entity.WebhookList, error)
+	GetActiveByEvent(ctx context.Context, eventType entity.EventType) (entity.WebhookList, error)
+	CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context,
+		limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	GetDeliveries(ctx context.Context,
+		filter *entity.WebhookDeliveryFilter) (entity.WebhookDeliveryList, error)
+}
+
+// OutboxRepoDB stores event messages which are created in transactions of subs changes
+// and relayed to publishers in background.
+type OutboxRepoDB interface {
+	// ClaimPending returns up to limit pending messages which next attempt time has come
+	// in creation order. Claimed messages are not returned again until lease is expired.
+	ClaimPending(ctx context.Context,
+		limit int, lease time.Duration) ([]entity.OutboxMessage, error)
+	// UpdateMessage saves relay attempt result fields of the message.
+	UpdateMessage(ctx context.Context, msg *entity.OutboxMessage) error
+}
+
+// TxManager runs functions in DB transactions.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type AuditRepoDB interface {
+	Create(ctx context.Context, entry *entity.AuditEntry) error
+	CreateBatch(ctx context.Context, entries entity.AuditEntryList) error
+	GetList(ctx context.Context, filter *entity.AuditFilter) (entity.AuditEntryList, error)
+}
+
+// CalendarTokensRepoDB stores versions of the users calendar tokens.
+type CalendarTokensRepoDB interface {
+	// GetVersion returns version of the calendar token of the user (0 if it is not regenerated).
+	GetVersion(ctx context.Context, userID string) (int64, error)
+	// IncVersion increments version of the calendar token of the user and returns it.
+	IncVersion(ctx context.Context, userID string, regeneratedAt time.Time) (int64, error)
+}
+
+type IdempotencyRepoDB interface {
+	Create(ctx context.Context, key *entity.IdempotencyKey) (bool, error)
+	Get(ctx context.Context, owner, key string) (*entity.IdempotencyKey, error)
+	Update(ctx context.Context, key *entity.IdempotencyKey) error
+	Delete(ctx context.Context, key *entity.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, expiredBefore time.Time, limit int) (int, error)
 }
@@ -2,17 +2,22 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	fiber "github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"SubscriptionAggregator/config"
-	"SubscriptionAggregator/internal/app/errors"
+	apperrors "SubscriptionAggregator/internal/app/errors"
 	"SubscriptionAggregator/internal/app/middleware"
 
 	httpv1 "SubscriptionAggregator/internal/app/controller/http/v1"
@@ -21,10 +26,15 @@ import (
 
 	"SubscriptionAggregator/internal/pkg/database"
 	"SubscriptionAggregator/internal/pkg/jsonify"
+	"SubscriptionAggregator/internal/pkg/jwt"
 	"SubscriptionAggregator/internal/pkg/logger"
+	"SubscriptionAggregator/internal/pkg/publisher"
 	"SubscriptionAggregator/internal/pkg/validator"
+	"SubscriptionAggregator/internal/pkg/webhook"
 )
 
+const _apiV1Path = "/api/v1" // root path of API v1
+
 var _ Server = (*httpServer)(nil)
 
 // HTTP-server interface.
@@ -35,20 +45,43 @@ type Server interface {
 
 // HTTP-server implementation.
 type httpServer struct {
-	cfg     *config.Config
-	db      *gorm.DB
-	valid   validator.Validator
-	jsonify jsonify.Jsonify
+	cfg      *config.Config
+	db       *gorm.DB
+	valid    validator.Validator
+	jsonify  jsonify.Jsonify
+	verifier jwt.Verifier
+	// external outbox events publisher (nil if it is disabled)
+	publisher publisher.Publisher
 
 	fiberApp *fiber.App
 	err      chan error // server listen error
+
+	baseCtx       context.Context    // parent context for all requests and workers
+	cancelBaseCtx context.CancelFunc // cancels all running requests and workers
+
+	stopWorkers chan struct{}  // closed to stop background workers
+	workers     sync.WaitGroup // running background workers
 }
 
 // New returns new Server instance.
 func New(cfg *config.Config) (Server, error) {
 	logger.Init()
 
+	verifier, err := jwt.New(
+		jwt.WithHMACSecret(cfg.Auth.JWTSecret),
+		jwt.WithJWKSFile(cfg.Auth.JWKSFile),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("jwt verifier: %w", err)
+	}
+
+	outboxPublisher, err := newOutboxPublisher(&cfg.Outbox)
+	if err != nil {
+		return nil, fmt.Errorf("outbox publisher: %w", err)
+	}
+
 	gormDB, err := database.New(cfg.DB.ConnString,
+		database.WithStatementTimeout(cfg.Server.DBTimeout),
 		database.WithTranslateError(),
 		database.WithIgnoreNotFound(),
 		database.WithWarnLogLevel(),
@@ -58,15 +91,43 @@ func New(cfg *config.Config) (Server, error) {
 		return nil, fmt.Errorf("db: %w", err)
 	}
 
+	baseCtx, cancelBaseCtx := context.WithCancel(context.Background())
 	return &httpServer{
-		cfg:     cfg,
-		db:      gormDB,
-		valid:   validator.New(),
-		jsonify: jsonify.New(),
-		err:     make(chan error),
+		cfg:           cfg,
+		db:            gormDB,
+		valid:         validator.New(),
+		jsonify:       jsonify.New(),
+		verifier:      verifier,
+		publisher:     outboxPublisher,
+		err:           make(chan error),
+		baseCtx:       baseCtx,
+		cancelBaseCtx: cancelBaseCtx,
+		stopWorkers:   make(chan struct{}),
 	}, nil
 }
 
+// newOutboxPublisher returns external publisher of outbox events set in config.
+func newOutboxPublisher(cfg *config.Outbox) (publisher.Publisher, error) {
+	switch cfg.Publisher {
+	case "log":
+		return publisher.NewLog(), nil
+	case "http":
+		if cfg.HTTPURL == "" {
+			return nil, errors.New("http url is required for http publisher")
+		}
+		return publisher.NewHTTP(cfg.HTTPURL, cfg.PublishTimeout), nil
+	case "nats":
+		if cfg.NATSAddr == "" {
+			return nil, errors.New("nats address is required for nats publisher")
+		}
+		return publisher.NewNATS(cfg.NATSAddr, cfg.NATSSubject, cfg.PublishTimeout), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown publisher %q", cfg.Publisher)
+	}
+}
+
 //	@title			Subscription Aggregator API
 //	@version		1.0.0
 //	@description	HTTP API для агрегации данных об онлайн-подписках пользователей
@@ -78,12 +139,17 @@ func New(cfg *config.Config) (Server, error) {
 //	@accept			json
 //	@produce		json
 //
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				JWT (HS256 или RS256) в формате "Bearer <token>" или API-ключ в формате "ApiKey <key>"
+//
 // Run starts server.
 func (s *httpServer) Run() {
 	// app init
 	s.fiberApp = fiber.New(fiber.Config{
 		AppName:       s.cfg.Server.Name,
-		ErrorHandler:  errors.CustomErrorHandler,
+		ErrorHandler:  apperrors.CustomErrorHandler,
 		JSONEncoder:   s.jsonify.Marshal,
 		JSONDecoder:   s.jsonify.Unmarshal,
 		ServerHeader:  "Subscription Aggregator API",
@@ -93,17 +159,103 @@ func (s *httpServer) Run() {
 	// set up base middlewares
 	s.fiberApp.Use(middleware.Logger())
 	s.fiberApp.Use(middleware.Recover())
+	s.fiberApp.Use(middleware.RequestContext(s.baseCtx))
+	s.fiberApp.Use(middleware.RequestID())
 	s.fiberApp.Use(middleware.Swagger())
 
 	// create repos
 	subsRepoDB := repopg.NewSubsRepoDB(s.db)
+	apiKeysRepoDB := repopg.NewAPIKeysRepoDB(s.db)
+	webhooksRepoDB := repopg.NewWebhooksRepoDB(s.db)
+	outboxRepoDB := repopg.NewOutboxRepoDB(s.db)
+	auditRepoDB := repopg.NewAuditRepoDB(s.db)
+	idempotencyRepoDB := repopg.NewIdempotencyRepoDB(s.db)
+	calendarTokensRepoDB := repopg.NewCalendarTokensRepoDB(s.db)
+	txManager := repopg.NewTxManager(s.db)
 	// create usecases
-	subsUsecase := usecase.NewSubsUsecase(subsRepoDB)
+	webhooksUsecase := usecase.NewWebhooksUsecase(webhooksRepoDB, subsRepoDB,
+		webhook.NewSender(s.cfg.Webhooks.RequestTimeout),
+		usecase.WebhooksConfig{
+			BatchSize:    s.cfg.Webhooks.BatchSize,
+			MaxAttempts:  s.cfg.Webhooks.MaxAttempts,
+			RetryBackoff: s.cfg.Webhooks.RetryBackoff,
+			// claimed deliveries are sent one by one so the whole batch must fit the lease
+			Lease: time.Duration(s.cfg.Webhooks.BatchSize+1) * s.cfg.Webhooks.RequestTimeout,
+		})
+	// events are published to webhooks and to external publisher
+	outboxPublishers := []usecase.OutboxPublisher{{Name: "webhooks", Publisher: webhooksUsecase}}
+	if s.publisher != nil {
+		outboxPublishers = append(outboxPublishers,
+			usecase.OutboxPublisher{Name: s.cfg.Outbox.Publisher, Publisher: s.publisher})
+	}
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepoDB,
+		usecase.OutboxConfig{
+			BatchSize:    s.cfg.Outbox.BatchSize,
+			MaxAttempts:  s.cfg.Outbox.MaxAttempts,
+			RetryBackoff: s.cfg.Outbox.RetryBackoff,
+			// claimed messages are published one by one (to webhooks with DB query)
+			// so the whole batch must fit the lease
+			Lease: time.Duration(s.cfg.Outbox.BatchSize+1) *
+				(s.cfg.Outbox.PublishTimeout + s.cfg.Server.DBTimeout),
+		},
+		outboxPublishers...)
+	subsUsecase := usecase.NewSubsUsecase(txManager, subsRepoDB, auditRepoDB, s.valid)
+	auditUsecase := usecase.NewAuditUsecase(auditRepoDB)
+	apiKeysUsecase := usecase.NewAPIKeysUsecase(apiKeysRepoDB)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(idempotencyRepoDB,
+		usecase.IdempotencyConfig{
+			TTL:            s.cfg.Idempotency.TTL,
+			Lease:          s.cfg.Idempotency.Lease,
+			PurgeBatchSize: s.cfg.Idempotency.PurgeBatchSize,
+		})
 	// create controllers
-	subsController := httpv1.NewSubsController(subsUsecase, s.valid)
+	subsController := httpv1.NewSubsController(subsUsecase, s.valid,
+		s.cfg.Server.RequireIfMatch, s.cfg.Server.ExportTimeout)
+	apiKeysController := httpv1.NewAPIKeysController(apiKeysUsecase, s.valid)
+	webhooksController := httpv1.NewWebhooksController(webhooksUsecase, s.valid)
+	auditController := httpv1.NewAuditController(auditUsecase, s.valid)
+	// calendar is disabled without secret
+	var calendarController *httpv1.CalendarController
+	if s.cfg.Calendar.Secret != "" {
+		calendarController = httpv1.NewCalendarController(
+			usecase.NewCalendarUsecase(subsRepoDB, calendarTokensRepoDB, s.cfg.Calendar.Secret),
+			s.valid, _apiV1Path)
+	}
 	// register endpoints
-	apiV1 := s.fiberApp.Group("/api/v1")
-	httpv1.RegisterSubsEndpoints(apiV1, subsController)
+	apiV1 := s.fiberApp.Group(_apiV1Path)
+	// public endpoints are registered before authentication middleware
+	if calendarController != nil {
+		httpv1.RegisterPublicCalendarEndpoints(apiV1, calendarController)
+	}
+	apiV1.Use(middleware.Auth(s.verifier, apiKeysUsecase))
+	httpv1.RegisterSubsEndpoints(apiV1, subsController,
+		middleware.Idempotency(idempotencyUsecase))
+	httpv1.RegisterAPIKeysEndpoints(apiV1, apiKeysController)
+	httpv1.RegisterWebhooksEndpoints(apiV1, webhooksController)
+	httpv1.RegisterAuditEndpoints(apiV1, auditController)
+	if calendarController != nil {
+		httpv1.RegisterCalendarEndpoints(apiV1, calendarController)
+	}
+
+	// start background workers
+	s.startWorker("outbox relay", s.cfg.Outbox.PollInterval, true,
+		func(ctx context.Context) (bool, error) {
+			sent, err := outboxUsecase.Relay(ctx)
+			return sent == s.cfg.Outbox.BatchSize, err
+		})
+	s.startWorker("webhooks", s.cfg.Webhooks.PollInterval, false,
+		func(ctx context.Context) (bool, error) {
+			if _, err := webhooksUsecase.NotifyEnded(ctx); err != nil {
+				return false, err
+			}
+			sent, err := webhooksUsecase.DeliverPending(ctx)
+			return sent == s.cfg.Webhooks.BatchSize, err
+		})
+	s.startWorker("idempotency keys purge", s.cfg.Idempotency.PurgeInterval, false,
+		func(ctx context.Context) (bool, error) {
+			purged, err := idempotencyUsecase.PurgeExpired(ctx)
+			return purged == s.cfg.Idempotency.PurgeBatchSize, err
+		})
 
 	// start app
 	go func() {
@@ -113,6 +265,70 @@ func (s *httpServer) Run() {
 	}()
 }
 
+// workerJob is a background worker job.
+// It returns true if it has more work to do right now.
+type workerJob func(ctx context.Context) (bool, error)
+
+// startWorker runs job in background every interval until workers are stopped.
+// If drain is set job is run until it has no more work after workers are stopped.
+func (s *httpServer) startWorker(name string, interval time.Duration, drain bool, job workerJob) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopWorkers:
+				if drain {
+					s.runJob(name, job)
+				}
+				return
+			case <-ticker.C:
+				s.runJob(name, job)
+			}
+		}
+	}()
+}
+
+// runJob runs job while it has more work and base context is not cancelled.
+func (s *httpServer) runJob(name string, job workerJob) {
+	for s.baseCtx.Err() == nil {
+		more, err := job(s.baseCtx)
+		if err != nil {
+			logrus.Errorf("Worker %s: %v", name, err)
+			return
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// shutdownWorkers stops background workers and waits for them until shutdown context
+// is done (outbox relay drains unsent events). Then it cancels still running jobs.
+func (s *httpServer) shutdownWorkers(shutdownCtx context.Context) {
+	close(s.stopWorkers)
+	workersDone := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		logrus.Warn("Background workers are not stopped in time. Cancel them")
+	}
+	s.cancelBaseCtx()
+	<-workersDone
+
+	if closer, ok := s.publisher.(io.Closer); ok {
+		closer.Close() // nolint:errcheck,gosec // server is stopped anyway
+	}
+}
+
 // WaitForShutdown waits for OS signal to gracefully shuts down server.
 // This method is blocking.
 func (s *httpServer) WaitForShutdown() error {
@@ -140,8 +356,16 @@ func (s *httpServer) WaitForShutdown() error {
 			return
 		case handledSignal := <-quit:
 			logrus.Infof("Got %s signal. Shutdown server", handledSignal.String())
+			// requests and jobs which are still running at the shutdown deadline are canceled
+			shutdownCtx, cancel := context.WithTimeout(context.Background(),
+				s.cfg.Server.ShutdownTimeout)
+			defer cancel()
+			stopCancel := context.AfterFunc(shutdownCtx, s.cancelBaseCtx)
+			defer stopCancel()
 			// shutdown app
-			s.fiberApp.ShutdownWithTimeout(s.cfg.Server.ShutdownTimeout) // nolint:errcheck // cannot occurs
+			s.fiberApp.ShutdownWithContext(shutdownCtx) // nolint:errcheck // cannot occurs
+			// stop workers after all requests are finished to relay their events
+			s.shutdownWorkers(shutdownCtx)
 		}
 	}()
 
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+const (
+	_apiKeyPrefix = "sa_" // prefix of plaintext API keys
+	_apiKeyBytes  = 32    // number of random bytes in API key
+)
+
+var _ APIKeysUsecase = (*apiKeysUsecase)(nil)
+
+// APIKeysUsecase implementation.
+type apiKeysUsecase struct {
+	apiKeysRepoDB repo.APIKeysRepoDB
+}
+
+// NewAPIKeysUsecase returns new APIKeysUsecase instance.
+func NewAPIKeysUsecase(apiKeysRepoDB repo.APIKeysRepoDB) APIKeysUsecase {
+	return &apiKeysUsecase{
+		apiKeysRepoDB: apiKeysRepoDB,
+	}
+}
+
+// Create generates new API key and saves its hash.
+// Name and scopes must be presented. Only admin can create keys.
+// Plaintext key is returned only here and it is never stored.
+func (u *apiKeysUsecase) Create(
+	ctx context.Context,
+	key *entity.APIKey,
+) (*entity.APIKeyCreated, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "create api key")
+	}
+	now := time.Now().UTC()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, errors.Wrap(fmt.Errorf("%w: expiration time is in the past",
+			apperrors.ErrValidateData), "create api key")
+	}
+
+	randomBytes := make([]byte, _apiKeyBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, errors.Wrap(err, "create api key: generate key")
+	}
+	plainKey := _apiKeyPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+
+	key.ID = uuid.NewString()
+	key.KeyHash = hashAPIKey(plainKey)
+	key.CreatedAt = now
+	if err := u.apiKeysRepoDB.Create(ctx, key); err != nil {
+		return nil, errors.Wrap(err, "create api key")
+	}
+	return &entity.APIKeyCreated{APIKey: *key, Key: plainKey}, nil
+}
+
+// GetAll returns all API keys (without plaintext keys). Only admin can list keys.
+func (u *apiKeysUsecase) GetAll(ctx context.Context) (entity.APIKeyList, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "get all api keys")
+	}
+	keyList, err := u.apiKeysRepoDB.GetList(ctx)
+	return keyList, errors.Wrap(err, "get all api keys")
+}
+
+// Revoke revokes API key by its ID. Only admin can revoke keys.
+func (u *apiKeysUsecase) Revoke(ctx context.Context, id string) error {
+	if _, err := requireAdmin(ctx); err != nil {
+		return errors.Wrap(err, "revoke api key")
+	}
+	err := u.apiKeysRepoDB.Revoke(ctx, id, time.Now().UTC())
+	return errors.Wrap(err, "revoke api key")
+}
+
+// Authenticate returns user authenticated by the given plaintext API key.
+// It returns ErrUnauthorized if key is unknown, revoked or expired.
+// Key without owner gets access to subs of all users.
+// Last usage time is saved only if the saved one is outdated to avoid a DB write
+// on every request.
+func (u *apiKeysUsecase) Authenticate(ctx context.Context, key string) (*entity.AuthUser, error) {
+	apiKey, err := u.apiKeysRepoDB.GetByHash(ctx, hashAPIKey(key))
+	if goerrors.Is(err, apperrors.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key", apperrors.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticate api key")
+	}
+	now := time.Now().UTC()
+	if !apiKey.IsActive(now) {
+		return nil, fmt.Errorf("%w: api key is revoked or expired", apperrors.ErrUnauthorized)
+	}
+	if apiKey.LastUsedOutdated(now) {
+		if err := u.apiKeysRepoDB.UpdateLastUsed(ctx, apiKey.ID, now); err != nil {
+			return nil, errors.Wrap(err, "authenticate api key")
+		}
+	}
+
+	user := &entity.AuthUser{Role: entity.RoleService, Scopes: apiKey.Scopes, APIKeyID: apiKey.ID}
+	if apiKey.OwnerID != nil {
+		user.ID, user.Role = *apiKey.OwnerID, entity.RoleUser
+	}
+	return user, nil
+}
+
+// hashAPIKey returns SHA-256 hash (hex) of the plaintext API key.
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
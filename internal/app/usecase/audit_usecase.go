@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ AuditUsecase = (*auditUsecase)(nil)
+
+// AuditUsecase implementation.
+type auditUsecase struct {
+	auditRepoDB repo.AuditRepoDB
+}
+
+// NewAuditUsecase returns new AuditUsecase instance.
+func NewAuditUsecase(auditRepoDB repo.AuditRepoDB) AuditUsecase {
+	return &auditUsecase{
+		auditRepoDB: auditRepoDB,
+	}
+}
+
+// GetAll returns audit log of all subs filtered by filter. Only admin can get audit log.
+func (u *auditUsecase) GetAll(
+	ctx context.Context,
+	filter *entity.AuditFilter,
+) (entity.AuditEntryList, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "get audit log")
+	}
+	entryList, err := u.auditRepoDB.GetList(ctx, filter)
+	return entryList, errors.Wrap(err, "get audit log")
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+// authUser returns authenticated user from the context.
+// It returns ErrUnauthorized if there is no user in the context.
+func authUser(ctx context.Context) (*entity.AuthUser, error) {
+	user, ok := entity.AuthUserFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: no authenticated user", errors.ErrUnauthorized)
+	}
+	return user, nil
+}
+
+// scopeUserID returns user ID to scope operation with the given user ID by.
+// Admin and service can use any user ID (empty one means all users).
+// Regular user can use only his own ID (it is used if the given one is empty),
+// otherwise ErrForbidden is returned.
+func scopeUserID(ctx context.Context, userID string) (string, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	if user.HasAllUsersAccess() {
+		return userID, nil
+	}
+	if userID != "" && userID != user.ID {
+		return "", fmt.Errorf("%w: access to subs of another user", errors.ErrForbidden)
+	}
+	return user.ID, nil
+}
+
+// requireAdmin returns authenticated user if he is admin.
+// It returns ErrForbidden otherwise.
+func requireAdmin(ctx context.Context) (*entity.AuthUser, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin() {
+		return nil, fmt.Errorf("%w: admin role is required", errors.ErrForbidden)
+	}
+	return user, nil
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+)
+
+func TestScopeUserID(t *testing.T) {
+	t.Log("Scope user ID by authenticated user")
+
+	const userID, anotherUserID = "user", "another-user"
+	userCtx := entity.ContextWithAuthUser(t.Context(),
+		&entity.AuthUser{ID: userID, Role: entity.RoleUser})
+	adminCtx := entity.ContextWithAuthUser(t.Context(),
+		&entity.AuthUser{ID: userID, Role: entity.RoleAdmin})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		userID   string
+		expected string
+		wantErr  error
+	}{
+		{name: "user without user id", ctx: userCtx, userID: "", expected: userID},
+		{name: "user with own id", ctx: userCtx, userID: userID, expected: userID},
+		{name: "user with another id", ctx: userCtx, userID: anotherUserID,
+			wantErr: errors.ErrForbidden},
+		{name: "admin without user id", ctx: adminCtx, userID: "", expected: ""},
+		{name: "admin with another id", ctx: adminCtx, userID: anotherUserID, expected: anotherUserID},
+		{name: "unauthenticated", ctx: t.Context(), userID: userID, wantErr: errors.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopedUserID, err := scopeUserID(tt.ctx, tt.userID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, scopedUserID)
+		})
+	}
+}
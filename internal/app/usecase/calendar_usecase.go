@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+const _calendarTokenPrefix = "calendar:" // prefix of the signed user ID of calendar token
+
+var _ CalendarUsecase = (*calendarUsecase)(nil)
+
+// CalendarUsecase implementation.
+type calendarUsecase struct {
+	subsRepoDB   repo.SubsRepoDB
+	tokensRepoDB repo.CalendarTokensRepoDB
+	secret       []byte
+	now          func() time.Time
+}
+
+// NewCalendarUsecase returns new CalendarUsecase instance.
+// Calendar tokens are signed with the given secret, its change revokes all tokens.
+// Token of the user is revoked by its regeneration.
+func NewCalendarUsecase(
+	subsRepoDB repo.SubsRepoDB,
+	tokensRepoDB repo.CalendarTokensRepoDB,
+	secret string,
+) CalendarUsecase {
+	return &calendarUsecase{
+		subsRepoDB:   subsRepoDB,
+		tokensRepoDB: tokensRepoDB,
+		secret:       []byte(secret),
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Token returns calendar token of the user. Regular user can get only his own token.
+func (u *calendarUsecase) Token(ctx context.Context, userID string) (string, error) {
+	userID, err := scopeCalendarUserID(ctx, userID)
+	if err != nil {
+		return "", errors.Wrap(err, "get calendar token")
+	}
+	version, err := u.tokensRepoDB.GetVersion(ctx, userID)
+	if err != nil {
+		return "", errors.Wrap(err, "get calendar token")
+	}
+	return u.sign(userID, version), nil
+}
+
+// RegenerateToken returns new calendar token of the user and revokes the previous one.
+// Regular user can regenerate only his own token.
+func (u *calendarUsecase) RegenerateToken(ctx context.Context, userID string) (string, error) {
+	userID, err := scopeCalendarUserID(ctx, userID)
+	if err != nil {
+		return "", errors.Wrap(err, "regenerate calendar token")
+	}
+	version, err := u.tokensRepoDB.IncVersion(ctx, userID, u.now())
+	if err != nil {
+		return "", errors.Wrap(err, "regenerate calendar token")
+	}
+	return u.sign(userID, version), nil
+}
+
+// scopeCalendarUserID checks access to the calendar of the user like scopeUserID
+// and requires user ID.
+func scopeCalendarUserID(ctx context.Context, userID string) (string, error) {
+	userID, err := scopeUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return "", fmt.Errorf("%w: user id is required", apperrors.ErrValidateData)
+	}
+	return userID, nil
+}
+
+// GetSchedules returns charges schedules of the user subs which are not ended
+// before the current month. Access is checked by the calendar token of the user,
+// ErrNotFound is returned for invalid or revoked token.
+func (u *calendarUsecase) GetSchedules(
+	ctx context.Context,
+	userID string,
+	token string,
+) (entity.SubscriptionScheduleList, error) {
+	version, err := u.tokensRepoDB.GetVersion(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get schedules")
+	}
+	if !hmac.Equal([]byte(u.sign(userID, version)), []byte(token)) {
+		return nil, errors.Wrap(fmt.Errorf("%w: invalid calendar token", apperrors.ErrNotFound),
+			"get schedules")
+	}
+
+	scheduleList, err := getUpcomingSchedules(ctx, u.subsRepoDB, userID, monthStart(u.now()))
+	return scheduleList, errors.Wrap(err, "get schedules")
+}
+
+// sign returns calendar token of the user with the given token version:
+// URL-safe base64 HMAC-SHA256 of the user ID and version.
+// Version 0 is not signed, so tokens issued before regeneration remain valid.
+func (u *calendarUsecase) sign(userID string, version int64) string {
+	message := _calendarTokenPrefix + userID
+	if version > 0 {
+		message += ":" + strconv.FormatInt(version, 10)
+	}
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
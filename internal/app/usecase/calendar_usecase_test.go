@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+// fakeCalendarTokensRepo is in-memory CalendarTokensRepoDB.
+type fakeCalendarTokensRepo struct {
+	repo.CalendarTokensRepoDB
+	versions map[string]int64
+}
+
+func (r *fakeCalendarTokensRepo) GetVersion(_ context.Context, userID string) (int64, error) {
+	return r.versions[userID], nil
+}
+
+func (r *fakeCalendarTokensRepo) IncVersion(
+	_ context.Context,
+	userID string,
+	_ time.Time,
+) (int64, error) {
+	r.versions[userID]++
+	return r.versions[userID], nil
+}
+
+func (r *fakeSubsRepo) GetUpcoming(
+	_ context.Context,
+	userID string,
+	from time.Time,
+) (entity.SubscriptionList, error) {
+	subsList := entity.SubscriptionList{}
+	for _, subs := range r.subs {
+		if subs.UserID == userID && (subs.EndDate == nil || !subs.EndDate.Before(from)) {
+			subsList = append(subsList, *subs)
+		}
+	}
+	return subsList, nil
+}
+
+func (r *fakeSubsRepo) GetPausesList(
+	_ context.Context,
+	subsIDs []string,
+) (entity.SubscriptionPauseList, error) {
+	pauseList := entity.SubscriptionPauseList{}
+	for _, pause := range r.pauses {
+		for _, id := range subsIDs {
+			if pause.SubsID == id {
+				pauseList = append(pauseList, pause)
+			}
+		}
+	}
+	return pauseList, nil
+}
+
+func TestCalendar_GetSchedules(t *testing.T) {
+	t.Log("Get schedules of user subs by calendar token")
+
+	pausedID := uuid.NewString()
+	subsRepo := &fakeSubsRepo{
+		subs: map[string]*entity.Subscription{
+			"ended": {ID: "ended", UserID: _testUserID, StartDate: month(time.January),
+				EndDate: month(time.March), BillingPeriod: entity.BillingMonthly, BillingInterval: 1},
+			"active": {ID: "active", UserID: _testUserID, StartDate: month(time.January),
+				BillingPeriod: entity.BillingMonthly, BillingInterval: 1},
+			pausedID: {ID: pausedID, UserID: _testUserID, StartDate: month(time.March),
+				BillingPeriod: entity.BillingQuarterly, BillingInterval: 1},
+		},
+		pauses: entity.SubscriptionPauseList{{SubsID: pausedID, StartDate: month(time.January)}},
+	}
+	tokensRepo := &fakeCalendarTokensRepo{versions: make(map[string]int64)}
+	calendarUC := NewCalendarUsecase(subsRepo, tokensRepo, "secret").(*calendarUsecase)
+	calendarUC.now = func() time.Time { return time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC) }
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+
+	// user can get only his own token
+	_, err := calendarUC.Token(ctx, uuid.NewString())
+	require.ErrorIs(t, err, errors.ErrForbidden)
+	token, err := calendarUC.Token(ctx, "")
+	require.NoError(t, err)
+
+	// token does not need authenticated user
+	scheduleList, err := calendarUC.GetSchedules(context.Background(), _testUserID, token)
+	require.NoError(t, err)
+	// ended subs and subs paused from its start have no upcoming charges
+	require.Len(t, scheduleList, 1)
+	require.Equal(t, "active", scheduleList[0].ID)
+
+	_, err = calendarUC.GetSchedules(context.Background(), uuid.NewString(), token)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+	otherUC := NewCalendarUsecase(subsRepo, tokensRepo, "another secret")
+	_, err = otherUC.GetSchedules(context.Background(), _testUserID, token)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+}
+
+func TestCalendar_RegenerateToken(t *testing.T) {
+	t.Log("Regenerate calendar token and revoke the previous one")
+
+	tokensRepo := &fakeCalendarTokensRepo{versions: make(map[string]int64)}
+	calendarUC := NewCalendarUsecase(&fakeSubsRepo{}, tokensRepo, "secret")
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+	anotherID := uuid.NewString()
+
+	// user can regenerate only his own token
+	_, err := calendarUC.RegenerateToken(ctx, anotherID)
+	require.ErrorIs(t, err, errors.ErrForbidden)
+	anotherToken, err := calendarUC.Token(entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: anotherID, Role: entity.RoleUser}), "")
+	require.NoError(t, err)
+
+	oldToken, err := calendarUC.Token(ctx, "")
+	require.NoError(t, err)
+	newToken, err := calendarUC.RegenerateToken(ctx, "")
+	require.NoError(t, err)
+	require.NotEqual(t, oldToken, newToken)
+	token, err := calendarUC.Token(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, newToken, token)
+
+	_, err = calendarUC.GetSchedules(context.Background(), _testUserID, oldToken)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+	_, err = calendarUC.GetSchedules(context.Background(), _testUserID, newToken)
+	require.NoError(t, err)
+	// tokens of other users are not revoked
+	_, err = calendarUC.GetSchedules(context.Background(), anotherID, anotherToken)
+	require.NoError(t, err)
+}
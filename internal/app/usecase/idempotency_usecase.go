@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+var _ IdempotencyUsecase = (*idempotencyUsecase)(nil)
+
+// IdempotencyConfig is a config of idempotency keys.
+type IdempotencyConfig struct {
+	// time for which key and saved response are kept
+	TTL time.Duration
+	// time for which the request in progress holds the key (must be longer than request),
+	// after it the same request can take over the key (e.g. if the service is crashed)
+	Lease time.Duration
+	// max number of expired keys purged at once
+	PurgeBatchSize int
+}
+
+// IdempotencyUsecase implementation.
+type idempotencyUsecase struct {
+	idempotencyRepoDB repo.IdempotencyRepoDB
+	cfg               IdempotencyConfig
+	now               func() time.Time
+}
+
+// NewIdempotencyUsecase returns new IdempotencyUsecase instance.
+func NewIdempotencyUsecase(
+	idempotencyRepoDB repo.IdempotencyRepoDB,
+	cfg IdempotencyConfig,
+) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		idempotencyRepoDB: idempotencyRepoDB,
+		cfg:               cfg,
+		now:               func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Begin starts the request with idempotency key of the authenticated user.
+// Key and request hash must be presented, key owner is set here.
+// It returns nil if the request must be handled and saved key with the response
+// to replay if the request with the key is already completed.
+// It returns ErrIdempotencyMismatch if the key is used with another request
+// and ErrConflict if the request with the key is still in progress and holds the key.
+func (u *idempotencyUsecase) Begin(
+	ctx context.Context,
+	key *entity.IdempotencyKey,
+) (*entity.IdempotencyKey, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "begin idempotent request")
+	}
+	key.Owner = idempotencyKeyOwner(user)
+	// creation time identifies the request holding the key, so it has DB precision
+	key.CreatedAt = u.now().Truncate(time.Microsecond)
+	key.LockedUntil = key.CreatedAt.Add(u.cfg.Lease)
+	key.ExpiresAt = key.CreatedAt.Add(u.cfg.TTL)
+
+	created, err := u.idempotencyRepoDB.Create(ctx, key)
+	if err != nil || created {
+		return nil, errors.Wrap(err, "begin idempotent request")
+	}
+
+	savedKey, err := u.idempotencyRepoDB.Get(ctx, key.Owner, key.Key)
+	// key has been just released by the concurrent request
+	if goerrors.Is(err, apperrors.ErrNotFound) {
+		err = fmt.Errorf("%w: request with this idempotency key is retried concurrently",
+			apperrors.ErrConflict)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "begin idempotent request")
+	}
+	switch {
+	case savedKey.RequestHash != key.RequestHash:
+		return nil, errors.Wrap(apperrors.ErrIdempotencyMismatch, "begin idempotent request")
+	case !savedKey.IsCompleted():
+		return nil, errors.Wrap(fmt.Errorf("%w: request with this idempotency key is in progress",
+			apperrors.ErrConflict), "begin idempotent request")
+	}
+	return savedKey, nil
+}
+
+// Complete saves response of the request started with the key.
+func (u *idempotencyUsecase) Complete(ctx context.Context, key *entity.IdempotencyKey) error {
+	err := u.idempotencyRepoDB.Update(ctx, key)
+	return errors.Wrap(err, "complete idempotent request")
+}
+
+// Release deletes the key of the failed request, so the request can be repeated.
+func (u *idempotencyUsecase) Release(ctx context.Context, key *entity.IdempotencyKey) error {
+	err := u.idempotencyRepoDB.Delete(ctx, key)
+	return errors.Wrap(err, "release idempotent request")
+}
+
+// PurgeExpired deletes batch of expired keys. It returns number of deleted keys.
+func (u *idempotencyUsecase) PurgeExpired(ctx context.Context) (int, error) {
+	purged, err := u.idempotencyRepoDB.DeleteExpired(ctx, u.now(), u.cfg.PurgeBatchSize)
+	return purged, errors.Wrap(err, "purge expired idempotency keys")
+}
+
+// idempotencyKeyOwner returns owner of the keys used by the user
+// (API key without owner has its own keys).
+func idempotencyKeyOwner(user *entity.AuthUser) string {
+	if user.ID == "" {
+		return "api-key:" + user.APIKeyID
+	}
+	return user.ID
+}
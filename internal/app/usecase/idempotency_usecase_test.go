@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+// fakeIdempotencyRepo is in-memory IdempotencyRepoDB.
+type fakeIdempotencyRepo struct {
+	repo.IdempotencyRepoDB
+	keys map[string]entity.IdempotencyKey
+}
+
+func (r *fakeIdempotencyRepo) Create(_ context.Context, key *entity.IdempotencyKey) (bool, error) {
+	savedKey, ok := r.keys[key.Owner+key.Key]
+	if ok && savedKey.ExpiresAt.After(key.CreatedAt) && (savedKey.IsCompleted() ||
+		savedKey.LockedUntil.After(key.CreatedAt) || savedKey.RequestHash != key.RequestHash) {
+		return false, nil
+	}
+	r.keys[key.Owner+key.Key] = *key
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepo) Get(
+	_ context.Context,
+	owner, key string,
+) (*entity.IdempotencyKey, error) {
+	savedKey, ok := r.keys[owner+key]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &savedKey, nil
+}
+
+func (r *fakeIdempotencyRepo) Update(_ context.Context, key *entity.IdempotencyKey) error {
+	if !r.keys[key.Owner+key.Key].CreatedAt.Equal(key.CreatedAt) {
+		return errors.ErrNotFound
+	}
+	r.keys[key.Owner+key.Key] = *key
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Delete(_ context.Context, key *entity.IdempotencyKey) error {
+	savedKey := r.keys[key.Owner+key.Key]
+	if !savedKey.IsCompleted() && savedKey.CreatedAt.Equal(key.CreatedAt) {
+		delete(r.keys, key.Owner+key.Key)
+	}
+	return nil
+}
+
+func TestIdempotency_Begin(t *testing.T) {
+	t.Log("Begin, repeat, complete and replay requests with idempotency key")
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	idempotencyUC := NewIdempotencyUsecase(
+		&fakeIdempotencyRepo{keys: make(map[string]entity.IdempotencyKey)},
+		IdempotencyConfig{TTL: time.Hour, Lease: time.Minute},
+	).(*idempotencyUsecase)
+	idempotencyUC.now = func() time.Time { return now }
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+
+	key := &entity.IdempotencyKey{Key: "key", RequestHash: "hash"}
+	savedKey, err := idempotencyUC.Begin(ctx, key)
+	require.NoError(t, err)
+	require.Nil(t, savedKey)
+	require.Equal(t, _testUserID, key.Owner)
+
+	// request is in progress
+	_, err = idempotencyUC.Begin(ctx, &entity.IdempotencyKey{Key: "key", RequestHash: "hash"})
+	require.ErrorIs(t, err, errors.ErrConflict)
+	// key is used with another request
+	_, err = idempotencyUC.Begin(ctx, &entity.IdempotencyKey{Key: "key", RequestHash: "other"})
+	require.ErrorIs(t, err, errors.ErrIdempotencyMismatch)
+
+	key.ResponseStatus = 201
+	key.ResponseBody = []byte(`{"id":"1"}`)
+	require.NoError(t, idempotencyUC.Complete(ctx, key))
+	savedKey, err = idempotencyUC.Begin(ctx,
+		&entity.IdempotencyKey{Key: "key", RequestHash: "hash"})
+	require.NoError(t, err)
+	require.Equal(t, key.ResponseBody, savedKey.ResponseBody)
+
+	// key of another user is not the same key
+	otherCtx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{Role: entity.RoleService, APIKeyID: "api-key"})
+	savedKey, err = idempotencyUC.Begin(otherCtx,
+		&entity.IdempotencyKey{Key: "key", RequestHash: "other"})
+	require.NoError(t, err)
+	require.Nil(t, savedKey)
+
+	// expired key is used again
+	now = now.Add(2 * time.Hour)
+	savedKey, err = idempotencyUC.Begin(ctx,
+		&entity.IdempotencyKey{Key: "key", RequestHash: "other"})
+	require.NoError(t, err)
+	require.Nil(t, savedKey)
+}
+
+func TestIdempotency_Release(t *testing.T) {
+	t.Log("Repeat request after the failed one")
+
+	idempotencyUC := NewIdempotencyUsecase(
+		&fakeIdempotencyRepo{keys: make(map[string]entity.IdempotencyKey)},
+		IdempotencyConfig{TTL: time.Hour},
+	)
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+
+	key := &entity.IdempotencyKey{Key: "key", RequestHash: "hash"}
+	_, err := idempotencyUC.Begin(ctx, key)
+	require.NoError(t, err)
+	require.NoError(t, idempotencyUC.Release(ctx, key))
+
+	savedKey, err := idempotencyUC.Begin(ctx, &entity.IdempotencyKey{Key: "key", RequestHash: "hash"})
+	require.NoError(t, err)
+	require.Nil(t, savedKey)
+}
+
+func TestIdempotency_Lease(t *testing.T) {
+	t.Log("Take over the key of the request which is not completed in time")
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	idempotencyUC := NewIdempotencyUsecase(
+		&fakeIdempotencyRepo{keys: make(map[string]entity.IdempotencyKey)},
+		IdempotencyConfig{TTL: time.Hour, Lease: time.Minute},
+	).(*idempotencyUsecase)
+	idempotencyUC.now = func() time.Time { return now }
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+
+	staleKey := &entity.IdempotencyKey{Key: "key", RequestHash: "hash"}
+	_, err := idempotencyUC.Begin(ctx, staleKey)
+	require.NoError(t, err)
+
+	// lock is expired but the key is still used with another request
+	now = now.Add(2 * time.Minute)
+	_, err = idempotencyUC.Begin(ctx, &entity.IdempotencyKey{Key: "key", RequestHash: "other"})
+	require.ErrorIs(t, err, errors.ErrIdempotencyMismatch)
+
+	key := &entity.IdempotencyKey{Key: "key", RequestHash: "hash"}
+	savedKey, err := idempotencyUC.Begin(ctx, key)
+	require.NoError(t, err)
+	require.Nil(t, savedKey)
+	_, err = idempotencyUC.Begin(ctx, &entity.IdempotencyKey{Key: "key", RequestHash: "hash"})
+	require.ErrorIs(t, err, errors.ErrConflict)
+
+	// stale request cannot release or complete the taken over key
+	require.NoError(t, idempotencyUC.Release(ctx, staleKey))
+	staleKey.ResponseStatus = 201
+	require.ErrorIs(t, idempotencyUC.Complete(ctx, staleKey), errors.ErrNotFound)
+	key.ResponseStatus = 201
+	require.NoError(t, idempotencyUC.Complete(ctx, key))
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/publisher"
+)
+
+var _ OutboxUsecase = (*outboxUsecase)(nil)
+
+// OutboxConfig is a config of outbox relay.
+type OutboxConfig struct {
+	// max number of messages relayed at once
+	BatchSize int
+	// number of failed attempts after which message is dead
+	MaxAttempts int
+	// delay before the first retry, it is doubled for every next one
+	RetryBackoff time.Duration
+	// time for which claimed message is not claimed again (must be longer than batch relay)
+	Lease time.Duration
+}
+
+// OutboxPublisher is a publisher of outbox messages with name
+// which is saved into messages published by it.
+type OutboxPublisher struct {
+	Name      string
+	Publisher publisher.Publisher
+}
+
+// OutboxUsecase implementation.
+type outboxUsecase struct {
+	outboxRepoDB repo.OutboxRepoDB
+	publishers   []OutboxPublisher
+	cfg          OutboxConfig
+	now          func() time.Time
+}
+
+// NewOutboxUsecase returns new OutboxUsecase instance which relays outbox messages
+// to all given publishers.
+func NewOutboxUsecase(
+	outboxRepoDB repo.OutboxRepoDB,
+	cfg OutboxConfig,
+	publishers ...OutboxPublisher,
+) OutboxUsecase {
+	return &outboxUsecase{
+		outboxRepoDB: outboxRepoDB,
+		publishers:   publishers,
+		cfg:          cfg,
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Relay publishes one batch of due pending outbox messages and saves attempts results.
+// Message is published only to publishers which have not published it yet.
+// Failed message is retried with exponential backoff until max attempts are made,
+// then it becomes dead, next messages are not blocked by it.
+// It returns number of processed messages.
+func (u *outboxUsecase) Relay(ctx context.Context) (int, error) {
+	messages, err := u.outboxRepoDB.ClaimPending(ctx, u.cfg.BatchSize, u.cfg.Lease)
+	if err != nil {
+		return 0, errors.Wrap(err, "relay outbox")
+	}
+
+	for i := range messages {
+		u.attempt(ctx, &messages[i])
+		if err := u.outboxRepoDB.UpdateMessage(ctx, &messages[i]); err != nil {
+			return i, errors.Wrap(err, "relay outbox")
+		}
+	}
+	return len(messages), nil
+}
+
+// attempt publishes the message to remaining publishers
+// and sets attempt result into the message.
+func (u *outboxUsecase) attempt(ctx context.Context, outboxMsg *entity.OutboxMessage) {
+	msg := &publisher.Message{
+		ID:   outboxMsg.ID,
+		Type: string(outboxMsg.EventType),
+		Body: outboxMsg.Payload,
+	}
+	var publishErrs []error
+	for _, pub := range u.publishers {
+		if outboxMsg.PublishedTo.Has(pub.Name) {
+			continue
+		}
+		if err := pub.Publisher.Publish(ctx, msg); err != nil {
+			publishErrs = append(publishErrs, fmt.Errorf("%s: %w", pub.Name, err))
+			continue
+		}
+		outboxMsg.PublishedTo = append(outboxMsg.PublishedTo, pub.Name)
+	}
+
+	now := u.now()
+	if err := goerrors.Join(publishErrs...); err != nil {
+		outboxMsg.Attempts++
+		outboxMsg.LastError = truncateError(err)
+		if outboxMsg.Attempts >= u.cfg.MaxAttempts {
+			outboxMsg.Status = entity.OutboxDead
+		} else {
+			outboxMsg.NextAttemptAt = now.Add(retryDelay(u.cfg.RetryBackoff, outboxMsg.Attempts))
+		}
+		return
+	}
+	outboxMsg.Status = entity.OutboxSent
+	outboxMsg.LastError = ""
+	outboxMsg.SentAt = &now
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	goerrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/publisher"
+)
+
+// fakeOutboxRepo is in-memory OutboxRepoDB.
+type fakeOutboxRepo struct {
+	repo.OutboxRepoDB
+	messages []entity.OutboxMessage
+	now      func() time.Time
+}
+
+func (r *fakeOutboxRepo) ClaimPending(
+	_ context.Context,
+	limit int,
+	_ time.Duration,
+) ([]entity.OutboxMessage, error) {
+	messages := []entity.OutboxMessage{}
+	for _, msg := range r.messages {
+		if len(messages) < limit && msg.Status == entity.OutboxPending &&
+			!msg.NextAttemptAt.After(r.now()) {
+			msg.PublishedTo = append(entity.TextArray{}, msg.PublishedTo...)
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (r *fakeOutboxRepo) UpdateMessage(_ context.Context, msg *entity.OutboxMessage) error {
+	for i := range r.messages {
+		if r.messages[i].ID == msg.ID {
+			r.messages[i] = *msg
+		}
+	}
+	return nil
+}
+
+// fakePublisher saves IDs of published messages and fails while err is set.
+type fakePublisher struct {
+	published []string
+	err       error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg *publisher.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, msg.ID)
+	return nil
+}
+
+func TestOutbox_Relay(t *testing.T) {
+	t.Log("Retry failed message only for failed publisher until it is dead")
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := &fakeOutboxRepo{
+		messages: []entity.OutboxMessage{
+			{ID: "failed", Status: entity.OutboxPending, NextAttemptAt: now},
+			{ID: "next", Status: entity.OutboxPending, NextAttemptAt: now},
+		},
+		now: func() time.Time { return now },
+	}
+	webhooks := &fakePublisher{}
+	external := &fakePublisher{err: goerrors.New("publisher is unavailable")}
+	outboxUC := NewOutboxUsecase(outboxRepo,
+		OutboxConfig{BatchSize: 10, MaxAttempts: 3, RetryBackoff: time.Minute},
+		OutboxPublisher{Name: "webhooks", Publisher: webhooks},
+		OutboxPublisher{Name: "nats", Publisher: external})
+	outboxUC.(*outboxUsecase).now = outboxRepo.now
+
+	relayed, err := outboxUC.Relay(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 2, relayed)
+	require.Equal(t, []string{"failed", "next"}, webhooks.published)
+
+	require.Equal(t, now.Add(time.Minute), outboxRepo.messages[0].NextAttemptAt)
+
+	// the second message is retried and sent before the first one
+	external.err = nil
+	outboxRepo.messages[1].NextAttemptAt = now
+	relayed, err = outboxUC.Relay(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, relayed)
+	require.Equal(t, []string{"next"}, external.published)
+	require.Equal(t, entity.OutboxSent, outboxRepo.messages[1].Status)
+	require.Equal(t, entity.TextArray{"webhooks", "nats"}, outboxRepo.messages[1].PublishedTo)
+	require.NotNil(t, outboxRepo.messages[1].SentAt)
+
+	// failed message is retried without webhooks publishing until it is dead
+	external.err = goerrors.New("publisher is unavailable")
+	outboxRepo.messages[0].NextAttemptAt = now
+	for range 2 {
+		relayed, err = outboxUC.Relay(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 1, relayed)
+		outboxRepo.messages[0].NextAttemptAt = now
+	}
+	require.Equal(t, []string{"failed", "next"}, webhooks.published)
+
+	msg := outboxRepo.messages[0]
+	require.Equal(t, entity.OutboxDead, msg.Status)
+	require.Equal(t, 3, msg.Attempts)
+	require.Equal(t, "nats: publisher is unavailable", msg.LastError)
+	require.Equal(t, entity.TextArray{"webhooks"}, msg.PublishedTo)
+	require.Nil(t, msg.SentAt)
+
+	// dead message is not relayed anymore
+	relayed, err = outboxUC.Relay(t.Context())
+	require.NoError(t, err)
+	require.Zero(t, relayed)
+}
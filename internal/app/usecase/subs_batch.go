@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+)
+
+// batchItemFunc handles one item of the batch by its index and fills its result.
+type batchItemFunc func(ctx context.Context, i int, result *entity.SubscriptionBatchResult) error
+
+// CreateBatch creates all subs of the list with multi-row inserts in one transaction
+// and records them into audit log. Every subs is validated like in Create.
+// In atomic mode the first invalid subs or DB error fails the whole batch. Otherwise
+// invalid subs are skipped and if multi-row insert fails every subs is inserted
+// in its own savepoint, so DB errors are put into results of their subs only.
+func (u *subsUsecase) CreateBatch(
+	ctx context.Context,
+	subsList entity.SubscriptionList,
+	atomic bool,
+) (entity.SubscriptionBatchResultList, error) {
+	results, validIndexes, err := prepareCreateBatch(ctx, subsList, atomic)
+	if err != nil {
+		return nil, errors.Wrap(err, "create subs batch")
+	}
+	if len(validIndexes) == 0 {
+		return results, nil
+	}
+
+	if atomic {
+		err = u.createBatch(ctx, subsList, validIndexes)
+	} else {
+		err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if u.createBatch(ctx, subsList, validIndexes) == nil {
+				return nil
+			}
+			for _, i := range validIndexes {
+				results[i].Err = errors.Wrap(u.createBatch(ctx, subsList, []int{i}),
+					"create subs")
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "create subs batch")
+	}
+	for _, i := range validIndexes {
+		if results[i].Err == nil {
+			results[i].ID = subsList[i].ID
+			results[i].Subs = &subsList[i]
+		}
+	}
+	return results, nil
+}
+
+// prepareCreateBatch prepares every subs of the list like in Create and returns
+// batch results with errors of invalid subs and indexes of the valid ones.
+// In atomic mode the first invalid subs error is returned.
+func prepareCreateBatch(
+	ctx context.Context,
+	subsList entity.SubscriptionList,
+	atomic bool,
+) (entity.SubscriptionBatchResultList, []int, error) {
+	results := make(entity.SubscriptionBatchResultList, len(subsList))
+	validIndexes := make([]int, 0, len(subsList))
+	for i := range subsList {
+		results[i].Index = i
+		if err := prepareCreate(ctx, &subsList[i]); err != nil {
+			if atomic {
+				return nil, nil, errors.Wrapf(err, "item %d", i)
+			}
+			results[i].Err = errors.Wrap(err, "create subs")
+			continue
+		}
+		validIndexes = append(validIndexes, i)
+	}
+	return results, validIndexes, nil
+}
+
+// createBatch creates subs of the list with the given indexes in one transaction
+// (savepoint for nested one) and records them into audit log.
+// Created subs are updated in the list.
+func (u *subsUsecase) createBatch(
+	ctx context.Context,
+	subsList entity.SubscriptionList,
+	indexes []int,
+) error {
+	validSubs := make(entity.SubscriptionList, 0, len(indexes))
+	for _, i := range indexes {
+		validSubs = append(validSubs, subsList[i])
+	}
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.subsRepoDB.CreateBatch(ctx, validSubs); err != nil {
+			return err
+		}
+		if err := u.auditCreateBatch(ctx, validSubs); err != nil {
+			return err
+		}
+		subsPointers := make([]*entity.Subscription, len(validSubs))
+		for i := range validSubs {
+			subsPointers[i] = &validSubs[i]
+		}
+		return u.setNextChargeDates(ctx, subsPointers...)
+	})
+	if err != nil {
+		return err
+	}
+	for j, i := range indexes {
+		subsList[i] = validSubs[j]
+	}
+	return nil
+}
+
+// UpdateBatch updates every subs of the list like Update.
+// In atomic mode all updates are made in one transaction and the first failed update
+// fails the whole batch, otherwise every update is made separately.
+func (u *subsUsecase) UpdateBatch(
+	ctx context.Context,
+	updates []entity.SubscriptionUpdate,
+	atomic bool,
+) (entity.SubscriptionBatchResultList, error) {
+	results, err := u.runBatch(ctx, len(updates), atomic,
+		func(ctx context.Context, i int, result *entity.SubscriptionBatchResult) error {
+			result.ID = updates[i].ID
+			updatedSubs, err := u.Update(ctx, &updates[i])
+			result.Subs = updatedSubs
+			return err
+		})
+	return results, errors.Wrap(err, "update subs batch")
+}
+
+// DeleteBatch softly deletes every subs of the list like Delete.
+// In atomic mode all subs are deleted in one transaction and the first failed deletion
+// fails the whole batch, otherwise every subs is deleted separately.
+func (u *subsUsecase) DeleteBatch(
+	ctx context.Context,
+	ids []string,
+	atomic bool,
+) (entity.SubscriptionBatchResultList, error) {
+	results, err := u.runBatch(ctx, len(ids), atomic,
+		func(ctx context.Context, i int, result *entity.SubscriptionBatchResult) error {
+			result.ID = ids[i]
+			return u.Delete(ctx, ids[i])
+		})
+	return results, errors.Wrap(err, "delete subs batch")
+}
+
+// runBatch handles every item of the batch with the given size and returns their results.
+// In atomic mode items are handled in one transaction and the first item error is returned,
+// otherwise item errors are put into their results.
+func (u *subsUsecase) runBatch(
+	ctx context.Context,
+	size int,
+	atomic bool,
+	handleItem batchItemFunc,
+) (entity.SubscriptionBatchResultList, error) {
+	results := make(entity.SubscriptionBatchResultList, size)
+	handleAll := func(ctx context.Context) error {
+		for i := range results {
+			results[i].Index = i
+			err := handleItem(ctx, i, &results[i])
+			if err != nil && atomic {
+				return errors.Wrapf(err, "item %d", i)
+			}
+			results[i].Err = err
+		}
+		return nil
+	}
+
+	if !atomic {
+		return results, handleAll(ctx)
+	}
+	if err := u.txManager.WithinTx(ctx, handleAll); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
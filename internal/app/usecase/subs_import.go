@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/pkg/csvimport"
+	"SubscriptionAggregator/internal/pkg/utils"
+)
+
+const _importBatchSize = 500 // max number of imported subs created at once
+
+var (
+	// fields of subs CSV file (JSON fields of subs creation body)
+	_subsCSVFields = []string{
+		"service_name", "price", "currency", "billing_period",
+		"billing_interval", "billing_day", "user_id", "start_date", "end_date",
+	}
+	// fields of subs CSV file which must have columns
+	_subsCSVRequiredFields = []string{"service_name", "price", "user_id", "start_date"}
+)
+
+// SubsCSVLayout is a layout of CSV file with subs.
+type SubsCSVLayout struct {
+	csvimport.Layout
+	// layout of dates (see time.Parse), YYYY-MM-DD and MM-YYYY dates are read if it is empty
+	DateFormat string
+}
+
+// NewSubsCSVLayout returns layout of subs CSV file with the given delimiter ("tab" for tab),
+// date format and header columns given as "field:column" pairs.
+// Empty delimiter means comma and empty date format means YYYY-MM-DD or MM-YYYY dates.
+// Not mapped fields are read from the columns with the same names.
+func NewSubsCSVLayout(delimiter, dateFormat string, columns []string) (*SubsCSVLayout, error) {
+	layout := &SubsCSVLayout{
+		Layout:     csvimport.Layout{Delimiter: ',', Columns: make(map[string]string)},
+		DateFormat: dateFormat,
+	}
+	switch {
+	case delimiter == "tab":
+		layout.Delimiter = '\t'
+	case delimiter != "":
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == utf8.RuneError || strings.ContainsRune("\"\r\n", r) {
+			return nil, fmt.Errorf("%w: invalid delimiter %q", apperrors.ErrValidateData, delimiter)
+		}
+		layout.Delimiter = r
+	}
+	for _, mapping := range columns {
+		field, column, ok := strings.Cut(mapping, ":")
+		if !ok || column == "" || !slices.Contains(_subsCSVFields, field) {
+			return nil, fmt.Errorf("%w: invalid column mapping %q (expected field:column)",
+				apperrors.ErrValidateData, mapping)
+		}
+		layout.Columns[field] = column
+	}
+	return layout, nil
+}
+
+// subsCSVRow is subs data of the CSV row validated like body of subs creation.
+type subsCSVRow struct {
+	ServiceName     string `validate:"required,max=100"`
+	Price           string `validate:"required,numeric,positive"`
+	Currency        string `validate:"omitempty,currency"`
+	BillingPeriod   string `validate:"omitempty,oneof=weekly monthly quarterly yearly"`
+	BillingInterval int    `validate:"omitempty,min=1,max=100"`
+	UserID          string `validate:"required,uuid4"`
+	StartDate       string `validate:"required"`
+	BillingDay      int    `validate:"omitempty,min=1,max=31"`
+}
+
+// ImportCSV reads subs from the CSV file with the given layout, validates every row
+// like subs creation and creates subs of the valid rows by batches in one transaction
+// (nothing is created in dry run), so the file is not loaded into memory.
+// Errors of the invalid rows are put into the report. Import fails as a whole
+// if the file cannot be read or valid subs cannot be created.
+func (u *subsUsecase) ImportCSV(
+	ctx context.Context,
+	r io.Reader,
+	layout *SubsCSVLayout,
+	dryRun bool,
+) (*entity.SubscriptionImportReport, error) {
+	reader, err := csvimport.NewReader(r, layout.Layout, _subsCSVFields, _subsCSVRequiredFields)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf("%w: %s", apperrors.ErrValidateData, err.Error()),
+			"import subs")
+	}
+
+	report := &entity.SubscriptionImportReport{
+		DryRun: dryRun,
+		Errors: []entity.SubscriptionImportError{},
+	}
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		batch := make(entity.SubscriptionList, 0, _importBatchSize)
+		for {
+			row, err := reader.Read()
+			if goerrors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%w: read csv: %s", apperrors.ErrValidateData, err.Error())
+			}
+
+			report.Total++
+			subs, err := u.parseSubsCSVRow(row, layout.DateFormat)
+			if err == nil {
+				err = errors.Wrap(prepareCreate(ctx, subs), "create subs")
+			}
+			if err != nil {
+				report.Failed++
+				report.Errors = append(report.Errors, entity.SubscriptionImportError{
+					Line:   row.Line,
+					Status: apperrors.ErrorCode(err),
+					Error:  err.Error(),
+				})
+				continue
+			}
+			report.Imported++
+			if batch = append(batch, *subs); len(batch) < _importBatchSize {
+				continue
+			}
+			if err := u.createImported(ctx, batch, dryRun); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		return u.createImported(ctx, batch, dryRun)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "import subs")
+	}
+	return report, nil
+}
+
+// createImported creates batch of imported subs unless it is dry run.
+func (u *subsUsecase) createImported(
+	ctx context.Context,
+	subsList entity.SubscriptionList,
+	dryRun bool,
+) error {
+	if dryRun || len(subsList) == 0 {
+		return nil
+	}
+	indexes := make([]int, len(subsList))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return u.createBatch(ctx, subsList, indexes)
+}
+
+// parseSubsCSVRow validates subs data of the CSV row and returns new subs with it.
+func (u *subsUsecase) parseSubsCSVRow(
+	row *csvimport.Row,
+	dateLayout string,
+) (*entity.Subscription, error) {
+	if row.Err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrValidateData, row.Err.Error())
+	}
+	rowData := &subsCSVRow{
+		ServiceName:   row.Values["service_name"],
+		Price:         row.Values["price"],
+		Currency:      row.Values["currency"],
+		BillingPeriod: row.Values["billing_period"],
+		UserID:        row.Values["user_id"],
+		StartDate:     row.Values["start_date"],
+	}
+	if interval := row.Values["billing_interval"]; interval != "" {
+		billingInterval, err := strconv.Atoi(interval)
+		if err != nil {
+			return nil, fmt.Errorf("%w: billing_interval must be an integer",
+				apperrors.ErrValidateData)
+		}
+		rowData.BillingInterval = billingInterval
+	}
+	if day := row.Values["billing_day"]; day != "" {
+		billingDay, err := strconv.Atoi(day)
+		if err != nil {
+			return nil, fmt.Errorf("%w: billing_day must be an integer", apperrors.ErrValidateData)
+		}
+		rowData.BillingDay = billingDay
+	}
+	if err := u.valid.Validate(rowData); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrValidateData, err.Error())
+	}
+
+	currency := rowData.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+	price, err := entity.ParseMoney(rowData.Price, currency)
+	if err != nil {
+		return nil, err
+	}
+	subs := &entity.Subscription{
+		ServiceName:     rowData.ServiceName,
+		Price:           price,
+		BillingPeriod:   entity.BillingPeriod(rowData.BillingPeriod),
+		BillingInterval: rowData.BillingInterval,
+		UserID:          rowData.UserID,
+		BillingDay:      rowData.BillingDay,
+	}
+	startDate, withDay, err := utils.ParseDateLayout(rowData.StartDate, dateLayout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse start date: %s", apperrors.ErrValidateData, err.Error())
+	}
+	subs.StartDate, subs.StartDatePrecision = &startDate, datePrecision(withDay)
+	if endStr := row.Value("end_date"); endStr != nil && *endStr != "" {
+		endDate, withDay, err := utils.ParseDateLayout(*endStr, dateLayout)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parse end date: %s", apperrors.ErrValidateData, err.Error())
+		}
+		endPrecision := datePrecision(withDay)
+		subs.EndDate, subs.EndDatePrecision = &endDate, &endPrecision
+	}
+	return subs, nil
+}
+
+// datePrecision returns precision of the parsed date with or without day.
+func datePrecision(withDay bool) entity.DatePrecision {
+	if withDay {
+		return entity.PrecisionDay
+	}
+	return entity.PrecisionMonth
+}
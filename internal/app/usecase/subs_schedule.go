@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/repo"
+)
+
+// getSchedules returns charges schedules of the given subs with their pauses.
+// Schedules of subs without charges are nil.
+func getSchedules(
+	ctx context.Context,
+	subsRepoDB repo.SubsRepoDB,
+	subsList []*entity.Subscription,
+) ([]*entity.SubscriptionSchedule, error) {
+	subsIDs := make([]string, len(subsList))
+	for i, subs := range subsList {
+		subsIDs[i] = subs.ID
+	}
+	pauseList, err := subsRepoDB.GetPausesList(ctx, subsIDs)
+	if err != nil {
+		return nil, err
+	}
+	subsPauses := make(map[string]entity.SubscriptionPauseList, len(subsList))
+	for _, pause := range pauseList {
+		subsPauses[pause.SubsID] = append(subsPauses[pause.SubsID], pause)
+	}
+
+	schedules := make([]*entity.SubscriptionSchedule, len(subsList))
+	for i, subs := range subsList {
+		if schedule, ok := entity.NewSubscriptionSchedule(subs, subsPauses[subs.ID]); ok {
+			schedules[i] = schedule
+		}
+	}
+	return schedules, nil
+}
+
+// getUpcomingSchedules returns charges schedules of not deleted subs of the user
+// (of all users for empty user ID) which are not ended before the given date.
+// Subs without charges are skipped.
+func getUpcomingSchedules(
+	ctx context.Context,
+	subsRepoDB repo.SubsRepoDB,
+	userID string,
+	from time.Time,
+) (entity.SubscriptionScheduleList, error) {
+	subsList, err := subsRepoDB.GetUpcoming(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	subsPointers := make([]*entity.Subscription, len(subsList))
+	for i := range subsList {
+		subsPointers[i] = &subsList[i]
+	}
+	schedules, err := getSchedules(ctx, subsRepoDB, subsPointers)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleList := make(entity.SubscriptionScheduleList, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule != nil {
+			scheduleList = append(scheduleList, *schedule)
+		}
+	}
+	return scheduleList, nil
+}
+
+// setNextChargeDates sets next charge dates from the current date of the given subs.
+// Dates are computed by DB like upcoming charges, so they are the same.
+// Deleted subs and subs without more charges have no next charge date.
+func (u *subsUsecase) setNextChargeDates(
+	ctx context.Context,
+	subsList ...*entity.Subscription,
+) error {
+	activeList := make([]*entity.Subscription, 0, len(subsList))
+	activeIDs := make([]string, 0, len(subsList))
+	for _, subs := range subsList {
+		if subs != nil && !subs.DeletedAt.Valid {
+			activeList = append(activeList, subs)
+			activeIDs = append(activeIDs, subs.ID)
+		}
+	}
+	if len(activeList) == 0 {
+		return nil
+	}
+	chargeDates, err := u.subsRepoDB.GetNextChargeDates(ctx, activeIDs, dayStart(u.now()))
+	if err != nil {
+		return errors.Wrap(err, "set next charge dates")
+	}
+
+	for _, subs := range activeList {
+		if chargeDate, ok := chargeDates[subs.ID]; ok {
+			subs.NextChargeDate = &chargeDate
+		}
+	}
+	return nil
+}
+
+// GetUpcoming returns up to limit charges of not deleted subs of the user due in the given
+// number of days from the current date (the current day is included) sorted by date.
+// Charges are computed by DB, so subs are not loaded.
+// Charges of all users are returned for admin and service if user ID is empty.
+// Regular user can get only his own charges.
+func (u *subsUsecase) GetUpcoming(
+	ctx context.Context,
+	userID string,
+	days, limit int,
+) (entity.SubscriptionChargeList, error) {
+	userID, err := scopeUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get upcoming charges")
+	}
+	from := dayStart(u.now())
+	to := from.AddDate(0, 0, days-1)
+	charges, err := u.subsRepoDB.GetCharges(ctx, userID, from, to, limit)
+	return charges, errors.Wrap(err, "get upcoming charges")
+}
+
+// dayStart returns the beginning of the date day in UTC.
+func dayStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -1,62 +1,624 @@
 package usecase
 
 import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
 	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/validator"
 )
 
 var _ SubsUsecase = (*subsUsecase)(nil)
 
 // SubsUsecase implementation.
 type subsUsecase struct {
-	subsRepoDB repo.SubsRepoDB
+	txManager   repo.TxManager
+	subsRepoDB  repo.SubsRepoDB
+	auditRepoDB repo.AuditRepoDB
+	valid       validator.Validator // validator of imported rows
+	now         func() time.Time
 }
 
 // NewSubsUsecase returns new SubsUsecase instance.
-func NewSubsUsecase(subsRepoDB repo.SubsRepoDB) SubsUsecase {
+func NewSubsUsecase(
+	txManager repo.TxManager,
+	subsRepoDB repo.SubsRepoDB,
+	auditRepoDB repo.AuditRepoDB,
+	valid validator.Validator,
+) SubsUsecase {
 	return &subsUsecase{
-		subsRepoDB: subsRepoDB,
+		txManager:   txManager,
+		subsRepoDB:  subsRepoDB,
+		auditRepoDB: auditRepoDB,
+		valid:       valid,
+		now:         func() time.Time { return time.Now().UTC() },
 	}
 }
 
-// Create creates new subs.
+// Create creates new subs and records it into audit log.
 // All required fields must be presented. ID is auto-generated.
-func (u *subsUsecase) Create(subs *entity.Subscription) error {
-	subs.ID = uuid.NewString()
-	err := u.subsRepoDB.Create(subs)
+// Regular user can create subs only for himself.
+func (u *subsUsecase) Create(ctx context.Context, subs *entity.Subscription) error {
+	if err := prepareCreate(ctx, subs); err != nil {
+		return errors.Wrap(err, "create subs")
+	}
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.subsRepoDB.Create(ctx, subs); err != nil {
+			return err
+		}
+		if err := u.audit(ctx, entity.AuditCreate, nil, subs); err != nil {
+			return err
+		}
+		return u.setNextChargeDates(ctx, subs)
+	})
 	return errors.Wrap(err, "create subs")
 }
 
+// prepareCreate scopes new subs to the authenticated user, sets its defaults,
+// validates it and sets its ID.
+func prepareCreate(ctx context.Context, subs *entity.Subscription) error {
+	userID, err := scopeUserID(ctx, subs.UserID)
+	if err != nil {
+		return err
+	}
+	subs.UserID = userID
+	subs.ServiceName = entity.NormalizeServiceName(subs.ServiceName)
+	subs.SetDefaults()
+	if err := subs.Validate(); err != nil {
+		return err
+	}
+	subs.ID = uuid.NewString()
+	return nil
+}
+
 // Get gets one subs by given ID.
-func (u *subsUsecase) GetByID(id string) (*entity.Subscription, error) {
-	subs, err := u.subsRepoDB.GetByID(id)
-	return subs, errors.Wrap(err, "get subs by id")
+// Subs of another user is not found for regular user.
+// Only admin can get deleted subs.
+func (u *subsUsecase) GetByID(
+	ctx context.Context,
+	id string,
+	includeDeleted bool,
+) (*entity.Subscription, error) {
+	if includeDeleted {
+		if _, err := requireAdmin(ctx); err != nil {
+			return nil, errors.Wrap(err, "get subs by id")
+		}
+	}
+	subs, err := u.getOwnByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, errors.Wrap(err, "get subs by id")
+	}
+	if err := u.setNextChargeDates(ctx, subs); err != nil {
+		return nil, errors.Wrap(err, "get subs by id")
+	}
+	return subs, nil
 }
 
-// Update updates all subs fields with given data by giving book ID.
+// Update updates all subs fields with given data by giving book ID
+// and records the change into audit log.
 // ID and all required fields must be presented.
-func (u *subsUsecase) Update(subs *entity.SubscriptionUpdate) (*entity.Subscription, error) {
-	updatedSubs, err := u.subsRepoDB.Update(subs)
-	return updatedSubs, errors.Wrap(err, "update subs")
+// The current subs is locked and validated with the update applied as a whole.
+// Regular user can update only his subs and cannot pass them to another user.
+func (u *subsUsecase) Update(
+	ctx context.Context,
+	subs *entity.SubscriptionUpdate,
+) (*entity.Subscription, error) {
+	var updatedSubs *entity.Subscription
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		currentSubs, err := u.lockOwnByID(ctx, subs.ID)
+		if err != nil {
+			return err
+		}
+		if subs.UserID != nil {
+			if _, err := scopeUserID(ctx, *subs.UserID); err != nil {
+				return err
+			}
+		}
+		if subs.ServiceName != nil {
+			serviceName := entity.NormalizeServiceName(*subs.ServiceName)
+			subs.ServiceName = &serviceName
+		}
+		if err := resolveUpdatePrice(subs, currentSubs); err != nil {
+			return err
+		}
+		subs.SetDefaults(currentSubs)
+		// validate the updated subs as a whole
+		if err := subs.Merge(currentSubs).Validate(); err != nil {
+			return err
+		}
+		if updatedSubs, err = u.subsRepoDB.Update(ctx, subs); err != nil {
+			return err
+		}
+		if err := u.audit(ctx, entity.AuditUpdate, currentSubs, updatedSubs); err != nil {
+			return err
+		}
+		return u.setNextChargeDates(ctx, updatedSubs)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "update subs")
+	}
+	return updatedSubs, nil
 }
 
-// Delete deletes subs by its ID.
-func (u *subsUsecase) Delete(id string) error {
-	err := u.subsRepoDB.Delete(id)
+// resolveUpdatePrice sets price amount of the update in minor units of its currency.
+// If only one of price and currency is given the other one is taken from the current subs.
+func resolveUpdatePrice(subs *entity.SubscriptionUpdate, currentSubs *entity.Subscription) error {
+	if subs.Price == nil && subs.Currency == nil {
+		return nil
+	}
+
+	price, currency := currentSubs.Price.String(), currentSubs.Price.Currency
+	if subs.Price != nil {
+		price = *subs.Price
+	}
+	if subs.Currency != nil {
+		currency = *subs.Currency
+	}
+
+	money, err := entity.ParseMoney(price, currency)
+	if err != nil {
+		return err
+	}
+	subs.PriceAmount = &money.Amount
+	return nil
+}
+
+// Delete softly deletes subs by its ID and records it into audit log.
+// The subs is locked, so it is deleted and recorded only once by concurrent requests.
+// Deleting of already deleted subs does nothing (delete is idempotent),
+// but ErrNotFound is returned for subs which has never existed.
+// Subs of another user is not found for regular user.
+func (u *subsUsecase) Delete(ctx context.Context, id string) error {
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		subs, err := u.lockOwnByID(ctx, id)
+		if goerrors.Is(err, apperrors.ErrNotFound) {
+			// deleted subs is not locked, so check whether it exists at all
+			_, err = u.getOwnByID(ctx, id, true)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if err := u.subsRepoDB.Delete(ctx, id); err != nil {
+			return err
+		}
+		return u.audit(ctx, entity.AuditDelete, subs, nil)
+	})
 	return errors.Wrap(err, "delete subs")
 }
 
-// GetAll gets all subs.
-func (u *subsUsecase) GetAll() (entity.SubscriptionList, error) {
-	subsList, err := u.subsRepoDB.GetList()
-	return subsList, errors.Wrap(err, "get all subs")
+// Restore restores deleted subs by its ID and records it into audit log.
+// It returns ErrNotFound if subs is not deleted.
+// Regular user can restore only his subs.
+func (u *subsUsecase) Restore(ctx context.Context, id string) (*entity.Subscription, error) {
+	var restoredSubs *entity.Subscription
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		deletedSubs, err := u.getOwnByID(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		if restoredSubs, err = u.subsRepoDB.Restore(ctx, id); err != nil {
+			return err
+		}
+		if err := u.audit(ctx, entity.AuditRestore, deletedSubs, restoredSubs); err != nil {
+			return err
+		}
+		return u.setNextChargeDates(ctx, restoredSubs)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "restore subs")
+	}
+	return restoredSubs, nil
+}
+
+// Cancel ends subs in the given month or day (the current month by default)
+// with the reason and records the change into audit log.
+// Subs cannot be canceled in the past or after its end or if it is already ended.
+// The current subs is locked, so it is not changed concurrently while it is checked.
+func (u *subsUsecase) Cancel(
+	ctx context.Context,
+	cancel *entity.SubscriptionCancel,
+) (*entity.Subscription, error) {
+	now := u.now()
+	today := dayStart(now)
+	endDate, precision := monthStart(now), entity.PrecisionMonth
+	if cancel.EndDate != nil {
+		endDate = monthStart(*cancel.EndDate)
+		if cancel.EndDatePrecision == entity.PrecisionDay {
+			endDate, precision = *cancel.EndDate, entity.PrecisionDay
+		}
+	}
+	lastDay := precision.LastDay(endDate)
+	if lastDay.Before(today) {
+		return nil, errors.Wrap(fmt.Errorf("%w: end date is in the past",
+			apperrors.ErrValidateData), "cancel subs")
+	}
+
+	var canceledSubs *entity.Subscription
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		currentSubs, err := u.lockOwnByID(ctx, cancel.ID)
+		if err != nil {
+			return err
+		}
+		currentLastDay, ended := currentSubs.LastDay()
+		if ended && currentLastDay.Before(today) {
+			return fmt.Errorf("%w: subs is already ended", apperrors.ErrConflict)
+		}
+		if lastDay.Before(*currentSubs.StartDate) {
+			return fmt.Errorf("%w: end date is before start date", apperrors.ErrValidateData)
+		}
+		if ended && lastDay.After(currentLastDay) {
+			return fmt.Errorf("%w: subs already ends before end date", apperrors.ErrValidateData)
+		}
+
+		canceledSubs, err = u.subsRepoDB.Update(ctx, &entity.SubscriptionUpdate{
+			ID:               cancel.ID,
+			EndDate:          &endDate,
+			EndDatePrecision: &precision,
+			CanceledAt:       &now,
+			CancelReason:     &cancel.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		if err := u.audit(ctx, entity.AuditUpdate, currentSubs, canceledSubs); err != nil {
+			return err
+		}
+		return u.setNextChargeDates(ctx, canceledSubs)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cancel subs")
+	}
+	return canceledSubs, nil
+}
+
+// Pause pauses subs in the given months. Pause starts in the current month by default
+// and lasts until resume if its end is not set. ID is auto-generated.
+// Pause cannot start in the past or after the subs end and cannot overlap another one.
+// Pause is recorded into audit log.
+func (u *subsUsecase) Pause(ctx context.Context, pause *entity.SubscriptionPause) error {
+	now := u.now()
+	currentMonth := monthStart(now)
+	if pause.StartDate == nil {
+		pause.StartDate = &currentMonth
+	}
+	if pause.StartDate.Before(currentMonth) {
+		return errors.Wrap(fmt.Errorf("%w: pause start date is in the past",
+			apperrors.ErrValidateData), "pause subs")
+	}
+	pause.ID = uuid.NewString()
+	pause.CreatedAt = now
+
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		subs, err := u.lockOwnByID(ctx, pause.SubsID)
+		if err != nil {
+			return err
+		}
+		if lastDay, ok := subs.LastDay(); ok && pause.StartDate.After(lastDay) {
+			return fmt.Errorf("%w: subs ends before pause start", apperrors.ErrValidateData)
+		}
+		pausesBefore, err := u.subsRepoDB.GetPauses(ctx, subs.ID)
+		if err != nil {
+			return err
+		}
+		if err := u.subsRepoDB.CreatePause(ctx, pause); err != nil {
+			return err
+		}
+		return u.auditPauses(ctx, entity.AuditPause, subs, pausesBefore)
+	})
+	return errors.Wrap(err, "pause subs")
+}
+
+// Resume resumes paused subs from the current month: pauses planned for the following
+// months are canceled and the current pause ends in the previous month.
+// Resume is recorded into audit log. It returns ErrConflict if subs is not paused.
+func (u *subsUsecase) Resume(ctx context.Context, id string) error {
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		subs, err := u.lockOwnByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		pausesBefore, err := u.subsRepoDB.GetPauses(ctx, id)
+		if err != nil {
+			return err
+		}
+		changed, err := u.subsRepoDB.Resume(ctx, id, monthStart(u.now()))
+		if err != nil {
+			return err
+		}
+		if changed == 0 {
+			return fmt.Errorf("%w: subs is not paused", apperrors.ErrConflict)
+		}
+		return u.auditPauses(ctx, entity.AuditResume, subs, pausesBefore)
+	})
+	return errors.Wrap(err, "resume subs")
+}
+
+// GetPauses returns all pauses of the subs.
+// Subs of another user is not found for regular user.
+func (u *subsUsecase) GetPauses(
+	ctx context.Context,
+	id string,
+) (entity.SubscriptionPauseList, error) {
+	if _, err := u.getOwnByID(ctx, id, false); err != nil {
+		return nil, errors.Wrap(err, "get subs pauses")
+	}
+	pauseList, err := u.subsRepoDB.GetPauses(ctx, id)
+	return pauseList, errors.Wrap(err, "get subs pauses")
+}
+
+// monthStart returns the first day of the date month.
+func monthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// audit records subs change made by the authenticated user into audit log.
+// State before the change is nil for create and state after the change is nil for delete.
+func (u *subsUsecase) audit(
+	ctx context.Context,
+	action entity.AuditAction,
+	before, after *entity.Subscription,
+) error {
+	entry, err := newAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	return u.auditRepoDB.Create(ctx, entry)
+}
+
+// auditPauses records pause or resume of the subs made by the authenticated user
+// into audit log with subs pauses before and after it.
+func (u *subsUsecase) auditPauses(
+	ctx context.Context,
+	action entity.AuditAction,
+	subs *entity.Subscription,
+	pausesBefore entity.SubscriptionPauseList,
+) error {
+	pausesAfter, err := u.subsRepoDB.GetPauses(ctx, subs.ID)
+	if err != nil {
+		return err
+	}
+	entry, err := newAuditEntry(ctx, action, subs, subs)
+	if err != nil {
+		return err
+	}
+	if entry.Changes, err = entity.PausesAuditChanges(pausesBefore, pausesAfter); err != nil {
+		return errors.Wrap(err, "audit")
+	}
+	return u.auditRepoDB.Create(ctx, entry)
+}
+
+// auditCreateBatch records creation of all subs of the list into audit log.
+func (u *subsUsecase) auditCreateBatch(
+	ctx context.Context,
+	subsList entity.SubscriptionList,
+) error {
+	entries := make(entity.AuditEntryList, 0, len(subsList))
+	for i := range subsList {
+		entry, err := newAuditEntry(ctx, entity.AuditCreate, nil, &subsList[i])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, *entry)
+	}
+	return u.auditRepoDB.CreateBatch(ctx, entries)
 }
 
-// GetSum returns sum of subs prices filtered by filter.
-func (u *subsUsecase) GetSum(filter *entity.SubscriptionSumFilter) (int, error) {
-	totalPrice, err := u.subsRepoDB.GetSum(filter)
-	return totalPrice, errors.Wrap(err, "get subs prices sum")
+// newAuditEntry returns audit entry with subs change made by the authenticated user.
+func newAuditEntry(
+	ctx context.Context,
+	action entity.AuditAction,
+	before, after *entity.Subscription,
+) (*entity.AuditEntry, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	beforeJSON, afterJSON, changes, err := entity.SubsAuditStates(before, after)
+	if err != nil {
+		return nil, errors.Wrap(err, "audit")
+	}
+	subs := after
+	if subs == nil {
+		subs = before
+	}
+
+	entry := &entity.AuditEntry{
+		ID:        uuid.NewString(),
+		SubsID:    subs.ID,
+		UserID:    subs.UserID,
+		Action:    action,
+		ActorRole: user.Role,
+		RequestID: entity.RequestIDFromContext(ctx),
+		Before:    beforeJSON,
+		After:     afterJSON,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if user.ID != "" {
+		entry.ActorID = &user.ID
+	}
+	if user.APIKeyID != "" {
+		entry.APIKeyID = &user.APIKeyID
+	}
+	return entry, nil
+}
+
+// GetHistory returns audit log of the subs filtered by filter (subs ID is required).
+// History of deleted subs is available too.
+// Regular user gets only history of subs while they belonged to him.
+func (u *subsUsecase) GetHistory(
+	ctx context.Context,
+	filter *entity.AuditFilter,
+) (entity.AuditEntryList, error) {
+	userID, err := scopeUserID(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "get subs history")
+	}
+	filter.UserID = userID
+	entryList, err := u.auditRepoDB.GetList(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "get subs history")
+	}
+	// check access to subs without history (e.g. created before audit)
+	if len(entryList) == 0 && filter.Offset == 0 {
+		if _, err := u.getOwnByID(ctx, filter.SubsID, true); err != nil {
+			return nil, errors.Wrap(err, "get subs history")
+		}
+	}
+	return entryList, nil
+}
+
+// lockOwnByID gets not deleted subs by given ID and locks it until the end of the transaction.
+// It returns ErrNotFound if subs of another user is requested by regular user.
+func (u *subsUsecase) lockOwnByID(ctx context.Context, id string) (*entity.Subscription, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := u.subsRepoDB.LockByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasAllUsersAccess() && subs.UserID != user.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	return subs, nil
+}
+
+// getOwnByID gets subs by given ID (deleted one only if includeDeleted is true).
+// It returns ErrNotFound if subs of another user is requested by regular user.
+func (u *subsUsecase) getOwnByID(
+	ctx context.Context,
+	id string,
+	includeDeleted bool,
+) (*entity.Subscription, error) {
+	user, err := authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := u.subsRepoDB.GetByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasAllUsersAccess() && subs.UserID != user.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	return subs, nil
+}
+
+// GetAll gets page of subs filtered, sorted and paginated by filter.
+// Filter is scoped to the authenticated user if he has no access to all users.
+// Only admin can include deleted subs.
+func (u *subsUsecase) GetAll(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+) (*entity.SubscriptionPage, error) {
+	if err := scopeListFilter(ctx, filter); err != nil {
+		return nil, errors.Wrap(err, "get all subs")
+	}
+	subsPage, err := u.subsRepoDB.GetList(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "get all subs")
+	}
+	subsList := make([]*entity.Subscription, len(subsPage.Items))
+	for i := range subsPage.Items {
+		subsList[i] = &subsPage.Items[i]
+	}
+	if err := u.setNextChargeDates(ctx, subsList...); err != nil {
+		return nil, errors.Wrap(err, "get all subs")
+	}
+	return subsPage, nil
+}
+
+// Export checks access to subs filtered and sorted by filter like GetAll
+// and returns func streaming them with values computed for export (pagination is ignored).
+// The func can be called with another context, e.g. after response headers are sent.
+func (u *subsUsecase) Export(
+	ctx context.Context,
+	filter *entity.SubscriptionListFilter,
+) (SubsExportFunc, error) {
+	if err := scopeListFilter(ctx, filter); err != nil {
+		return nil, errors.Wrap(err, "export subs")
+	}
+	return func(ctx context.Context, write func(subs *entity.SubscriptionExport) error) error {
+		return errors.Wrap(u.subsRepoDB.Export(ctx, filter, write), "export subs")
+	}, nil
+}
+
+// scopeListFilter scopes subs list filter to the authenticated user
+// if he has no access to all users. Only admin can include deleted subs.
+// Service name is normalized like the saved ones.
+func scopeListFilter(ctx context.Context, filter *entity.SubscriptionListFilter) error {
+	if filter.IncludeDeleted {
+		if _, err := requireAdmin(ctx); err != nil {
+			return err
+		}
+	}
+	userID, err := scopeUserID(ctx, filter.UserID)
+	if err != nil {
+		return err
+	}
+	filter.UserID = userID
+	filter.ServiceName = entity.NormalizeServiceName(filter.ServiceName)
+	return nil
+}
+
+// GetSum returns sum of subs prices filtered by filter
+// converted into the filter currency with exchange rates used for it.
+// Filter is scoped to the authenticated user if he has no access to all users.
+func (u *subsUsecase) GetSum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumFilter,
+) (*entity.SubscriptionSum, error) {
+	if err := scopeSumFilter(ctx, filter); err != nil {
+		return nil, errors.Wrap(err, "get subs prices sum")
+	}
+	subsSum, err := u.subsRepoDB.GetSum(ctx, filter)
+	return subsSum, errors.Wrap(err, "get subs prices sum")
+}
+
+// GetGroupedSum returns sum of subs prices filtered by filter grouped by filter group field.
+// Filter is scoped to the authenticated user if he has no access to all users.
+func (u *subsUsecase) GetGroupedSum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumGroupFilter,
+) (entity.SubscriptionSumGroupList, error) {
+	if err := scopeSumFilter(ctx, &filter.SubscriptionSumFilter); err != nil {
+		return nil, errors.Wrap(err, "get subs grouped sum")
+	}
+	groupList, err := u.subsRepoDB.GetGroupedSum(ctx, filter)
+	return groupList, errors.Wrap(err, "get subs grouped sum")
+}
+
+// GetMonthlySum returns subs costs for every month of the period filtered by filter.
+// Filter is scoped to the authenticated user if he has no access to all users.
+func (u *subsUsecase) GetMonthlySum(
+	ctx context.Context,
+	filter *entity.SubscriptionSumFilter,
+) (entity.SubscriptionMonthlySumList, error) {
+	if err := scopeSumFilter(ctx, filter); err != nil {
+		return nil, errors.Wrap(err, "get subs monthly sum")
+	}
+	monthlySumList, err := u.subsRepoDB.GetMonthlySum(ctx, filter)
+	return monthlySumList, errors.Wrap(err, "get subs monthly sum")
+}
+
+// scopeSumFilter scopes subs sum filter to the authenticated user
+// if he has no access to all users. Service name is normalized like the saved ones.
+func scopeSumFilter(ctx context.Context, filter *entity.SubscriptionSumFilter) error {
+	userID, err := scopeUserID(ctx, filter.UserID)
+	if err != nil {
+		return err
+	}
+	filter.UserID = userID
+	filter.ServiceName = entity.NormalizeServiceName(filter.ServiceName)
+	return nil
 }
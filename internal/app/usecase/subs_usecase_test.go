@@ -0,0 +1,743 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/validator"
+)
+
+const _testUserID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+// fakeTxManager runs functions without transactions.
+type fakeTxManager struct{}
+
+func (fakeTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeSubsRepo is in-memory SubsRepoDB with subs used by subs changes.
+type fakeSubsRepo struct {
+	repo.SubsRepoDB
+	subs    map[string]*entity.Subscription
+	pauses  entity.SubscriptionPauseList
+	batches int      // number of created batches
+	broken  string   // service name of subs which cannot be inserted
+	locked  []string // IDs of locked subs
+}
+
+func (r *fakeSubsRepo) GetByID(
+	_ context.Context,
+	id string,
+	includeDeleted bool,
+) (*entity.Subscription, error) {
+	subs, ok := r.subs[id]
+	if !ok || (subs.DeletedAt.Valid && !includeDeleted) {
+		return nil, errors.ErrNotFound
+	}
+	subsCopy := *subs
+	return &subsCopy, nil
+}
+
+func (r *fakeSubsRepo) CreateBatch(_ context.Context, subsList entity.SubscriptionList) error {
+	for _, subs := range subsList {
+		if r.broken != "" && subs.ServiceName == r.broken {
+			return errors.ErrConflict
+		}
+	}
+	r.batches++
+	for i := range subsList {
+		subs := subsList[i]
+		r.subs[subs.ID] = &subs
+	}
+	return nil
+}
+
+func (r *fakeSubsRepo) LockByID(ctx context.Context, id string) (*entity.Subscription, error) {
+	r.locked = append(r.locked, id)
+	return r.GetByID(ctx, id, false)
+}
+
+func (r *fakeSubsRepo) Update(
+	ctx context.Context,
+	update *entity.SubscriptionUpdate,
+) (*entity.Subscription, error) {
+	subs, ok := r.subs[update.ID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	*subs = *update.Merge(subs)
+	return r.GetByID(ctx, update.ID, false)
+}
+
+func (r *fakeSubsRepo) Export(
+	_ context.Context,
+	filter *entity.SubscriptionListFilter,
+	fn func(subs *entity.SubscriptionExport) error,
+) error {
+	for _, subs := range r.subs {
+		if (filter.UserID != "" && subs.UserID != filter.UserID) ||
+			(filter.ServiceName != "" && subs.ServiceName != filter.ServiceName) {
+			continue
+		}
+		if err := fn(&entity.SubscriptionExport{Subscription: *subs}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeSubsRepo) GetSum(
+	_ context.Context,
+	filter *entity.SubscriptionSumFilter,
+) (*entity.SubscriptionSum, error) {
+	return &entity.SubscriptionSum{Filter: filter}, nil
+}
+
+// GetCharges returns charges of the user subs computed by their schedules without pauses.
+func (r *fakeSubsRepo) GetCharges(
+	ctx context.Context,
+	userID string,
+	from, to time.Time,
+	limit int,
+) (entity.SubscriptionChargeList, error) {
+	subsList, err := r.GetUpcoming(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	charges := entity.SubscriptionChargeList{}
+	for i := range subsList {
+		schedule, ok := entity.NewSubscriptionSchedule(&subsList[i], nil)
+		if !ok {
+			continue
+		}
+		for _, chargeDate := range schedule.Charges(from, to) {
+			charges = append(charges, entity.SubscriptionCharge{
+				Date:        chargeDate,
+				SubsID:      schedule.ID,
+				ServiceName: schedule.ServiceName,
+				UserID:      schedule.UserID,
+				Amount:      schedule.Price,
+			})
+		}
+	}
+	slices.SortFunc(charges, func(a, b entity.SubscriptionCharge) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ServiceName, b.ServiceName))
+	})
+	return charges[:min(len(charges), limit)], nil
+}
+
+// GetNextChargeDates returns next charge dates computed by schedules of the subs with pauses.
+func (r *fakeSubsRepo) GetNextChargeDates(
+	ctx context.Context,
+	ids []string,
+	from time.Time,
+) (map[string]time.Time, error) {
+	pauseList, err := r.GetPausesList(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	chargeDates := make(map[string]time.Time, len(ids))
+	for _, id := range ids {
+		subs, err := r.GetByID(ctx, id, false)
+		if err != nil {
+			continue
+		}
+		pauses := slices.DeleteFunc(slices.Clone(pauseList), func(p entity.SubscriptionPause) bool {
+			return p.SubsID != id
+		})
+		schedule, ok := entity.NewSubscriptionSchedule(subs, pauses)
+		if !ok {
+			continue
+		}
+		if chargeDate, ok := schedule.NextCharge(from); ok {
+			chargeDates[id] = chargeDate
+		}
+	}
+	return chargeDates, nil
+}
+
+func (r *fakeSubsRepo) Delete(_ context.Context, id string) error {
+	r.subs[id].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakeSubsRepo) CreatePause(_ context.Context, pause *entity.SubscriptionPause) error {
+	r.pauses = append(r.pauses, *pause)
+	return nil
+}
+
+func (r *fakeSubsRepo) GetPauses(
+	_ context.Context,
+	subsID string,
+) (entity.SubscriptionPauseList, error) {
+	pauseList := entity.SubscriptionPauseList{}
+	for _, pause := range r.pauses {
+		if pause.SubsID == subsID {
+			pauseList = append(pauseList, pause)
+		}
+	}
+	return pauseList, nil
+}
+
+// Resume deletes pauses starting from the month and ends the pause covering it.
+func (r *fakeSubsRepo) Resume(_ context.Context, subsID string, month time.Time) (int, error) {
+	changed := 0
+	r.pauses = slices.DeleteFunc(r.pauses, func(pause entity.SubscriptionPause) bool {
+		deleted := pause.SubsID == subsID && !pause.StartDate.Before(month)
+		if deleted {
+			changed++
+		}
+		return deleted
+	})
+	for i := range r.pauses {
+		pause := &r.pauses[i]
+		if pause.SubsID == subsID && (pause.EndDate == nil || !pause.EndDate.Before(month)) {
+			endDate := month.AddDate(0, -1, 0)
+			pause.EndDate = &endDate
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// fakeAuditRepo is in-memory AuditRepoDB.
+type fakeAuditRepo struct {
+	repo.AuditRepoDB
+	entries entity.AuditEntryList
+}
+
+func (r *fakeAuditRepo) Create(_ context.Context, entry *entity.AuditEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeAuditRepo) CreateBatch(_ context.Context, entries entity.AuditEntryList) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+// newTestSubsUsecase returns usecase with fake repos containing the given subs
+// (with defaults like in DB) and context of the subs owner. Usecase time is 15-07-2025.
+func newTestSubsUsecase(
+	subsList ...entity.Subscription,
+) (context.Context, *subsUsecase, *fakeAuditRepo) {
+	subsRepo := &fakeSubsRepo{subs: make(map[string]*entity.Subscription)}
+	for i := range subsList {
+		subsList[i].SetDefaults()
+		subsRepo.subs[subsList[i].ID] = &subsList[i]
+	}
+	auditRepo := &fakeAuditRepo{}
+
+	subsUC := NewSubsUsecase(fakeTxManager{}, subsRepo, auditRepo,
+		validator.New()).(*subsUsecase)
+	subsUC.now = func() time.Time { return time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC) }
+	ctx := entity.ContextWithAuthUser(context.Background(),
+		&entity.AuthUser{ID: _testUserID, Role: entity.RoleUser})
+	return ctx, subsUC, auditRepo
+}
+
+// month returns the first day of the month of 2025.
+func month(m time.Month) *time.Time {
+	date := time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+func TestSubs_Cancel(t *testing.T) {
+	t.Log("Cancel subs immediately and at the future month")
+
+	subs := entity.Subscription{ID: "subs", UserID: _testUserID, StartDate: month(time.March)}
+	ctx, subsUC, auditRepo := newTestSubsUsecase(subs)
+
+	canceledSubs, err := subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:      subs.ID,
+		EndDate: month(time.September),
+		Reason:  "Too expensive",
+	})
+	require.NoError(t, err)
+	require.Equal(t, month(time.September), canceledSubs.EndDate)
+	require.Equal(t, "Too expensive", canceledSubs.CancelReason)
+	require.NotNil(t, canceledSubs.CanceledAt)
+	require.Len(t, auditRepo.entries, 1)
+	require.Equal(t, entity.AuditUpdate, auditRepo.entries[0].Action)
+	require.Equal(t, []string{subs.ID}, subsUC.subsRepoDB.(*fakeSubsRepo).locked)
+
+	canceledSubs, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{ID: subs.ID})
+	require.NoError(t, err)
+	require.Equal(t, month(time.July), canceledSubs.EndDate)
+
+	// cancel cannot move end date
+	_, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:      subs.ID,
+		EndDate: month(time.August),
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+	_, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:      subs.ID,
+		EndDate: month(time.June),
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+}
+
+func TestSubs_PauseResume(t *testing.T) {
+	t.Log("Pause and resume locked subs with audit records")
+
+	subs := entity.Subscription{ID: "subs", UserID: _testUserID, StartDate: month(time.March)}
+	ctx, subsUC, auditRepo := newTestSubsUsecase(subs)
+	subsRepo := subsUC.subsRepoDB.(*fakeSubsRepo)
+
+	require.NoError(t, subsUC.Pause(ctx, &entity.SubscriptionPause{SubsID: subs.ID}))
+	require.Len(t, auditRepo.entries, 1)
+	require.Equal(t, entity.AuditPause, auditRepo.entries[0].Action)
+	require.Equal(t, subs.ID, auditRepo.entries[0].SubsID)
+	require.JSONEq(t, `{"pauses": {"old": [], "new": [{"id": "`+subsRepo.pauses[0].ID+
+		`", "subs_id": "subs", "start_date": "2025-07-01T00:00:00Z",`+
+		` "created_at": "2025-07-15T12:00:00Z"}]}}`, string(auditRepo.entries[0].Changes))
+
+	// resume cancels the pause of the current month
+	pauses := slices.Clone(subsRepo.pauses)
+	require.NoError(t, subsUC.Resume(ctx, subs.ID))
+	require.Empty(t, subsRepo.pauses)
+	require.Len(t, auditRepo.entries, 2)
+	require.Equal(t, entity.AuditResume, auditRepo.entries[1].Action)
+	changes, err := entity.PausesAuditChanges(pauses, entity.SubscriptionPauseList{})
+	require.NoError(t, err)
+	require.JSONEq(t, string(changes), string(auditRepo.entries[1].Changes))
+	require.Equal(t, []string{subs.ID, subs.ID}, subsRepo.locked)
+
+	// resume of not paused subs is not recorded
+	require.ErrorIs(t, subsUC.Resume(ctx, subs.ID), errors.ErrConflict)
+	require.Len(t, auditRepo.entries, 2)
+}
+
+func TestSubs_UpdateDates(t *testing.T) {
+	t.Log("Update subs dates validated against the stored ones")
+
+	subs := entity.Subscription{
+		ID:          "subs",
+		ServiceName: "Ivi",
+		Price:       entity.Money{Amount: 19900, Currency: entity.DefaultCurrency},
+		UserID:      _testUserID,
+		StartDate:   month(time.March),
+		EndDate:     month(time.May),
+	}
+	ctx, subsUC, auditRepo := newTestSubsUsecase(subs)
+
+	// start date after the stored end date
+	_, err := subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:        subs.ID,
+		StartDate: month(time.June),
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+	// stored start date after end date
+	_, err = subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:      subs.ID,
+		EndDate: month(time.February),
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+	require.Empty(t, auditRepo.entries)
+
+	// end date is cleared, so start date can be moved forward
+	updatedSubs, err := subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:           subs.ID,
+		StartDate:    month(time.June),
+		ClearEndDate: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, month(time.June), updatedSubs.StartDate)
+	require.Nil(t, updatedSubs.EndDate)
+	require.Len(t, auditRepo.entries, 1)
+}
+
+func TestSubs_UpdateValidation(t *testing.T) {
+	t.Log("Update subs with invalid fields and normalized service name")
+
+	subs := entity.Subscription{
+		ID:          "subs",
+		ServiceName: "Ivi",
+		Price:       entity.Money{Amount: 19900, Currency: entity.DefaultCurrency},
+		UserID:      _testUserID,
+		StartDate:   month(time.March),
+	}
+	ctx, subsUC, _ := newTestSubsUsecase(subs)
+
+	serviceName, price := "  ", "0"
+	_, err := subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:          subs.ID,
+		ServiceName: &serviceName,
+		Price:       &price,
+		EndDate:     month(time.January),
+	})
+	var validationErr *errors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []errors.FieldError{
+		{Field: "service_name", Message: "must not be empty"},
+		{Field: "price", Message: "must be positive"},
+		{Field: "end_date", Message: "must not be before start_date"},
+	}, validationErr.Fields)
+
+	serviceName = "  Yandex   Plus "
+	updatedSubs, err := subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:          subs.ID,
+		ServiceName: &serviceName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Yandex Plus", updatedSubs.ServiceName)
+}
+
+func TestSubs_CancelEnded(t *testing.T) {
+	t.Log("Try to cancel ended subs")
+
+	subs := entity.Subscription{
+		ID:        "subs",
+		UserID:    _testUserID,
+		StartDate: month(time.March),
+		EndDate:   month(time.May),
+	}
+	ctx, subsUC, _ := newTestSubsUsecase(subs)
+
+	_, err := subsUC.Cancel(ctx, &entity.SubscriptionCancel{ID: subs.ID})
+	require.ErrorIs(t, err, errors.ErrConflict)
+}
+
+func TestSubs_DayPrecision(t *testing.T) {
+	t.Log("Create, update and cancel subs with day precision dates")
+
+	ctx, subsUC, _ := newTestSubsUsecase()
+	start := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	results, err := subsUC.CreateBatch(ctx, entity.SubscriptionList{{
+		ServiceName:        "Ivi",
+		Price:              entity.Money{Amount: 19900, Currency: entity.DefaultCurrency},
+		BillingPeriod:      entity.BillingMonthly,
+		BillingInterval:    1,
+		StartDate:          &start,
+		StartDatePrecision: entity.PrecisionDay,
+	}}, true)
+	require.NoError(t, err)
+	subs := results[0].Subs
+	// billing day is taken from the start date
+	require.Equal(t, 31, subs.BillingDay)
+	require.Equal(t, entity.PrecisionDay, subs.StartDatePrecision)
+	require.Nil(t, subs.EndDatePrecision)
+
+	// billing day follows the changed start date
+	newStart, precision := time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC), entity.PrecisionDay
+	updatedSubs, err := subsUC.Update(ctx, &entity.SubscriptionUpdate{
+		ID:                 subs.ID,
+		StartDate:          &newStart,
+		StartDatePrecision: &precision,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 17, updatedSubs.BillingDay)
+
+	// cancel in the past day
+	yesterday := time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)
+	_, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:               subs.ID,
+		EndDate:          &yesterday,
+		EndDatePrecision: entity.PrecisionDay,
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+	// cancel on the current day before the subs start day
+	today := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	_, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:               subs.ID,
+		EndDate:          &today,
+		EndDatePrecision: entity.PrecisionDay,
+	})
+	require.ErrorIs(t, err, errors.ErrValidateData)
+
+	endDate := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	canceledSubs, err := subsUC.Cancel(ctx, &entity.SubscriptionCancel{
+		ID:               subs.ID,
+		EndDate:          &endDate,
+		EndDatePrecision: entity.PrecisionDay,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &endDate, canceledSubs.EndDate)
+	require.Equal(t, entity.PrecisionDay, *canceledSubs.EndDatePrecision)
+	require.Equal(t, time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC), *canceledSubs.NextChargeDate)
+	// subs can end earlier in the current month
+	_, err = subsUC.Cancel(ctx, &entity.SubscriptionCancel{ID: subs.ID})
+	require.NoError(t, err)
+}
+
+func TestSubs_Delete(t *testing.T) {
+	t.Log("Delete subs twice and try to delete unexisting subs")
+
+	subs := entity.Subscription{ID: "subs", UserID: _testUserID, StartDate: month(time.March)}
+	ctx, subsUC, auditRepo := newTestSubsUsecase(subs)
+
+	require.NoError(t, subsUC.Delete(ctx, subs.ID))
+	require.NoError(t, subsUC.Delete(ctx, subs.ID))
+	require.Len(t, auditRepo.entries, 1)
+	require.Equal(t, entity.AuditDelete, auditRepo.entries[0].Action)
+	require.Equal(t, []string{subs.ID, subs.ID}, subsUC.subsRepoDB.(*fakeSubsRepo).locked)
+
+	require.ErrorIs(t, subsUC.Delete(ctx, "unexisting"), errors.ErrNotFound)
+}
+
+func TestSubs_CreateBatch(t *testing.T) {
+	t.Log("Create subs batch with invalid subs in atomic and non-atomic modes")
+
+	ctx, subsUC, auditRepo := newTestSubsUsecase()
+	newSubsList := func() entity.SubscriptionList {
+		return entity.SubscriptionList{
+			{ServiceName: " Ivi ", Price: entity.Money{Amount: 100}, StartDate: month(time.March)},
+			{ServiceName: "Okko", StartDate: month(time.March)},
+			{ServiceName: "Kion", Price: entity.Money{Amount: 100}, StartDate: month(time.May)},
+		}
+	}
+
+	_, err := subsUC.CreateBatch(ctx, newSubsList(), true)
+	require.ErrorIs(t, err, errors.ErrValidateData)
+	require.Contains(t, err.Error(), "item 1")
+	require.Empty(t, auditRepo.entries)
+
+	results, err := subsUC.CreateBatch(ctx, newSubsList(), false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "Ivi", results[0].Subs.ServiceName)
+	require.Equal(t, _testUserID, results[0].Subs.UserID)
+	require.ErrorIs(t, results[1].Err, errors.ErrValidateData)
+	require.Nil(t, results[1].Subs)
+	require.Equal(t, 2, results[2].Index)
+	require.NotEmpty(t, results[2].ID)
+	require.Len(t, auditRepo.entries, 2)
+}
+
+func TestSubs_CreateBatchDBError(t *testing.T) {
+	t.Log("Put DB error only into result of its subs in non-atomic mode")
+
+	ctx, subsUC, auditRepo := newTestSubsUsecase()
+	subsUC.subsRepoDB.(*fakeSubsRepo).broken = "Okko"
+	newSubsList := func() entity.SubscriptionList {
+		return entity.SubscriptionList{
+			{ServiceName: "Ivi", Price: entity.Money{Amount: 100}, StartDate: month(time.March)},
+			{ServiceName: "Okko", Price: entity.Money{Amount: 100}, StartDate: month(time.March)},
+			{ServiceName: "Kion", Price: entity.Money{Amount: 100}, StartDate: month(time.May)},
+		}
+	}
+
+	_, err := subsUC.CreateBatch(ctx, newSubsList(), true)
+	require.ErrorIs(t, err, errors.ErrConflict)
+	require.Empty(t, auditRepo.entries)
+
+	results, err := subsUC.CreateBatch(ctx, newSubsList(), false)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.NotNil(t, results[0].Subs)
+	require.ErrorIs(t, results[1].Err, errors.ErrConflict)
+	require.Nil(t, results[1].Subs)
+	require.Empty(t, results[1].ID)
+	require.NoError(t, results[2].Err)
+	require.Len(t, auditRepo.entries, 2)
+}
+
+func TestSubs_ImportCSV(t *testing.T) {
+	t.Log("Import subs from CSV with invalid rows in dry run and real modes")
+
+	ctx, subsUC, auditRepo := newTestSubsUsecase()
+	layout, err := NewSubsCSVLayout(";", "02.01.2006", []string{"service_name:Service"})
+	require.NoError(t, err)
+	data := "Service;price;user_id;start_date\n" +
+		"Ivi;100;" + _testUserID + ";01.03.2025\n" +
+		"Okko;-100;" + _testUserID + ";01.03.2025\n" +
+		"Kion;100;" + _testUserID + ";17.05.2025\n" +
+		"Wink;100\n"
+
+	report, err := subsUC.ImportCSV(ctx, strings.NewReader(data), layout, true)
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Total)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 2, report.Failed)
+	require.Equal(t, 3, report.Errors[0].Line)
+	require.Equal(t, http.StatusBadRequest, report.Errors[0].Status)
+	require.Equal(t, 5, report.Errors[1].Line)
+	require.Empty(t, auditRepo.entries)
+
+	report, err = subsUC.ImportCSV(ctx, strings.NewReader(data), layout, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Imported)
+	require.Len(t, auditRepo.entries, 2)
+	kion := findSubs(subsUC, "Kion")
+	require.NotNil(t, kion)
+	require.Equal(t, entity.PrecisionDay, kion.StartDatePrecision)
+	require.Equal(t, 17, kion.BillingDay)
+
+	// missing column fails the whole import
+	_, err = subsUC.ImportCSV(ctx, strings.NewReader("Service;price\n"), layout, false)
+	require.ErrorIs(t, err, errors.ErrValidateData)
+}
+
+func TestSubs_ImportCSVBatches(t *testing.T) {
+	t.Log("Create imported subs by batches")
+
+	ctx, subsUC, _ := newTestSubsUsecase()
+	layout, err := NewSubsCSVLayout("", "", nil)
+	require.NoError(t, err)
+	var data strings.Builder
+	data.WriteString("service_name,price,user_id,start_date\n")
+	for range _importBatchSize + 1 {
+		data.WriteString("Ivi,100," + _testUserID + ",03-2025\n")
+	}
+
+	report, err := subsUC.ImportCSV(ctx, strings.NewReader(data.String()), layout, false)
+	require.NoError(t, err)
+	require.Equal(t, _importBatchSize+1, report.Imported)
+	subsRepo := subsUC.subsRepoDB.(*fakeSubsRepo)
+	require.Equal(t, 2, subsRepo.batches)
+	require.Len(t, subsRepo.subs, _importBatchSize+1)
+}
+
+// findSubs returns subs of the fake repo with the given service name.
+func findSubs(subsUC *subsUsecase, serviceName string) *entity.Subscription {
+	for _, subs := range subsUC.subsRepoDB.(*fakeSubsRepo).subs {
+		if subs.ServiceName == serviceName {
+			return subs
+		}
+	}
+	return nil
+}
+
+func TestSubs_Export(t *testing.T) {
+	t.Log("Export subs scoped to regular user")
+
+	ctx, subsUC, _ := newTestSubsUsecase(
+		entity.Subscription{ID: "own", UserID: _testUserID, StartDate: month(time.March)},
+		entity.Subscription{ID: "another", UserID: "another", StartDate: month(time.March)},
+	)
+
+	_, err := subsUC.Export(ctx, &entity.SubscriptionListFilter{UserID: "another"})
+	require.ErrorIs(t, err, errors.ErrForbidden)
+	_, err = subsUC.Export(ctx, &entity.SubscriptionListFilter{IncludeDeleted: true})
+	require.ErrorIs(t, err, errors.ErrForbidden)
+
+	export, err := subsUC.Export(ctx, &entity.SubscriptionListFilter{})
+	require.NoError(t, err)
+	var ids []string
+	err = export(context.Background(), func(subs *entity.SubscriptionExport) error {
+		ids = append(ids, subs.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"own"}, ids)
+}
+
+func TestSubs_FilterServiceName(t *testing.T) {
+	t.Log("Normalize service name of filters like saved service names")
+
+	ctx, subsUC, _ := newTestSubsUsecase(entity.Subscription{
+		ID: "subs", ServiceName: "Yandex Plus", UserID: _testUserID, StartDate: month(time.March),
+	})
+
+	export, err := subsUC.Export(ctx, &entity.SubscriptionListFilter{ServiceName: " Yandex  Plus "})
+	require.NoError(t, err)
+	var ids []string
+	err = export(context.Background(), func(subs *entity.SubscriptionExport) error {
+		ids = append(ids, subs.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"subs"}, ids)
+
+	subsSum, err := subsUC.GetSum(ctx, &entity.SubscriptionSumFilter{ServiceName: "Yandex\tPlus "})
+	require.NoError(t, err)
+	require.Equal(t, "Yandex Plus", subsSum.Filter.ServiceName)
+	require.Equal(t, _testUserID, subsSum.Filter.UserID)
+}
+
+func TestSubs_NextChargeDate(t *testing.T) {
+	t.Log("Get subs with next charge date considering pauses and end")
+
+	ctx, subsUC, _ := newTestSubsUsecase(
+		entity.Subscription{ID: "quarterly", UserID: _testUserID, StartDate: month(time.February),
+			BillingPeriod: entity.BillingQuarterly, BillingInterval: 1},
+		entity.Subscription{ID: "ended", UserID: _testUserID, StartDate: month(time.January),
+			EndDate: month(time.July), BillingPeriod: entity.BillingMonthly, BillingInterval: 1},
+	)
+	subsRepo := subsUC.subsRepoDB.(*fakeSubsRepo)
+
+	subs, err := subsUC.GetByID(ctx, "quarterly", false)
+	require.NoError(t, err)
+	require.Equal(t, month(time.August), subs.NextChargeDate)
+	// charge in paused month is skipped
+	subsRepo.pauses = entity.SubscriptionPauseList{
+		{SubsID: "quarterly", StartDate: month(time.August), EndDate: month(time.August)},
+	}
+	subs, err = subsUC.GetByID(ctx, "quarterly", false)
+	require.NoError(t, err)
+	require.Equal(t, month(time.November), subs.NextChargeDate)
+
+	// the last charge of the ended subs was in its end month before the current date
+	subs, err = subsUC.GetByID(ctx, "ended", false)
+	require.NoError(t, err)
+	require.Nil(t, subs.NextChargeDate)
+}
+
+func TestSubs_GetUpcoming(t *testing.T) {
+	t.Log("Get upcoming charges of the user sorted by date")
+
+	weeklyStart := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+	ctx, subsUC, _ := newTestSubsUsecase(
+		entity.Subscription{ID: "monthly", ServiceName: "Okko", Price: entity.Money{Amount: 29900},
+			UserID: _testUserID, StartDate: month(time.January),
+			BillingPeriod: entity.BillingMonthly, BillingInterval: 1},
+		entity.Subscription{ID: "weekly", ServiceName: "Kion", Price: entity.Money{Amount: 9900},
+			UserID: _testUserID, StartDate: &weeklyStart,
+			BillingPeriod: entity.BillingWeekly, BillingInterval: 2},
+		entity.Subscription{ID: "another", UserID: "another", StartDate: month(time.January),
+			BillingPeriod: entity.BillingMonthly, BillingInterval: 1},
+	)
+
+	_, err := subsUC.GetUpcoming(ctx, "another", 30, 100)
+	require.ErrorIs(t, err, errors.ErrForbidden)
+
+	// window from 15-07-2025 to 13-08-2025
+	charges, err := subsUC.GetUpcoming(ctx, "", 30, 100)
+	require.NoError(t, err)
+	dates := make([]string, len(charges))
+	for i, charge := range charges {
+		dates[i] = charge.Date.Format(time.DateOnly) + " " + charge.SubsID
+	}
+	require.Equal(t, []string{
+		"2025-07-24 weekly",
+		"2025-08-01 monthly",
+		"2025-08-07 weekly",
+	}, dates)
+	require.Equal(t, int64(29900), charges[1].Amount.Amount)
+
+	charges, err = subsUC.GetUpcoming(ctx, "", 30, 2)
+	require.NoError(t, err)
+	require.Len(t, charges, 2)
+}
+
+func TestSubs_DeleteBatch(t *testing.T) {
+	t.Log("Delete subs batch with unexisting subs in atomic and non-atomic modes")
+
+	subs := entity.Subscription{ID: "subs", UserID: _testUserID, StartDate: month(time.March)}
+	ctx, subsUC, auditRepo := newTestSubsUsecase(subs)
+
+	_, err := subsUC.DeleteBatch(ctx, []string{"unexisting", subs.ID}, true)
+	require.ErrorIs(t, err, errors.ErrNotFound)
+	require.Contains(t, err.Error(), "item 0")
+
+	results, err := subsUC.DeleteBatch(ctx, []string{"unexisting", subs.ID}, false)
+	require.NoError(t, err)
+	require.ErrorIs(t, results[0].Err, errors.ErrNotFound)
+	require.Equal(t, "unexisting", results[0].ID)
+	require.NoError(t, results[1].Err)
+	require.Len(t, auditRepo.entries, 1)
+}
@@ -3,14 +3,87 @@
 package usecase
 
 import (
+	"context"
+	"io"
+
 	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/pkg/publisher"
 )
 
+// SubsExportFunc streams exported subs into the write func.
+// It stops on the first write error and returns it.
+type SubsExportFunc func(ctx context.Context,
+	write func(subs *entity.SubscriptionExport) error) error
+
 type SubsUsecase interface {
-	Create(subs *entity.Subscription) error
-	GetByID(id string) (*entity.Subscription, error)
-	Update(subs *entity.SubscriptionUpdate) (*entity.Subscription, error)
-	Delete(id string) error
-	GetAll() (entity.SubscriptionList, error)
-	GetSum(filter *entity.SubscriptionSumFilter) (int, error)
+	Create(ctx context.Context, subs *entity.Subscription) error
+	CreateBatch(ctx context.Context, subsList entity.SubscriptionList,
+		atomic bool) (entity.SubscriptionBatchResultList, error)
+	UpdateBatch(ctx context.Context, updates []entity.SubscriptionUpdate,
+		atomic bool) (entity.SubscriptionBatchResultList, error)
+	DeleteBatch(ctx context.Context, ids []string,
+		atomic bool) (entity.SubscriptionBatchResultList, error)
+	ImportCSV(ctx context.Context, r io.Reader, layout *SubsCSVLayout,
+		dryRun bool) (*entity.SubscriptionImportReport, error)
+	GetByID(ctx context.Context, id string, includeDeleted bool) (*entity.Subscription, error)
+	Update(ctx context.Context, subs *entity.SubscriptionUpdate) (*entity.Subscription, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*entity.Subscription, error)
+	Cancel(ctx context.Context, cancel *entity.SubscriptionCancel) (*entity.Subscription, error)
+	Pause(ctx context.Context, pause *entity.SubscriptionPause) error
+	Resume(ctx context.Context, id string) error
+	GetPauses(ctx context.Context, id string) (entity.SubscriptionPauseList, error)
+	GetAll(ctx context.Context,
+		filter *entity.SubscriptionListFilter) (*entity.SubscriptionPage, error)
+	Export(ctx context.Context, filter *entity.SubscriptionListFilter) (SubsExportFunc, error)
+	GetUpcoming(ctx context.Context,
+		userID string, days, limit int) (entity.SubscriptionChargeList, error)
+	GetSum(ctx context.Context, filter *entity.SubscriptionSumFilter) (*entity.SubscriptionSum, error)
+	GetGroupedSum(ctx context.Context,
+		filter *entity.SubscriptionSumGroupFilter) (entity.SubscriptionSumGroupList, error)
+	GetMonthlySum(ctx context.Context,
+		filter *entity.SubscriptionSumFilter) (entity.SubscriptionMonthlySumList, error)
+	GetHistory(ctx context.Context, filter *entity.AuditFilter) (entity.AuditEntryList, error)
+}
+
+type CalendarUsecase interface {
+	Token(ctx context.Context, userID string) (string, error)
+	RegenerateToken(ctx context.Context, userID string) (string, error)
+	GetSchedules(ctx context.Context,
+		userID, token string) (entity.SubscriptionScheduleList, error)
+}
+
+type AuditUsecase interface {
+	GetAll(ctx context.Context, filter *entity.AuditFilter) (entity.AuditEntryList, error)
+}
+
+type APIKeysUsecase interface {
+	Create(ctx context.Context, key *entity.APIKey) (*entity.APIKeyCreated, error)
+	GetAll(ctx context.Context) (entity.APIKeyList, error)
+	Revoke(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, key string) (*entity.AuthUser, error)
+}
+
+type WebhooksUsecase interface {
+	publisher.Publisher
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	GetByID(ctx context.Context, id string) (*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.WebhookUpdate) (*entity.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	GetAll(ctx context.Context) (entity.WebhookList, error)
+	GetDeliveries(ctx context.Context,
+		filter *entity.WebhookDeliveryFilter) (entity.WebhookDeliveryList, error)
+	DeliverPending(ctx context.Context) (int, error)
+	NotifyEnded(ctx context.Context) (int, error)
+}
+
+type OutboxUsecase interface {
+	Relay(ctx context.Context) (int, error)
+}
+
+type IdempotencyUsecase interface {
+	Begin(ctx context.Context, key *entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, key *entity.IdempotencyKey) error
+	Release(ctx context.Context, key *entity.IdempotencyKey) error
+	PurgeExpired(ctx context.Context) (int, error)
 }
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"SubscriptionAggregator/internal/app/entity"
+	apperrors "SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/publisher"
+	"SubscriptionAggregator/internal/pkg/webhook"
+)
+
+const (
+	_maxRetryDelay     = 24 * time.Hour // max delay between delivery attempts
+	_maxDeliveryErrLen = 1000           // max length of saved delivery error
+)
+
+var _ WebhooksUsecase = (*webhooksUsecase)(nil)
+
+// WebhooksConfig is a config of webhook deliveries.
+type WebhooksConfig struct {
+	// max number of deliveries sent at once
+	BatchSize int
+	// number of attempts after which delivery is dead
+	MaxAttempts int
+	// delay before the first retry, it is doubled for every next one
+	RetryBackoff time.Duration
+	// time for which claimed delivery is not claimed again (must be longer than request timeout)
+	Lease time.Duration
+}
+
+// WebhooksUsecase implementation.
+type webhooksUsecase struct {
+	webhooksRepoDB repo.WebhooksRepoDB
+	subsRepoDB     repo.SubsRepoDB
+	sender         webhook.Sender
+	cfg            WebhooksConfig
+	now            func() time.Time
+}
+
+// NewWebhooksUsecase returns new WebhooksUsecase instance.
+func NewWebhooksUsecase(
+	webhooksRepoDB repo.WebhooksRepoDB,
+	subsRepoDB repo.SubsRepoDB,
+	sender webhook.Sender,
+	cfg WebhooksConfig,
+) WebhooksUsecase {
+	return &webhooksUsecase{
+		webhooksRepoDB: webhooksRepoDB,
+		subsRepoDB:     subsRepoDB,
+		sender:         sender,
+		cfg:            cfg,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Create creates new webhook. ID is auto-generated. Only admin can create webhooks.
+func (u *webhooksUsecase) Create(ctx context.Context, webhook *entity.Webhook) error {
+	if _, err := requireAdmin(ctx); err != nil {
+		return errors.Wrap(err, "create webhook")
+	}
+	webhook.ID = uuid.NewString()
+	webhook.CreatedAt = u.now()
+	err := u.webhooksRepoDB.Create(ctx, webhook)
+	return errors.Wrap(err, "create webhook")
+}
+
+// GetByID gets one webhook by given ID. Only admin can get webhooks.
+func (u *webhooksUsecase) GetByID(ctx context.Context, id string) (*entity.Webhook, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "get webhook by id")
+	}
+	webhook, err := u.webhooksRepoDB.GetByID(ctx, id)
+	return webhook, errors.Wrap(err, "get webhook by id")
+}
+
+// Update updates given webhook fields by its ID. Only admin can update webhooks.
+func (u *webhooksUsecase) Update(
+	ctx context.Context,
+	webhook *entity.WebhookUpdate,
+) (*entity.Webhook, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "update webhook")
+	}
+	updatedWebhook, err := u.webhooksRepoDB.Update(ctx, webhook)
+	return updatedWebhook, errors.Wrap(err, "update webhook")
+}
+
+// Delete deletes webhook with its deliveries by its ID. Only admin can delete webhooks.
+func (u *webhooksUsecase) Delete(ctx context.Context, id string) error {
+	if _, err := requireAdmin(ctx); err != nil {
+		return errors.Wrap(err, "delete webhook")
+	}
+	err := u.webhooksRepoDB.Delete(ctx, id)
+	return errors.Wrap(err, "delete webhook")
+}
+
+// GetAll returns all webhooks. Only admin can list webhooks.
+func (u *webhooksUsecase) GetAll(ctx context.Context) (entity.WebhookList, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "get all webhooks")
+	}
+	webhookList, err := u.webhooksRepoDB.GetList(ctx)
+	return webhookList, errors.Wrap(err, "get all webhooks")
+}
+
+// GetDeliveries returns delivery log of the webhook filtered by filter.
+// Only admin can get deliveries.
+func (u *webhooksUsecase) GetDeliveries(
+	ctx context.Context,
+	filter *entity.WebhookDeliveryFilter,
+) (entity.WebhookDeliveryList, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "get webhook deliveries")
+	}
+	// check webhook existence to distinguish it from empty log
+	if _, err := u.webhooksRepoDB.GetByID(ctx, filter.WebhookID); err != nil {
+		return nil, errors.Wrap(err, "get webhook deliveries")
+	}
+	deliveryList, err := u.webhooksRepoDB.GetDeliveries(ctx, filter)
+	return deliveryList, errors.Wrap(err, "get webhook deliveries")
+}
+
+// Publish enqueues delivery of the event message to every active webhook
+// subscribed to its type. Deliveries are sent asynchronously by DeliverPending.
+// Message published again is not delivered twice to the same webhook.
+func (u *webhooksUsecase) Publish(ctx context.Context, msg *publisher.Message) error {
+	webhookList, err := u.webhooksRepoDB.GetActiveByEvent(ctx, entity.EventType(msg.Type))
+	if err != nil {
+		return errors.Wrap(err, "publish event")
+	}
+	if len(webhookList) == 0 {
+		return nil
+	}
+
+	now := u.now()
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhookList))
+	for _, hook := range webhookList {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			ID:            uuid.NewString(),
+			WebhookID:     hook.ID,
+			EventID:       msg.ID,
+			EventType:     entity.EventType(msg.Type),
+			Payload:       msg.Body,
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	err = u.webhooksRepoDB.CreateDeliveries(ctx, deliveries)
+	return errors.Wrap(err, "publish event")
+}
+
+// DeliverPending sends one batch of due pending deliveries and saves attempts results.
+// Failed delivery is retried with exponential backoff until max attempts are made,
+// then it becomes dead. It returns number of processed deliveries.
+func (u *webhooksUsecase) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := u.webhooksRepoDB.ClaimDueDeliveries(ctx, u.cfg.BatchSize, u.cfg.Lease)
+	if err != nil {
+		return 0, errors.Wrap(err, "deliver pending")
+	}
+
+	webhooks := make(map[string]*entity.Webhook) // webhooks of the batch by ID
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		hook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			hook, err = u.webhooksRepoDB.GetByID(ctx, delivery.WebhookID)
+			if goerrors.Is(err, apperrors.ErrNotFound) {
+				continue // webhook is deleted with its deliveries
+			}
+			if err != nil {
+				return i, errors.Wrap(err, "deliver pending")
+			}
+			webhooks[delivery.WebhookID] = hook
+		}
+
+		u.attempt(ctx, hook, delivery)
+		if err := u.webhooksRepoDB.UpdateDelivery(ctx, delivery); err != nil {
+			return i, errors.Wrap(err, "deliver pending")
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery to the webhook and sets attempt result into the delivery.
+func (u *webhooksUsecase) attempt(
+	ctx context.Context,
+	hook *entity.Webhook,
+	delivery *entity.WebhookDelivery,
+) {
+	delivery.Attempts++
+	var err error
+	if hook.Active {
+		delivery.LastStatusCode, err = u.sender.Send(ctx, &webhook.Request{
+			URL:       hook.URL,
+			Secret:    hook.Secret,
+			ID:        delivery.ID,
+			Event:     string(delivery.EventType),
+			Body:      delivery.Payload,
+			Timestamp: u.now(),
+		})
+	} else {
+		delivery.LastStatusCode, err = 0, goerrors.New("webhook is inactive")
+	}
+
+	now := u.now()
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case !hook.Active || delivery.Attempts >= u.cfg.MaxAttempts:
+		delivery.Status = entity.DeliveryDead
+		delivery.LastError = truncateError(err)
+	default:
+		delivery.LastError = truncateError(err)
+		delivery.NextAttemptAt = now.Add(retryDelay(u.cfg.RetryBackoff, delivery.Attempts))
+	}
+}
+
+// NotifyEnded creates subs.ended event for every subs ended before the current day
+// which is not notified yet. It returns number of created events.
+func (u *webhooksUsecase) NotifyEnded(ctx context.Context) (int, error) {
+	today := dayStart(u.now())
+
+	notified := 0
+	for {
+		subsList, err := u.subsRepoDB.MarkEnded(ctx, today, u.cfg.BatchSize)
+		if err != nil {
+			return notified, errors.Wrap(err, "notify ended")
+		}
+		notified += len(subsList)
+		if len(subsList) < u.cfg.BatchSize {
+			return notified, nil
+		}
+	}
+}
+
+// retryDelay returns delay before the next attempt after the given number of failed attempts.
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempts && delay < _maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, _maxRetryDelay)
+}
+
+// truncateError returns error message cut to the max saved length.
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > _maxDeliveryErrLen {
+		return fmt.Sprintf("%s...", msg[:_maxDeliveryErrLen])
+	}
+	return msg
+}
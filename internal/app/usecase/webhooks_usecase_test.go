@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"SubscriptionAggregator/internal/app/entity"
+	"SubscriptionAggregator/internal/app/errors"
+	"SubscriptionAggregator/internal/app/repo"
+	"SubscriptionAggregator/internal/pkg/publisher"
+	"SubscriptionAggregator/internal/pkg/webhook"
+)
+
+const _testWebhookSecret = "test-webhook-secret"
+
+// fakeWebhooksRepo is in-memory WebhooksRepoDB with webhooks and deliveries used by delivery.
+type fakeWebhooksRepo struct {
+	repo.WebhooksRepoDB
+	webhooks   entity.WebhookList
+	deliveries []entity.WebhookDelivery
+	now        func() time.Time
+}
+
+func (r *fakeWebhooksRepo) GetByID(_ context.Context, id string) (*entity.Webhook, error) {
+	for i := range r.webhooks {
+		if r.webhooks[i].ID == id {
+			return &r.webhooks[i], nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (r *fakeWebhooksRepo) GetActiveByEvent(
+	_ context.Context,
+	eventType entity.EventType,
+) (entity.WebhookList, error) {
+	webhookList := entity.WebhookList{}
+	for _, hook := range r.webhooks {
+		if hook.Active && hook.EventTypes.Has(string(eventType)) {
+			webhookList = append(webhookList, hook)
+		}
+	}
+	return webhookList, nil
+}
+
+func (r *fakeWebhooksRepo) CreateDeliveries(
+	_ context.Context,
+	deliveries []entity.WebhookDelivery,
+) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *fakeWebhooksRepo) ClaimDueDeliveries(
+	_ context.Context,
+	limit int,
+	_ time.Duration,
+) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if len(deliveries) < limit && delivery.Status == entity.DeliveryPending &&
+			!delivery.NextAttemptAt.After(r.now()) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *fakeWebhooksRepo) UpdateDelivery(
+	_ context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == delivery.ID {
+			r.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+// newTestWebhooksUsecase returns usecase with fake repo and webhook to the given URL
+// subscribed to subs.created events. Usecase time is moved by returned function.
+func newTestWebhooksUsecase(
+	url string,
+) (*webhooksUsecase, *fakeWebhooksRepo, func(time.Duration)) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+	webhooksRepo := &fakeWebhooksRepo{
+		webhooks: entity.WebhookList{{
+			ID:         "webhook",
+			URL:        url,
+			Secret:     _testWebhookSecret,
+			EventTypes: entity.TextArray{string(entity.EventSubsCreated)},
+			Active:     true,
+		}},
+		now: nowFunc,
+	}
+	webhooksUC := NewWebhooksUsecase(webhooksRepo, nil, webhook.NewSender(time.Second),
+		WebhooksConfig{BatchSize: 10, MaxAttempts: 3, RetryBackoff: time.Minute})
+	webhooksUC.(*webhooksUsecase).now = nowFunc
+	return webhooksUC.(*webhooksUsecase), webhooksRepo, func(d time.Duration) { now = now.Add(d) }
+}
+
+// newTestMessage returns event message of the given type with subs state.
+func newTestMessage(
+	t *testing.T,
+	eventType entity.EventType,
+	subs *entity.Subscription,
+) *publisher.Message {
+	t.Helper()
+
+	event := entity.Event{ID: uuid.NewString(), Type: eventType, Data: subs}
+	body, err := json.Marshal(event)
+	require.NoError(t, err)
+	return &publisher.Message{ID: event.ID, Type: string(eventType), Body: body}
+}
+
+func TestWebhooks_Deliver(t *testing.T) {
+	t.Log("Publish event and deliver it to the receiver with signature")
+
+	received := make(chan *http.Request, 1)
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer receiver.Close()
+	webhooksUC, webhooksRepo, _ := newTestWebhooksUsecase(receiver.URL)
+
+	subs := &entity.Subscription{ID: "subs", ServiceName: "Yandex Plus"}
+	require.NoError(t, webhooksUC.Publish(t.Context(),
+		newTestMessage(t, entity.EventSubsCreated, subs)))
+	// webhook is not subscribed to the event type
+	require.NoError(t, webhooksUC.Publish(t.Context(),
+		newTestMessage(t, entity.EventSubsDeleted, subs)))
+	require.Len(t, webhooksRepo.deliveries, 1)
+
+	sent, err := webhooksUC.DeliverPending(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+
+	req := <-received
+	require.Equal(t, string(entity.EventSubsCreated), req.Header.Get(webhook.HeaderEvent))
+	require.Equal(t, webhooksRepo.deliveries[0].ID, req.Header.Get(webhook.HeaderID))
+	require.True(t, webhook.Verify(_testWebhookSecret, req.Header.Get(webhook.HeaderTimestamp),
+		receivedBody, req.Header.Get(webhook.HeaderSignature)))
+	event := entity.Event{}
+	require.NoError(t, json.Unmarshal(receivedBody, &event))
+	require.Equal(t, subs.ID, event.Data.ID)
+
+	delivery := webhooksRepo.deliveries[0]
+	require.Equal(t, entity.DeliveryDelivered, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+	require.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWebhooks_DeliverRetries(t *testing.T) {
+	t.Log("Retry failed delivery with backoff until it is dead")
+
+	var requests atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	webhooksUC, webhooksRepo, moveTime := newTestWebhooksUsecase(receiver.URL)
+
+	require.NoError(t, webhooksUC.Publish(t.Context(),
+		newTestMessage(t, entity.EventSubsCreated, &entity.Subscription{ID: "subs"})))
+
+	// first attempt and retries after 1 and 2 minutes
+	for i, delay := range []time.Duration{0, time.Minute, 2 * time.Minute} {
+		moveTime(delay - time.Second)
+		sent, err := webhooksUC.DeliverPending(t.Context())
+		require.NoError(t, err)
+		require.Zero(t, sent, "delivery is sent before its retry time")
+
+		moveTime(time.Second)
+		sent, err = webhooksUC.DeliverPending(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+		require.EqualValues(t, i+1, requests.Load())
+	}
+
+	delivery := webhooksRepo.deliveries[0]
+	require.Equal(t, entity.DeliveryDead, delivery.Status)
+	require.Equal(t, 3, delivery.Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	require.Contains(t, delivery.LastError, "unexpected status 503")
+
+	// dead delivery is not sent anymore
+	moveTime(time.Hour)
+	sent, err := webhooksUC.DeliverPending(t.Context())
+	require.NoError(t, err)
+	require.Zero(t, sent)
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Log("Double retry delay up to the max one")
+
+	require.Equal(t, time.Minute, retryDelay(time.Minute, 1))
+	require.Equal(t, 8*time.Minute, retryDelay(time.Minute, 4))
+	require.Equal(t, _maxRetryDelay, retryDelay(time.Minute, 100))
+}
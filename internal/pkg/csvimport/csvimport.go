@@ -0,0 +1,126 @@
+// Package csvimport reads rows of CSV files with configurable layout:
+// delimiter and names of the header columns with values of the fields.
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const _utf8BOM = "\ufeff" // byte order mark some spreadsheet apps put into CSV files
+
+// ErrMissingColumn is returned if the header does not contain column of the required field.
+var ErrMissingColumn = errors.New("missing column")
+
+// Layout of the CSV file.
+type Layout struct {
+	// fields delimiter (comma by default)
+	Delimiter rune
+	// header column names by field names (field name is used for not mapped field)
+	Columns map[string]string
+}
+
+// Column returns header column name of the field.
+func (l *Layout) Column(field string) string {
+	if column, ok := l.Columns[field]; ok {
+		return column
+	}
+	return field
+}
+
+// Row is one data row of the CSV file.
+type Row struct {
+	// line number of the row in the file
+	Line int
+	// trimmed values by field names (field without column has no value)
+	Values map[string]string
+	// error of the row format (e.g. wrong number of fields)
+	Err error
+}
+
+// Value returns pointer to the field value or nil if the field has no column.
+func (r *Row) Value(field string) *string {
+	value, ok := r.Values[field]
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// Reader reads rows of the CSV file with the given layout.
+type Reader struct {
+	csvReader *csv.Reader
+	columns   int            // number of header columns
+	indexes   map[string]int // column indexes by field names
+}
+
+// NewReader reads header of the CSV file and returns reader of its data rows.
+// Header columns are matched with the fields case-insensitively.
+// ErrMissingColumn is returned if there is no column of any required field.
+func NewReader(r io.Reader, layout Layout, fields, required []string) (*Reader, error) {
+	csvReader := csv.NewReader(r)
+	if layout.Delimiter != 0 {
+		csvReader.Comma = layout.Delimiter
+	}
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty csv")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	// columns of the rows are not checked against header, rows report it themselves
+	csvReader.FieldsPerRecord = -1
+
+	columnIndexes := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, _utf8BOM)))
+		columnIndexes[column] = i
+	}
+	indexes := make(map[string]int, len(fields))
+	for _, field := range fields {
+		column := strings.ToLower(strings.TrimSpace(layout.Column(field)))
+		if i, ok := columnIndexes[column]; ok {
+			indexes[field] = i
+		}
+	}
+	for _, field := range required {
+		if _, ok := indexes[field]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrMissingColumn, layout.Column(field))
+		}
+	}
+	return &Reader{csvReader: csvReader, columns: len(header), indexes: indexes}, nil
+}
+
+// Read returns the next data row. It returns io.EOF if there are no more rows.
+// Rows with wrong number of fields are returned with error, other CSV errors
+// are returned as the reading error.
+func (r *Reader) Read() (*Row, error) {
+	for {
+		record, err := r.csvReader.Read()
+		if err != nil {
+			return nil, err // io.EOF OR CSV error
+		}
+		line, _ := r.csvReader.FieldPos(0)
+		// skip blank lines with spaces only
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		row := &Row{Line: line, Values: make(map[string]string, len(r.indexes))}
+		if len(record) != r.columns {
+			row.Err = fmt.Errorf("expected %d fields, got %d", r.columns, len(record))
+		}
+		for field, i := range r.indexes {
+			if i < len(record) {
+				row.Values[field] = strings.TrimSpace(record[i])
+			}
+		}
+		return row, nil
+	}
+}
@@ -0,0 +1,70 @@
+package csvimport
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads all rows of the reader.
+func readAll(t *testing.T, reader *Reader) []*Row {
+	t.Helper()
+	var rows []*Row
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Log("Read rows with mapped columns and custom delimiter")
+
+	data := "\ufeffService; Cost ;extra\nYandex Plus; 199.99 ;x\n\nKinopoisk\n"
+	layout := Layout{
+		Delimiter: ';',
+		Columns:   map[string]string{"name": "service", "price": "COST"},
+	}
+
+	reader, err := NewReader(strings.NewReader(data), layout,
+		[]string{"name", "price", "currency"}, []string{"name"})
+	require.NoError(t, err)
+
+	rows := readAll(t, reader)
+	require.Len(t, rows, 2)
+
+	require.Equal(t, 2, rows[0].Line)
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, map[string]string{"name": "Yandex Plus", "price": "199.99"}, rows[0].Values)
+	require.Nil(t, rows[0].Value("currency"))
+
+	require.Equal(t, 4, rows[1].Line)
+	require.Error(t, rows[1].Err)
+	t.Logf("Expected row error: %s", rows[1].Err.Error())
+}
+
+func TestReader_Errors(t *testing.T) {
+	t.Log("Missing required column")
+	_, err := NewReader(strings.NewReader("name,cost\n"), Layout{},
+		[]string{"name", "price"}, []string{"name", "price"})
+	require.ErrorIs(t, err, ErrMissingColumn)
+	t.Logf("Expected error: %s", err.Error())
+
+	t.Log("Empty file")
+	_, err = NewReader(strings.NewReader(""), Layout{}, []string{"name"}, nil)
+	require.Error(t, err)
+
+	t.Log("Broken quotes")
+	reader, err := NewReader(strings.NewReader("name\n\"broken\n"), Layout{},
+		[]string{"name"}, nil)
+	require.NoError(t, err)
+	_, err = reader.Read()
+	require.Error(t, err)
+	require.NotErrorIs(t, err, io.EOF)
+}
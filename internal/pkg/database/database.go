@@ -2,15 +2,24 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// PostgreSQL error code of the statement canceled by statement timeout or by request.
+const _pgQueryCanceled = "57014"
+
 // Internal interface compatible with a logger.Writer.
 // Used to configure a custom DB logger.
 type Logger interface {
@@ -24,6 +33,8 @@ type dbSettings struct {
 	translateError  bool
 	ignoreNotFound  bool
 	disableColorful bool
+	// max duration of one DB statement (0 means no limit)
+	statementTimeout time.Duration
 }
 
 // Type for options for DB struct initializing.
@@ -45,8 +56,12 @@ func New(dsn string, options ...Option) (*gorm.DB, error) {
 		opt(dbStorage)
 	}
 
+	dialector, err := withConn(dsn, dbStorage.statementTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("open db connection: %w", err)
+	}
 	gormDB, err := gorm.Open(
-		withConn(dsn),
+		dialector,
 		&gorm.Config{
 			// set UTC time zone
 			NowFunc: func() time.Time {
@@ -113,8 +128,43 @@ func WithDisableColorful() Option {
 	}
 }
 
-// Set connection for DB. Required.
+// Set max duration of one DB statement, DB cancels longer statements
+// and their errors are translated into context.DeadlineExceeded. Optional.
+// It can be changed for the transaction by SET LOCAL statement_timeout.
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(d *dbSettings) {
+		d.statementTimeout = timeout
+	}
+}
+
+// Set connection for DB with the statement timeout. Required.
 // In this case used PostgreSQL as DB.
-func withConn(dsn string) gorm.Dialector {
-	return postgres.Open(dsn)
+func withConn(dsn string, statementTimeout time.Duration) (gorm.Dialector, error) {
+	if statementTimeout <= 0 {
+		return postgres.Open(dsn), nil
+	}
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+	connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(
+		statementTimeout.Milliseconds(), 10)
+	return &timeoutDialector{
+		Dialector: postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}).(*postgres.Dialector),
+	}, nil
+}
+
+// timeoutDialector is a PostgreSQL dialector which translates errors
+// of canceled statements into context.DeadlineExceeded.
+type timeoutDialector struct {
+	*postgres.Dialector
+}
+
+// Translate translates DB error into GORM error.
+func (d *timeoutDialector) Translate(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == _pgQueryCanceled {
+		return fmt.Errorf("%w: %s", context.DeadlineExceeded, pgErr.Message)
+	}
+	return d.Dialector.Translate(err)
 }
@@ -0,0 +1,28 @@
+// Package disconnect detects close of the client connection
+// while the server handles its request and does not read from it.
+package disconnect
+
+import (
+	"net"
+	"time"
+)
+
+// _pastDeadline is a read deadline which wakes up the connection watcher.
+var _pastDeadline = time.Unix(1, 0)
+
+// Watch calls onClose once the client closes the connection until the returned stop
+// is called. Stop waits for the watcher to finish, after it the connection is ready
+// for the next read. Connection is watched without reading its data, so it must not be
+// read until stop is called. Connections without access to their socket are not watched.
+func Watch(conn net.Conn, onClose func()) (stop func()) {
+	watchDone := make(chan struct{})
+	if !watch(conn, onClose, watchDone) {
+		return func() {}
+	}
+	return func() {
+		// wake up the watcher waiting for the connection data
+		conn.SetReadDeadline(_pastDeadline) // nolint:errcheck,gosec // watcher is stopped anyway
+		<-watchDone
+		conn.SetReadDeadline(time.Time{}) // nolint:errcheck,gosec // next read sets its own
+	}
+}
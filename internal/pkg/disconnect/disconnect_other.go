@@ -0,0 +1,10 @@
+//go:build !unix
+
+package disconnect
+
+import "net"
+
+// watch does not watch connections on this platform.
+func watch(net.Conn, func(), chan<- struct{}) bool {
+	return false
+}
@@ -0,0 +1,86 @@
+//go:build unix
+
+package disconnect
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// connPair returns server and client sides of the new TCP connection.
+func connPair(t *testing.T) (server, client net.Conn) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	client, err = net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	server, err = listener.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return server, client
+}
+
+func TestWatch_Close(t *testing.T) {
+	t.Log("Detect close of the client connection")
+
+	server, client := connPair(t)
+	closed := make(chan struct{})
+	stop := Watch(server, func() { close(closed) })
+	defer stop()
+
+	require.NoError(t, client.Close())
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("connection close is not detected")
+	}
+}
+
+func TestWatch_Stop(t *testing.T) {
+	t.Log("Stop watching of the alive connection and read it")
+
+	server, client := connPair(t)
+	stop := Watch(server, func() { t.Error("alive connection is reported as closed") })
+	stop()
+
+	// connection is readable after stop
+	_, err := client.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(server, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+}
+
+func TestWatch_PendingData(t *testing.T) {
+	t.Log("Stop watching of the connection with unread data")
+
+	server, client := connPair(t)
+	_, err := client.Write([]byte("next request"))
+	require.NoError(t, err)
+
+	closed := make(chan struct{})
+	stop := Watch(server, func() { close(closed) })
+	stop()
+
+	// data is not consumed by watcher
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(server, buf)
+	require.NoError(t, err)
+	require.Equal(t, "next", string(buf))
+	select {
+	case <-closed:
+		t.Fatal("connection with data is reported as closed")
+	default:
+	}
+}
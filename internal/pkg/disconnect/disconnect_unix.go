@@ -0,0 +1,44 @@
+//go:build unix
+
+package disconnect
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// watch starts watcher of the connection socket which closes watchDone when it finishes.
+// Watcher peeks the socket data without reading it: end of stream or error means
+// the connection is closed, pending data (the next pipelined request) stops watching.
+// It returns false if the connection socket is not available.
+func watch(conn net.Conn, onClose func(), watchDone chan<- struct{}) bool {
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	rawConn, err := sysConn.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	go func() {
+		defer close(watchDone)
+		closed := false
+		peekBuf := make([]byte, 1)
+		// callback returning false waits until the socket is readable
+		err := rawConn.Read(func(socket uintptr) bool {
+			n, _, err := syscall.Recvfrom(int(socket), peekBuf, // nolint:gosec // socket is int
+				syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+			if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+				return false
+			}
+			closed = err != nil || n == 0
+			return true
+		})
+		if err == nil && closed {
+			onClose()
+		}
+	}()
+	return true
+}
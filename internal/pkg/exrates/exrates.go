@@ -0,0 +1,119 @@
+// Package exrates provides parsers of exchange rates files (CSV and ECB XML).
+package exrates
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const _dateFmt = time.DateOnly // format of rate dates in files
+
+// Rate is an exchange rate of the currency against the base currency
+// (amount of currency for 1 unit of base currency).
+type Rate struct {
+	Currency string
+	Date     time.Time
+	Rate     float64
+}
+
+// Parser parses exchange rates from the reader.
+type Parser func(r io.Reader) ([]Rate, error)
+
+// Parsers by format names.
+var Parsers = map[string]Parser{
+	"csv": ParseCSV,
+	"ecb": ParseECB,
+}
+
+// ParseCSV parses rates from CSV with header "date,currency,rate".
+// Dates are in YYYY-MM-DD format.
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 3 // nolint:mnd // date, currency, rate
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty csv")
+	}
+	if header := strings.Join(records[0], ","); header != "date,currency,rate" {
+		return nil, fmt.Errorf("unexpected csv header %q", header)
+	}
+
+	rates := make([]Rate, 0, len(records)-1)
+	for i, record := range records[1:] {
+		rate, err := parseRate(record[0], record[1], record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err) // nolint:mnd // skip header
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// ecbEnvelope is an ECB euro foreign exchange reference rates XML document
+// (daily or historical one).
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ParseECB parses rates from ECB euro foreign exchange reference rates XML
+// (eurofxref-daily.xml or eurofxref-hist.xml). Base currency is EUR.
+func ParseECB(r io.Reader) ([]Rate, error) {
+	envelope := ecbEnvelope{}
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("decode xml: %w", err)
+	}
+
+	var rates []Rate
+	for _, day := range envelope.Cube.Days {
+		for _, dayRate := range day.Rates {
+			rate, err := parseRate(day.Time, dayRate.Currency, dayRate.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("day %s: %w", day.Time, err)
+			}
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("no rates in xml")
+	}
+	return rates, nil
+}
+
+// parseRate parses rate from its string fields.
+func parseRate(dateStr, currency, rateStr string) (Rate, error) {
+	date, err := time.Parse(_dateFmt, strings.TrimSpace(dateStr))
+	if err != nil {
+		return Rate{}, fmt.Errorf("parse date: %w", err)
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 { // nolint:mnd // ISO 4217 code length
+		return Rate{}, fmt.Errorf("invalid currency %q", currency)
+	}
+	rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+	if err != nil {
+		return Rate{}, fmt.Errorf("parse rate: %w", err)
+	}
+	if rate <= 0 {
+		return Rate{}, fmt.Errorf("rate must be positive, got %v", rate)
+	}
+	return Rate{Currency: currency, Date: date, Rate: rate}, nil
+}
@@ -0,0 +1,53 @@
+package exrates
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Log("Parse rates from CSV")
+
+	data := "date,currency,rate\n2025-07-01,usd,1.1789\n2025-07-01, RUB, 92.5\n"
+
+	rates, err := ParseCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, []Rate{
+		{Currency: "USD", Date: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), Rate: 1.1789},
+		{Currency: "RUB", Date: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), Rate: 92.5},
+	}, rates)
+
+	_, err = ParseCSV(strings.NewReader("date,currency,rate\n2025-07-01,USD,-1\n"))
+	require.Error(t, err)
+	t.Logf("Expected error: %s", err.Error())
+}
+
+func TestParseECB(t *testing.T) {
+	t.Log("Parse rates from ECB XML")
+
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2025-07-02">
+			<Cube currency="USD" rate="1.1794"/>
+			<Cube currency="JPY" rate="169.75"/>
+		</Cube>
+		<Cube time="2025-07-01">
+			<Cube currency="USD" rate="1.1789"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+	rates, err := ParseECB(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+	require.Equal(t, Rate{
+		Currency: "JPY",
+		Date:     time.Date(2025, time.July, 2, 0, 0, 0, 0, time.UTC),
+		Rate:     169.75,
+	}, rates[1])
+}
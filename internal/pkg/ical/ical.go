@@ -0,0 +1,196 @@
+// Package ical writes iCalendar (RFC 5545) calendars with all-day,
+// optionally recurring events.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MIMEType is a media type of iCalendar files.
+const MIMEType = "text/calendar; charset=utf-8"
+
+const (
+	_dateFormat  = "20060102"         // format of DATE values
+	_stampFormat = "20060102T150405Z" // format of UTC DATE-TIME values
+	_maxLineLen  = 75                 // max number of octets in the line before folding
+)
+
+// Frequency of the event recurrence.
+type Frequency string
+
+// Supported frequencies.
+const (
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Recurrence is a rule of the event recurrence.
+type Recurrence struct {
+	Freq Frequency
+	// number of frequency periods between occurrences (1 if not set)
+	Interval int
+	// month of yearly occurrences (not used if it is 0)
+	ByMonth time.Month
+	// days of month of occurrences (not used if it is empty)
+	ByMonthDays []int
+	// position of the occurrence among the days of the period, negative from its end
+	// (not used if it is 0)
+	BySetPos int
+	// date of the last possible occurrence (nil for endless recurrence)
+	Until *time.Time
+}
+
+// Event is an all-day event.
+type Event struct {
+	// unique and stable ID of the event, clients update events with the same UID
+	UID string
+	// revision of the event, it must grow on every change
+	Sequence int64
+	Summary  string
+	// optional description
+	Description string
+	// date of the (first) occurrence
+	Date time.Time
+	// optional recurrence rule
+	Recurrence *Recurrence
+	// excluded occurrence dates
+	ExDates []time.Time
+}
+
+// Calendar is a calendar with events.
+type Calendar struct {
+	// ID of the product which created the calendar
+	ProdID string
+	// optional name of the calendar displayed by clients
+	Name   string
+	Events []Event
+}
+
+// Encode writes the calendar into w. Stamp is a time of the calendar creation.
+func (c *Calendar) Encode(w io.Writer, stamp time.Time) error {
+	lw := &lineWriter{w: bufio.NewWriter(w)}
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + escapeText(c.ProdID))
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	for i := range c.Events {
+		c.Events[i].encode(lw, stamp)
+	}
+	lw.line("END:VCALENDAR")
+	if lw.err != nil {
+		return fmt.Errorf("write calendar: %w", lw.err)
+	}
+	if err := lw.w.Flush(); err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+	return nil
+}
+
+// encode writes the event lines.
+func (e *Event) encode(lw *lineWriter, stamp time.Time) {
+	lw.line("BEGIN:VEVENT")
+	lw.line("UID:" + escapeText(e.UID))
+	lw.line("DTSTAMP:" + stamp.UTC().Format(_stampFormat))
+	lw.line("SEQUENCE:" + strconv.FormatInt(e.Sequence, 10))
+	lw.line("DTSTART;VALUE=DATE:" + e.Date.Format(_dateFormat))
+	lw.line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format(_dateFormat))
+	if e.Recurrence != nil {
+		lw.line("RRULE:" + e.Recurrence.String())
+	}
+	if len(e.ExDates) != 0 {
+		dates := make([]string, len(e.ExDates))
+		for i, date := range e.ExDates {
+			dates[i] = date.Format(_dateFormat)
+		}
+		lw.line("EXDATE;VALUE=DATE:" + strings.Join(dates, ","))
+	}
+	lw.line("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		lw.line("DESCRIPTION:" + escapeText(e.Description))
+	}
+	lw.line("TRANSP:TRANSPARENT")
+	lw.line("END:VEVENT")
+}
+
+// String returns the recurrence as RRULE value.
+func (r *Recurrence) String() string {
+	rule := "FREQ=" + string(r.Freq)
+	if r.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if r.ByMonth != 0 {
+		rule += ";BYMONTH=" + strconv.Itoa(int(r.ByMonth))
+	}
+	if len(r.ByMonthDays) != 0 {
+		days := make([]string, len(r.ByMonthDays))
+		for i, day := range r.ByMonthDays {
+			days[i] = strconv.Itoa(day)
+		}
+		rule += ";BYMONTHDAY=" + strings.Join(days, ",")
+	}
+	if r.BySetPos != 0 {
+		rule += ";BYSETPOS=" + strconv.Itoa(r.BySetPos)
+	}
+	if r.Until != nil {
+		rule += ";UNTIL=" + r.Until.Format(_dateFormat)
+	}
+	return rule
+}
+
+// Escaper of TEXT values.
+var _textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes TEXT value.
+func escapeText(text string) string {
+	return _textEscaper.Replace(text)
+}
+
+// lineWriter writes content lines folded by max length and keeps the first error.
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes the content line. Long line is folded into several lines
+// without splitting UTF-8 characters, continuation lines start with space.
+func (lw *lineWriter) line(content string) {
+	if lw.err != nil {
+		return
+	}
+	limit := _maxLineLen
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		lw.write(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = _maxLineLen - 1 // continuation line starts with space
+	}
+	lw.write(content + "\r\n")
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err != nil {
+		return
+	}
+	_, lw.err = lw.w.WriteString(s)
+}
@@ -0,0 +1,86 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	t.Log("Encode calendar with recurring event")
+
+	until := time.Date(2025, 8, 31, 0, 0, 0, 0, time.UTC)
+	calendar := &Calendar{
+		ProdID: "-//Test//Calendar//RU",
+		Name:   "Charges",
+		Events: []Event{{
+			UID:      "subs-id@test",
+			Sequence: 2,
+			Summary:  "Yandex Plus, 199.99 RUB",
+			Date:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Recurrence: &Recurrence{
+				Freq:     Monthly,
+				Interval: 2,
+				Until:    &until,
+			},
+			ExDates: []time.Time{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, calendar.Encode(buf, time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)))
+
+	require.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Test//Calendar//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Charges",
+		"BEGIN:VEVENT",
+		"UID:subs-id@test",
+		"DTSTAMP:20250715T120000Z",
+		"SEQUENCE:2",
+		"DTSTART;VALUE=DATE:20250101",
+		"DTEND;VALUE=DATE:20250102",
+		"RRULE:FREQ=MONTHLY;INTERVAL=2;UNTIL=20250831",
+		"EXDATE;VALUE=DATE:20250301",
+		`SUMMARY:Yandex Plus\, 199.99 RUB`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), buf.String())
+}
+
+func TestRecurrence_String(t *testing.T) {
+	t.Log("Format recurrence rule on the last day of short months")
+
+	until := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	recurrence := &Recurrence{
+		Freq:        Yearly,
+		ByMonth:     time.February,
+		ByMonthDays: []int{28, 29, 30},
+		BySetPos:    -1,
+		Until:       &until,
+	}
+	require.Equal(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=28,29,30;BYSETPOS=-1;UNTIL=20261231",
+		recurrence.String())
+}
+
+func TestLineWriter_Fold(t *testing.T) {
+	t.Log("Fold long lines without splitting characters")
+
+	buf := &bytes.Buffer{}
+	calendar := &Calendar{Name: strings.Repeat("подписка ", 20)}
+	require.NoError(t, calendar.Encode(buf, time.Now()))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), _maxLineLen)
+		require.True(t, strings.ToValidUTF8(line, "") == line)
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	require.Contains(t, unfolded, "X-WR-CALNAME:"+calendar.Name+"\r\n")
+}
@@ -0,0 +1,101 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	t.Log("Apply merge patches from RFC 7386 examples")
+
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		patched, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		require.NoError(t, err)
+		require.JSONEq(t, tt.expected, string(patched), "patch %s", tt.patch)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Log("Apply JSON patches")
+
+	const doc = `{"foo":"bar","baz":[1,2],"obj":{"a/b":1,"m~n":2}}`
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "add member and array items",
+			patch:    `[{"op":"add","path":"/qux","value":null},{"op":"add","path":"/baz/1","value":3},{"op":"add","path":"/baz/-","value":4}]`,
+			expected: `{"foo":"bar","qux":null,"baz":[1,3,2,4],"obj":{"a/b":1,"m~n":2}}`,
+		},
+		{
+			name:     "remove escaped members and array item",
+			patch:    `[{"op":"remove","path":"/obj/a~1b"},{"op":"remove","path":"/obj/m~0n"},{"op":"remove","path":"/baz/0"}]`,
+			expected: `{"foo":"bar","baz":[2],"obj":{}}`,
+		},
+		{
+			name:     "test and replace",
+			patch:    `[{"op":"test","path":"/baz/0","value":1.0},{"op":"replace","path":"/foo","value":{"x":1}}]`,
+			expected: `{"foo":{"x":1},"baz":[1,2],"obj":{"a/b":1,"m~n":2}}`,
+		},
+		{
+			name:     "move and copy",
+			patch:    `[{"op":"move","from":"/foo","path":"/obj/foo"},{"op":"copy","from":"/baz","path":"/copy"}]`,
+			expected: `{"baz":[1,2],"copy":[1,2],"obj":{"a/b":1,"m~n":2,"foo":"bar"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := Apply([]byte(doc), []byte(tt.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(patched))
+		})
+	}
+}
+
+func TestApplyFailed(t *testing.T) {
+	t.Log("Apply invalid JSON patches")
+
+	const doc = `{"foo":"bar","baz":[1,2]}`
+	tests := []struct {
+		name  string
+		patch string
+		err   error
+	}{
+		{"failed test", `[{"op":"test","path":"/foo","value":"baz"}]`, ErrTestFailed},
+		{"replace missing member", `[{"op":"replace","path":"/qux","value":1}]`, ErrInvalidPatch},
+		{"remove missing item", `[{"op":"remove","path":"/baz/2"}]`, ErrInvalidPatch},
+		{"add without value", `[{"op":"add","path":"/qux"}]`, ErrInvalidPatch},
+		{"invalid path", `[{"op":"remove","path":"foo"}]`, ErrInvalidPatch},
+		{"unsupported operation", `[{"op":"merge","path":"/foo","value":1}]`, ErrInvalidPatch},
+		{"move into child", `[{"op":"move","from":"/baz","path":"/baz/0"}]`, ErrInvalidPatch},
+		{"not a patch", `{"op":"remove","path":"/foo"}`, ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(doc), []byte(tt.patch))
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Package jwt provides verifying of JSON Web Tokens signed with HS256 or RS256.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token is expired")
+)
+
+var _ Verifier = (*verifier)(nil)
+
+// Verifier provides method to verify token and get its claims.
+type Verifier interface {
+	Verify(token string) (*Claims, error)
+}
+
+// Claims are token payload fields used by app.
+type Claims struct {
+	// token subject (user uuid)
+	Subject string `json:"sub"`
+	// user role
+	Role string `json:"role,omitempty"`
+	// expiration time (unix seconds)
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// time before which token must not be accepted (unix seconds)
+	NotBefore int64 `json:"nbf,omitempty"`
+}
+
+// header is a token JOSE header.
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Provides verifier with custom options when creating an object.
+type verifierSettings struct {
+	hmacSecret string
+	jwksFile   string
+}
+
+// Type for options for verifier initializing.
+type Option func(*verifierSettings)
+
+// WithHMACSecret sets secret to verify HS256 tokens.
+func WithHMACSecret(secret string) Option {
+	return func(s *verifierSettings) {
+		s.hmacSecret = secret
+	}
+}
+
+// WithJWKSFile sets path to JWKS file with RSA public keys to verify RS256 tokens.
+func WithJWKSFile(path string) Option {
+	return func(s *verifierSettings) {
+		s.jwksFile = path
+	}
+}
+
+// Verifier implementation.
+type verifier struct {
+	hmacSecret []byte
+	rsaKeys    map[string]*rsa.PublicKey // keys by key ID
+	now        func() time.Time
+}
+
+// New returns new Verifier instance.
+// At least one of HMAC secret and JWKS file must be set with "WithSmth" funcs.
+func New(options ...Option) (Verifier, error) {
+	settings := &verifierSettings{}
+	// apply all options to customize verifier
+	for _, opt := range options {
+		opt(settings)
+	}
+
+	v := &verifier{now: time.Now}
+	if settings.hmacSecret != "" {
+		v.hmacSecret = []byte(settings.hmacSecret)
+	}
+	if settings.jwksFile != "" {
+		rsaKeys, err := loadJWKSFile(settings.jwksFile)
+		if err != nil {
+			return nil, fmt.Errorf("load jwks: %w", err)
+		}
+		v.rsaKeys = rsaKeys
+	}
+	if v.hmacSecret == nil && len(v.rsaKeys) == 0 {
+		return nil, errors.New("neither hmac secret nor rsa keys are set")
+	}
+	return v, nil
+}
+
+// Verify checks token signature and time claims and returns its claims.
+func (v *verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { // nolint:mnd // header, payload and signature
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	tokenHeader := header{}
+	if err := decodeSegment(parts[0], &tokenHeader); err != nil {
+		return nil, fmt.Errorf("%w: decode header: %s", ErrInvalidToken, err.Error())
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode signature: %s", ErrInvalidToken, err.Error())
+	}
+	if err := v.verifySignature(&tokenHeader, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, fmt.Errorf("%w: decode claims: %s", ErrInvalidToken, err.Error())
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is missing", ErrInvalidToken)
+	}
+	now := v.now().Unix()
+	if claims.ExpiresAt != 0 && now >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// verifySignature verifies signature of the signing input by the header algorithm.
+func (v *verifier) verifySignature(tokenHeader *header, signingInput string, signature []byte) error {
+	switch tokenHeader.Alg {
+	case "HS256":
+		if v.hmacSecret == nil {
+			return errors.New("HS256 is not supported")
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case "RS256":
+		key, err := v.rsaKey(tokenHeader.Kid)
+		if err != nil {
+			return err
+		}
+		hashed := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature); err != nil {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", tokenHeader.Alg)
+	}
+}
+
+// rsaKey returns RSA public key by key ID.
+// Key ID may be omitted if there is only one key.
+func (v *verifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(v.rsaKeys) == 1 {
+		for _, key := range v.rsaKeys {
+			return key, nil
+		}
+	}
+	key, ok := v.rsaKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// decodeSegment decodes base64url JSON token segment into dest.
+func decodeSegment(segment string, dest any) error {
+	rawSegment, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rawSegment, dest)
+}
+
+// jwks is a JSON Web Key Set document.
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// loadJWKSFile reads RSA public keys from JWKS file. Keys of other types are skipped.
+func loadJWKSFile(path string) (map[string]*rsa.PublicKey, error) {
+	rawJWKS, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	keySet := jwks{}
+	if err := json.Unmarshal(rawJWKS, &keySet); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	rsaKeys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		modulus, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: decode modulus: %w", key.Kid, err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: decode exponent: %w", key.Kid, err)
+		}
+		rsaKeys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+	if len(rsaKeys) == 0 {
+		return nil, errors.New("no rsa keys in jwks")
+	}
+	return rsaKeys, nil
+}
@@ -0,0 +1,124 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testSecret = "test-secret"
+
+// encodeSegment encodes value as base64url JSON token segment.
+func encodeSegment(t *testing.T, value any) string {
+	t.Helper()
+
+	rawValue, err := json.Marshal(value)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(rawValue)
+}
+
+// signHS256 returns HS256 token with the given claims.
+func signHS256(t *testing.T, secret string, claims *Claims) string {
+	t.Helper()
+
+	signingInput := encodeSegment(t, header{Alg: "HS256"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS256 returns RS256 token with the given claims.
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims *Claims) string {
+	t.Helper()
+
+	signingInput := encodeSegment(t, header{Alg: "RS256", Kid: kid}) + "." + encodeSegment(t, claims)
+	hashed := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify_HS256(t *testing.T) {
+	t.Log("Verify HS256 tokens")
+
+	v, err := New(WithHMACSecret(_testSecret))
+	require.NoError(t, err)
+
+	claims := &Claims{
+		Subject:   "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		Role:      "admin",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	verifiedClaims, err := v.Verify(signHS256(t, _testSecret, claims))
+	require.NoError(t, err)
+	require.Equal(t, claims, verifiedClaims)
+
+	_, err = v.Verify(signHS256(t, "another-secret", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	_, err = v.Verify(signHS256(t, _testSecret, claims))
+	require.ErrorIs(t, err, ErrExpiredToken)
+
+	_, err = v.Verify("not.a-token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerify_RS256(t *testing.T) {
+	t.Log("Verify RS256 tokens with keys from JWKS file")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keySet := map[string]any{
+		"keys": []map[string]string{
+			{"kty": "oct", "kid": "skipped"},
+			{
+				"kty": "RSA",
+				"kid": "key-1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	}
+	rawKeySet, err := json.Marshal(keySet)
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, rawKeySet, 0o600))
+
+	v, err := New(WithJWKSFile(jwksFile))
+	require.NoError(t, err)
+
+	claims := &Claims{Subject: "60601fee-2bf1-4721-ae6f-7636e79a0cba"}
+	verifiedClaims, err := v.Verify(signRS256(t, key, "key-1", claims))
+	require.NoError(t, err)
+	require.Equal(t, claims, verifiedClaims)
+
+	// the only key is used if key ID is omitted
+	_, err = v.Verify(signRS256(t, key, "", claims))
+	require.NoError(t, err)
+
+	_, err = v.Verify(signRS256(t, key, "unknown", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// HS256 is not configured
+	_, err = v.Verify(signHS256(t, _testSecret, claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNew_NoKeys(t *testing.T) {
+	t.Log("Create verifier without keys")
+
+	_, err := New()
+	require.Error(t, err)
+}
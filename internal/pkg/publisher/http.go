@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP publisher request headers.
+const (
+	HeaderEventID   = "X-Event-Id"
+	HeaderEventType = "X-Event-Type"
+)
+
+const _maxErrBodySize = 512 // max number of response body bytes included into error
+
+var _ Publisher = (*httpPublisher)(nil)
+
+// Publisher implementation which sends messages to HTTP endpoint.
+type httpPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP returns new Publisher instance which sends messages
+// to the URL as POST requests with the given timeout.
+func NewHTTP(url string, timeout time.Duration) Publisher {
+	return &httpPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish sends POST request with JSON event as body and event ID and type in headers.
+// Error is returned if request is failed or response status is not 2xx.
+func (p *httpPublisher) Publish(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(msg.Body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, msg.ID)
+	req.Header.Set(HeaderEventType, msg.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, _maxErrBodySize))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Publisher = (*natsPublisher)(nil)
+
+// Publisher implementation which publishes messages to NATS server
+// using core NATS text protocol.
+type natsPublisher struct {
+	addr          string
+	subjectPrefix string
+	timeout       time.Duration
+
+	mu     sync.Mutex // protects connection
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATS returns new Publisher instance which publishes messages to NATS server
+// at the given address (host:port) with subject "<prefix>.<event type>".
+// Connection is opened on the first publish and reopened after any error.
+// Returned publisher implements io.Closer to close connection.
+func NewNATS(addr, subjectPrefix string, timeout time.Duration) Publisher {
+	return &natsPublisher{
+		addr:          addr,
+		subjectPrefix: subjectPrefix,
+		timeout:       timeout,
+	}
+}
+
+// Publish publishes message and waits for server acknowledgement (PONG on PING)
+// to be sure that message is processed by server.
+func (p *natsPublisher) Publish(ctx context.Context, msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, msg); err != nil {
+		p.closeConn()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+// publish publishes message with opened connection (it is opened if it is necessary).
+func (p *natsPublisher) publish(ctx context.Context, msg *Message) error {
+	deadline := time.Now().Add(p.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if p.conn == nil {
+		if err := p.connect(ctx, deadline); err != nil {
+			return err
+		}
+	}
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("set deadline: %w", err)
+	}
+
+	subject := p.subjectPrefix + "." + msg.Type
+	cmd := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(msg.Body), msg.Body)
+	if _, err := p.conn.Write([]byte(cmd)); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return p.waitPong()
+}
+
+// connect opens connection and sends CONNECT command after server INFO.
+func (p *natsPublisher) connect(ctx context.Context, deadline time.Time) error {
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("set deadline: %w", err)
+	}
+
+	line, err := p.readLine()
+	if err != nil {
+		return fmt.Errorf("read info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("unexpected server greeting: %q", line)
+	}
+	_, err = p.conn.Write([]byte(`CONNECT {"verbose":false,"pedantic":false}` + "\r\n"))
+	if err != nil {
+		return fmt.Errorf("write connect: %w", err)
+	}
+	return nil
+}
+
+// waitPong reads server commands until PONG. Server PINGs are answered,
+// server error is returned.
+func (p *natsPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("write pong: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// readLine reads one server command line without CRLF.
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// closeConn closes connection if it is opened.
+func (p *natsPublisher) closeConn() {
+	if p.conn != nil {
+		p.conn.Close() // nolint:errcheck,gosec // connection is dropped anyway
+		p.conn, p.reader = nil, nil
+	}
+}
+
+// Close closes connection to NATS server.
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closeConn()
+	return nil
+}
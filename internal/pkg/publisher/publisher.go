@@ -0,0 +1,46 @@
+// Package publisher provides publishers of event messages to external systems:
+// log, HTTP endpoint and NATS server.
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Publisher = (*logPublisher)(nil)
+
+// Message is an event message.
+type Message struct {
+	// message ID (unique for every event)
+	ID string
+	// event type
+	Type string
+	// JSON event
+	Body []byte
+}
+
+// Publisher publishes event messages.
+// Message can be published more than once so receivers should deduplicate it by ID.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Publisher implementation which writes messages to log.
+type logPublisher struct{}
+
+// NewLog returns new Publisher instance which writes messages to log.
+func NewLog() Publisher {
+	return &logPublisher{}
+}
+
+// Publish writes message to log with info level.
+func (p *logPublisher) Publish(_ context.Context, msg *Message) error {
+	logrus.WithFields(logrus.Fields{
+		"id":    msg.ID,
+		"type":  msg.Type,
+		"event": json.RawMessage(msg.Body),
+	}).Info("Event published")
+	return nil
+}
@@ -0,0 +1,101 @@
+package publisher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _testMsg = &Message{
+	ID:   "0b2cdb6e-3c5a-4a4e-9a4f-4f2a1c3b8e7d",
+	Type: "subs.created",
+	Body: []byte(`{"type":"subs.created"}`),
+}
+
+func TestHTTP_Publish(t *testing.T) {
+	t.Log("Publish message to HTTP endpoint")
+
+	statusCode := http.StatusNoContent
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(statusCode)
+	}))
+	defer receiver.Close()
+
+	pub := NewHTTP(receiver.URL, time.Second)
+	require.NoError(t, pub.Publish(t.Context(), _testMsg))
+	require.Equal(t, _testMsg.ID, received.Header.Get(HeaderEventID))
+	require.Equal(t, _testMsg.Type, received.Header.Get(HeaderEventType))
+	require.Equal(t, _testMsg.Body, receivedBody)
+
+	statusCode = http.StatusInternalServerError
+	require.ErrorContains(t, pub.Publish(t.Context(), _testMsg), "unexpected status 500")
+}
+
+// runNATSServer runs fake NATS server which accepts one connection
+// and sends received PUB commands into the returned channel.
+// Server responds with -ERR to the message with "fail" body.
+func runNATSServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	published := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				payload, _ := reader.ReadString('\n')
+				payload = strings.TrimRight(payload, "\r\n")
+				if payload == "fail" {
+					fmt.Fprint(conn, "-ERR 'Permissions Violation'\r\n")
+					return
+				}
+				published <- line + " " + payload
+			case line == "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+		}
+	}()
+	return listener.Addr().String(), published
+}
+
+func TestNATS_Publish(t *testing.T) {
+	t.Log("Publish messages to NATS server")
+
+	addr, published := runNATSServer(t)
+	pub := NewNATS(addr, "aggregator", time.Second)
+	defer pub.(io.Closer).Close()
+
+	require.NoError(t, pub.Publish(t.Context(), _testMsg))
+	require.Equal(t, fmt.Sprintf("PUB aggregator.subs.created %d %s",
+		len(_testMsg.Body), _testMsg.Body), <-published)
+
+	err := pub.Publish(t.Context(), &Message{ID: "id", Type: "subs.deleted", Body: []byte("fail")})
+	require.ErrorContains(t, err, "Permissions Violation")
+}
@@ -5,13 +5,60 @@ import (
 	"time"
 )
 
-const _dateFmt = "01-2006" // format to parse date into string to time.Time
+const (
+	DateFormat    = "01-2006"    // format of string dates with month precision
+	DayDateFormat = "2006-01-02" // format of string dates with day precision (ISO 8601)
+	_monthsInYear = 12
+)
+
+// ParseDate parses date from given string in YYYY-MM-DD or MM-YYYY format.
+// It returns true if the date is given with day, otherwise the first day of its month
+// is returned.
+func ParseDate(dateStr string) (time.Time, bool, error) {
+	if date, err := time.Parse(DayDateFormat, dateStr); err == nil {
+		return date, true, nil
+	}
+	date, err := time.Parse(DateFormat, dateStr)
+	if err != nil {
+		return date, false, fmt.Errorf("parse date: expected YYYY-MM-DD or MM-YYYY: %w", err)
+	}
+	return date, false, nil
+}
 
-// ParseDate parses date from given string by the const template.
-func ParseDate(dateStr string) (time.Time, error) {
-	date, err := time.Parse(_dateFmt, dateStr)
+// ParseDateLayout parses date from given string by the given layout (both formats
+// of ParseDate for empty layout). It returns true if the layout has day,
+// otherwise the first day of the date month is returned.
+func ParseDateLayout(dateStr, layout string) (time.Time, bool, error) {
+	if layout == "" {
+		return ParseDate(dateStr)
+	}
+	date, err := time.Parse(layout, dateStr)
 	if err != nil {
-		return date, fmt.Errorf("parse date: %w", err)
+		return date, false, fmt.Errorf("parse date: %w", err)
+	}
+	if LayoutHasDay(layout) {
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), true, nil
 	}
-	return date, nil
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC), false, nil
+}
+
+// LayoutHasDay returns true if dates formatted by the layout differ in days.
+func LayoutHasDay(layout string) bool {
+	day := time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC) // nolint:mnd // any day
+	return day.Format(layout) != day.AddDate(0, 0, 1).Format(layout)
+}
+
+// FormatDate formats date into string in YYYY-MM-DD format if it is given with day,
+// otherwise in MM-YYYY format.
+func FormatDate(date time.Time, withDay bool) string {
+	if withDay {
+		return date.Format(DayDateFormat)
+	}
+	return date.Format(DateFormat)
+}
+
+// MonthsBetween returns number of months from start date month to end date month
+// including both of them. It returns non-positive number if end month is before start month.
+func MonthsBetween(start, end time.Time) int {
+	return (end.Year()-start.Year())*_monthsInYear + int(end.Month()) - int(start.Month()) + 1
 }
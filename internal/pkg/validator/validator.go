@@ -3,6 +3,7 @@ package validator
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/locales/en"
@@ -35,10 +36,50 @@ func New() Validator {
 	if err != nil {
 		panic(err)
 	}
+	registerCustomValidations(validate, trans)
 
 	return &valid{validate, trans}
 }
 
+// registerCustomValidations registers app-specific validation tags with its translations.
+func registerCustomValidations(validate *govalidator.Validate, trans ut.Translator) {
+	// currency code (ISO 4217)
+	validate.RegisterAlias("currency", "iso4217")
+	err := validate.RegisterTranslation("currency", trans,
+		func(translator ut.Translator) error {
+			return translator.Add("currency", "{0} must be a valid ISO 4217 currency code", true)
+		},
+		func(translator ut.Translator, fe govalidator.FieldError) string {
+			msg, _ := translator.T("currency", fe.Field())
+			return msg
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// positive decimal number given as string (e.g. price)
+	err = validate.RegisterValidation("positive", func(fl govalidator.FieldLevel) bool {
+		number, err := strconv.ParseFloat(fl.Field().String(), 64)
+		return err == nil && number > 0
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = validate.RegisterTranslation("positive", trans,
+		func(translator ut.Translator) error {
+			return translator.Add("positive", "{0} must be a positive number", true)
+		},
+		func(translator ut.Translator, fe govalidator.FieldError) string {
+			msg, _ := translator.T("positive", fe.Field())
+			return msg
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Validate validates given struct s (using pointer to this struct).
 func (v valid) Validate(s any) error {
 	err := v.validatorInstance.Struct(s)
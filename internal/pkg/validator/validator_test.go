@@ -25,3 +25,41 @@ func TestValidateWithError(t *testing.T) {
 
 	t.Logf("Expected error: %s", err.Error())
 }
+
+type CurrencyStruct struct {
+	Currency string `validate:"required,currency"`
+}
+
+func TestValidateCurrency(t *testing.T) {
+	t.Log("Validate currency code")
+
+	valid := New()
+
+	err := valid.Validate(&CurrencyStruct{Currency: "USD"})
+	require.NoError(t, err)
+
+	err = valid.Validate(&CurrencyStruct{Currency: "usd"})
+	require.Error(t, err)
+
+	t.Logf("Expected error: %s", err.Error())
+}
+
+type PriceStruct struct {
+	Price string `validate:"required,numeric,positive"`
+}
+
+func TestValidatePositive(t *testing.T) {
+	t.Log("Validate positive decimal number")
+
+	valid := New()
+
+	err := valid.Validate(&PriceStruct{Price: "199.99"})
+	require.NoError(t, err)
+
+	for _, price := range []string{"0", "0.00", "-10"} {
+		err = valid.Validate(&PriceStruct{Price: price})
+		require.Error(t, err, price)
+	}
+
+	t.Logf("Expected error: %s", err.Error())
+}
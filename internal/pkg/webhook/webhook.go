@@ -0,0 +1,103 @@
+// Package webhook provides sending of signed webhook requests.
+//
+// Every request is a POST with JSON body and headers:
+//   - X-Webhook-Id - delivery ID (the same for all attempts)
+//   - X-Webhook-Event - event type
+//   - X-Webhook-Timestamp - unix time of the attempt
+//   - X-Webhook-Signature - "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>" with secret
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook request headers.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const _maxErrBodySize = 512 // max number of response body bytes included into error
+
+var _ Sender = (*httpSender)(nil)
+
+// Request is a webhook request data.
+type Request struct {
+	URL       string
+	Secret    string
+	ID        string
+	Event     string
+	Body      []byte
+	Timestamp time.Time
+}
+
+// Sender sends webhook requests.
+type Sender interface {
+	// Send sends request and returns response status code.
+	// Error is returned if request is failed or response status is not 2xx.
+	Send(ctx context.Context, req *Request) (int, error)
+}
+
+// Sender implementation.
+type httpSender struct {
+	client *http.Client
+}
+
+// NewSender returns new Sender instance with the given request timeout.
+func NewSender(timeout time.Duration) Sender {
+	return &httpSender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send sends signed POST request.
+func (s *httpSender) Send(ctx context.Context, req *Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL,
+		bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(req.Timestamp.Unix(), 10)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderID, req.ID)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderTimestamp, timestamp)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, _maxErrBodySize))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns signature header value of the body sent at the timestamp (unix time string).
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature matches the body sent at the timestamp.
+// It is useful for receivers written in Go.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
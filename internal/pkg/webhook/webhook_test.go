@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_Send(t *testing.T) {
+	t.Log("Send signed webhook to local receiver")
+
+	const secret = "test-secret"
+	body := []byte(`{"type":"subs.created"}`)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, receivedBody)
+		require.Equal(t, "subs.created", r.Header.Get(HeaderEvent))
+		require.Equal(t, "delivery-id", r.Header.Get(HeaderID))
+		require.True(t, Verify(secret, r.Header.Get(HeaderTimestamp), receivedBody,
+			r.Header.Get(HeaderSignature)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(time.Second)
+	statusCode, err := sender.Send(t.Context(), &Request{
+		URL:       receiver.URL,
+		Secret:    secret,
+		ID:        "delivery-id",
+		Event:     "subs.created",
+		Body:      body,
+		Timestamp: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+}
+
+func TestSender_SendFailed(t *testing.T) {
+	t.Log("Send webhook to failing receiver")
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	statusCode, err := NewSender(time.Second).Send(t.Context(), &Request{URL: receiver.URL})
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+	t.Logf("Expected error: %s", err.Error())
+}
@@ -0,0 +1,165 @@
+// Package xlsx writes XLSX workbooks with one sheet streamed row by row,
+// so rows are not kept in memory. Only inline strings and numbers are supported.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MIMEType is a media type of XLSX files.
+const MIMEType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+const _lettersCount = 26 // number of letters in column names
+
+// Static parts of the workbook.
+const (
+	_contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ` +
+		`ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	_rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Target="xl/workbook.xml" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"/>` +
+		`</Relationships>`
+	_workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Target="worksheets/sheet1.xml" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"/>` +
+		`</Relationships>`
+	// workbook with the only sheet, its name is given by format arg
+	_workbookFmt = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	_sheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	_sheetEnd = `</sheetData></worksheet>`
+)
+
+// ErrClosed is returned on writing into closed writer.
+var ErrClosed = errors.New("xlsx writer is closed")
+
+// Cell is a value of one sheet cell.
+type Cell struct {
+	value  string
+	number bool
+}
+
+// String returns cell with the text.
+func String(value string) Cell {
+	return Cell{value: value}
+}
+
+// Number returns cell with the number given as decimal string (e.g. "199.99").
+func Number(value string) Cell {
+	return Cell{value: value, number: true}
+}
+
+// Int returns cell with the integer number.
+func Int(value int64) Cell {
+	return Number(strconv.FormatInt(value, 10))
+}
+
+// Writer writes rows into the sheet of the workbook.
+type Writer struct {
+	zipWriter *zip.Writer
+	sheet     io.Writer
+	rows      int
+	closed    bool
+}
+
+// NewWriter writes all workbook parts except sheet rows into w
+// and returns writer of the rows into the sheet with the given name.
+// Close must be called to finish the workbook.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zipWriter := zip.NewWriter(w)
+	name := &bytes.Buffer{}
+	if err := xml.EscapeText(name, []byte(sheetName)); err != nil {
+		return nil, fmt.Errorf("escape sheet name: %w", err)
+	}
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", _contentTypes},
+		{"_rels/.rels", _rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(_workbookFmt, name.String())},
+		{"xl/_rels/workbook.xml.rels", _workbookRels},
+		{"xl/worksheets/sheet1.xml", _sheetStart},
+	}
+
+	var sheet io.Writer
+	for _, part := range parts {
+		partWriter, err := zipWriter.Create(part.path)
+		if err != nil {
+			return nil, fmt.Errorf("create %s: %w", part.path, err)
+		}
+		if _, err := io.WriteString(partWriter, part.content); err != nil {
+			return nil, fmt.Errorf("write %s: %w", part.path, err)
+		}
+		sheet = partWriter // sheet is the last part
+	}
+	return &Writer{zipWriter: zipWriter, sheet: sheet}, nil
+}
+
+// WriteRow writes the next row of the sheet with the given cells.
+func (w *Writer) WriteRow(cells ...Cell) error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.rows++
+	row := &bytes.Buffer{}
+	fmt.Fprintf(row, `<row r="%d">`, w.rows)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+		if cell.number {
+			fmt.Fprintf(row, `<c r="%s"><v>%s</v></c>`, ref, cell.value)
+			continue
+		}
+		fmt.Fprintf(row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		if err := xml.EscapeText(row, []byte(cell.value)); err != nil {
+			return fmt.Errorf("escape cell %s: %w", ref, err)
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+
+	if _, err := w.sheet.Write(row.Bytes()); err != nil {
+		return fmt.Errorf("write row %d: %w", w.rows, err)
+	}
+	return nil
+}
+
+// Close finishes the sheet and the workbook. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	if _, err := io.WriteString(w.sheet, _sheetEnd); err != nil {
+		return fmt.Errorf("write sheet end: %w", err)
+	}
+	if err := w.zipWriter.Close(); err != nil {
+		return fmt.Errorf("close workbook: %w", err)
+	}
+	return nil
+}
+
+// columnName returns name of the column with the given zero-based index (A, B, ..., AA, ...).
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / _lettersCount {
+		name = string(rune('A'+(i-1)%_lettersCount)) + name
+	}
+	return name
+}
@@ -0,0 +1,51 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readPart returns content of the workbook part.
+func readPart(t *testing.T, workbook []byte, path string) string {
+	t.Helper()
+	zipReader, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	require.NoError(t, err)
+	part, err := zipReader.Open(path)
+	require.NoError(t, err)
+	defer part.Close()
+	content, err := io.ReadAll(part)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestWriter(t *testing.T) {
+	t.Log("Write workbook with text and number cells")
+
+	buf := &bytes.Buffer{}
+	writer, err := NewWriter(buf, "subs & co")
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRow(String("service"), String("price")))
+	require.NoError(t, writer.WriteRow(String("<Ivi>"), Number("199.99"), Int(3)))
+	require.NoError(t, writer.Close())
+	require.ErrorIs(t, writer.WriteRow(String("late")), ErrClosed)
+
+	require.Contains(t, readPart(t, buf.Bytes(), "xl/workbook.xml"), `name="subs &amp; co"`)
+	sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	require.Contains(t, sheet, `<row r="2"><c r="A2" t="inlineStr"><is>`+
+		`<t xml:space="preserve">&lt;Ivi&gt;</t></is></c>`+
+		`<c r="B2"><v>199.99</v></c><c r="C2"><v>3</v></c></row>`)
+	require.Contains(t, sheet, "</sheetData></worksheet>")
+	require.Contains(t, readPart(t, buf.Bytes(), "[Content_Types].xml"), "/xl/worksheets/sheet1.xml")
+}
+
+func TestColumnName(t *testing.T) {
+	t.Log("Column names by indexes")
+
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		require.Equal(t, name, columnName(i))
+	}
+}
@@ -0,0 +1,7 @@
+DROP FUNCTION IF EXISTS subs_charge_dates(subs, DATE, DATE);
+
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS billing_interval,
+    DROP COLUMN IF EXISTS billing_period;
+
+DROP TYPE IF EXISTS billing_period;
@@ -0,0 +1,39 @@
+CREATE TYPE billing_period AS ENUM ('weekly', 'monthly', 'quarterly', 'yearly');
+
+ALTER TABLE subs
+    ADD COLUMN billing_period billing_period NOT NULL DEFAULT 'monthly',
+    ADD COLUMN billing_interval INT NOT NULL DEFAULT 1 CHECK (billing_interval > 0);
+
+-- Returns billing dates of the subs within the months window [win_start, win_end]
+-- (both months are inclusive, NULL win_start means no lower bound).
+-- Subs is charged on its start date and then every billing period.
+-- The month of the subs end date is the last charged month.
+CREATE FUNCTION subs_charge_dates(s subs, win_start DATE, win_end DATE)
+RETURNS SETOF DATE
+LANGUAGE SQL STABLE
+AS $$
+    WITH bounds AS (
+        SELECT
+            (date_trunc('month', LEAST(COALESCE(s.end_date, win_end), win_end))
+                + INTERVAL '1 month' - INTERVAL '1 day')::date AS upper,
+            CASE s.billing_period
+                WHEN 'weekly' THEN make_interval(weeks => s.billing_interval)
+                WHEN 'monthly' THEN make_interval(months => s.billing_interval)
+                WHEN 'quarterly' THEN make_interval(months => 3 * s.billing_interval)
+                WHEN 'yearly' THEN make_interval(years => s.billing_interval)
+            END AS step,
+            -- min number of days in the billing period to limit the number of steps
+            CASE s.billing_period
+                WHEN 'weekly' THEN 7
+                WHEN 'monthly' THEN 28
+                WHEN 'quarterly' THEN 89
+                WHEN 'yearly' THEN 365
+            END * s.billing_interval AS step_days
+    )
+    SELECT charge.charge_date
+    FROM bounds
+    CROSS JOIN LATERAL generate_series(0, GREATEST(bounds.upper - s.start_date, 0) / bounds.step_days) AS k
+    CROSS JOIN LATERAL (SELECT (s.start_date + k * bounds.step)::date AS charge_date) AS charge
+    WHERE charge.charge_date <= bounds.upper
+        AND (win_start IS NULL OR charge.charge_date >= date_trunc('month', win_start)::date)
+$$;
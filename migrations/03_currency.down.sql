@@ -0,0 +1,7 @@
+DROP FUNCTION IF EXISTS exchange_rate(CHAR(3), CHAR(3), DATE);
+DROP FUNCTION IF EXISTS exchange_rate_to_eur(CHAR(3), DATE);
+
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS currency;
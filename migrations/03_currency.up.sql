@@ -0,0 +1,50 @@
+ALTER TABLE subs
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+-- Exchange rates against EUR: amount of currency for 1 EUR at the rate date.
+CREATE TABLE exchange_rates (
+    currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (currency, rate_date)
+);
+
+-- Returns amount of currency for 1 EUR valid for the month of the given date
+-- (the latest rate not later than the end of the month).
+-- It raises no_data_found exception if there is no such rate.
+CREATE FUNCTION exchange_rate_to_eur(cur CHAR(3), at_date DATE)
+RETURNS NUMERIC
+LANGUAGE plpgsql STABLE
+AS $$
+DECLARE
+    found_rate NUMERIC;
+BEGIN
+    IF cur = 'EUR' THEN
+        RETURN 1;
+    END IF;
+
+    SELECT r.rate INTO found_rate
+    FROM exchange_rates AS r
+    WHERE r.currency = cur
+        AND r.rate_date < date_trunc('month', at_date) + INTERVAL '1 month'
+    ORDER BY r.rate_date DESC
+    LIMIT 1;
+
+    IF found_rate IS NULL THEN
+        RAISE EXCEPTION 'no exchange rate for % at %', cur, to_char(at_date, 'MM-YYYY')
+            USING ERRCODE = 'no_data_found';
+    END IF;
+    RETURN found_rate;
+END;
+$$;
+
+-- Returns amount of to_cur for 1 from_cur valid for the month of the given date.
+CREATE FUNCTION exchange_rate(from_cur CHAR(3), to_cur CHAR(3), at_date DATE)
+RETURNS NUMERIC
+LANGUAGE SQL STABLE
+AS $$
+    SELECT CASE
+        WHEN from_cur = to_cur THEN 1
+        ELSE exchange_rate_to_eur(to_cur, at_date) / exchange_rate_to_eur(from_cur, at_date)
+    END
+$$;
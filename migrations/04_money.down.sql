@@ -0,0 +1,7 @@
+DROP FUNCTION IF EXISTS convert_amount(BIGINT, CHAR(3), CHAR(3), DATE);
+
+ALTER TABLE subs
+    ALTER COLUMN price TYPE INT
+    USING ROUND(price / (10::NUMERIC ^ currency_exponent(currency)))::INT;
+
+DROP FUNCTION IF EXISTS currency_exponent(CHAR(3));
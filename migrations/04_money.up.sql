@@ -0,0 +1,31 @@
+-- Returns number of minor unit digits of the currency (ISO 4217).
+-- It must be kept in sync with entity.CurrencyExponent.
+CREATE FUNCTION currency_exponent(cur CHAR(3))
+RETURNS INT
+LANGUAGE SQL IMMUTABLE
+AS $$
+    SELECT CASE
+        WHEN cur IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG',
+            'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+        WHEN cur IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+        ELSE 2
+    END
+$$;
+
+-- Prices are stored in minor units of the subs currency (e.g. kopecks).
+ALTER TABLE subs
+    ALTER COLUMN price TYPE BIGINT
+    USING price::BIGINT * (10 ^ currency_exponent(currency))::BIGINT;
+
+-- Returns amount in minor units of from_cur converted into minor units of to_cur
+-- by exchange rate valid for the month of the given date (not rounded).
+CREATE FUNCTION convert_amount(amount BIGINT, from_cur CHAR(3), to_cur CHAR(3), at_date DATE)
+RETURNS NUMERIC
+LANGUAGE SQL STABLE
+AS $$
+    SELECT CASE
+        WHEN from_cur = to_cur THEN amount::NUMERIC
+        ELSE amount * exchange_rate(from_cur, to_cur, at_date)
+            * (10::NUMERIC ^ (currency_exponent(to_cur) - currency_exponent(from_cur)))
+    END
+$$;
@@ -0,0 +1,6 @@
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS ended_notified_at;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TYPE IF EXISTS webhook_delivery_status;
+DROP TABLE IF EXISTS webhooks;
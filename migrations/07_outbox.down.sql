@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS webhook_deliveries_event_uniq;
+
+DROP TABLE IF EXISTS outbox;
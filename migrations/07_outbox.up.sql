@@ -0,0 +1,16 @@
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ NULL
+);
+
+-- unsent messages are polled in creation order
+CREATE INDEX outbox_unsent_idx ON outbox (created_at)
+    WHERE sent_at IS NULL;
+
+-- event is delivered to webhook once even if it is relayed again
+CREATE UNIQUE INDEX webhook_deliveries_event_uniq ON webhook_deliveries (webhook_id, event_id);
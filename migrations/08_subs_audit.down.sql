@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subs_audit;
+DROP TYPE IF EXISTS subs_audit_action;
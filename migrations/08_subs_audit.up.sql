@@ -0,0 +1,21 @@
+CREATE TYPE subs_audit_action AS ENUM ('create', 'update', 'delete');
+
+-- subs is not referenced to keep history of deleted subs
+CREATE TABLE subs_audit (
+    id UUID PRIMARY KEY,
+    subs_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    action subs_audit_action NOT NULL,
+    actor_id UUID NULL,
+    actor_role TEXT NOT NULL,
+    api_key_id UUID NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    before JSONB NULL,
+    after JSONB NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subs_audit_subs_idx ON subs_audit (subs_id, created_at);
+CREATE INDEX subs_audit_created_idx ON subs_audit (created_at);
+CREATE INDEX subs_audit_actor_idx ON subs_audit (actor_id, created_at);
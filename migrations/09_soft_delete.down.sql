@@ -0,0 +1,13 @@
+DELETE FROM subs_audit WHERE action = 'restore';
+
+ALTER TYPE subs_audit_action RENAME TO subs_audit_action_old;
+CREATE TYPE subs_audit_action AS ENUM ('create', 'update', 'delete');
+ALTER TABLE subs_audit
+    ALTER COLUMN action TYPE subs_audit_action USING action::text::subs_audit_action;
+DROP TYPE subs_audit_action_old;
+
+DROP INDEX IF EXISTS subs_deleted_at_idx;
+
+DELETE FROM subs WHERE deleted_at IS NOT NULL;
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+-- deleted subs are kept until they are purged
+ALTER TABLE subs
+    ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX subs_deleted_at_idx ON subs (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TYPE subs_audit_action ADD VALUE IF NOT EXISTS 'restore';
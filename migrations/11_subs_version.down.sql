@@ -0,0 +1,2 @@
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- version of the subs for optimistic concurrency, it is incremented on every update
+ALTER TABLE subs
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
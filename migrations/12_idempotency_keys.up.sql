@@ -0,0 +1,16 @@
+CREATE TABLE idempotency_keys (
+    owner TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    -- response is absent while the request is in progress
+    response_status INT NOT NULL DEFAULT 0,
+    response_headers JSONB NULL,
+    response_body BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    -- concurrent requests with the same key cannot both be started
+    PRIMARY KEY (owner, key)
+);
+
+-- expired keys are purged by expiration time
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys (expires_at);
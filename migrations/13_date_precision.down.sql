@@ -0,0 +1,55 @@
+-- restore billing dates function within months window without billing day
+-- Returns billing dates of the subs within the months window [win_start, win_end]
+-- (both months are inclusive, NULL win_start means no lower bound).
+-- Subs is charged on its start date and then every billing period.
+-- The month of the subs end date is the last charged month.
+-- Charges within paused months are skipped.
+CREATE OR REPLACE FUNCTION subs_charge_dates(s subs, win_start DATE, win_end DATE)
+RETURNS SETOF DATE
+LANGUAGE SQL STABLE
+AS $$
+    WITH bounds AS (
+        SELECT
+            (date_trunc('month', LEAST(COALESCE(s.end_date, win_end), win_end))
+                + INTERVAL '1 month' - INTERVAL '1 day')::date AS upper,
+            CASE s.billing_period
+                WHEN 'weekly' THEN make_interval(weeks => s.billing_interval)
+                WHEN 'monthly' THEN make_interval(months => s.billing_interval)
+                WHEN 'quarterly' THEN make_interval(months => 3 * s.billing_interval)
+                WHEN 'yearly' THEN make_interval(years => s.billing_interval)
+            END AS step,
+            -- min number of days in the billing period to limit the number of steps
+            CASE s.billing_period
+                WHEN 'weekly' THEN 7
+                WHEN 'monthly' THEN 28
+                WHEN 'quarterly' THEN 89
+                WHEN 'yearly' THEN 365
+            END * s.billing_interval AS step_days
+    )
+    SELECT charge.charge_date
+    FROM bounds
+    CROSS JOIN LATERAL generate_series(0, GREATEST(bounds.upper - s.start_date, 0) / bounds.step_days) AS k
+    CROSS JOIN LATERAL (SELECT (s.start_date + k * bounds.step)::date AS charge_date) AS charge
+    WHERE charge.charge_date <= bounds.upper
+        AND (win_start IS NULL OR charge.charge_date >= date_trunc('month', win_start)::date)
+        AND NOT EXISTS (
+            SELECT 1 FROM subs_pauses AS pause
+            WHERE pause.subs_id = s.id
+                AND date_trunc('month', charge.charge_date) >= pause.start_date
+                AND (pause.end_date IS NULL OR date_trunc('month', charge.charge_date) <= pause.end_date)
+        )
+$$;
+
+DROP FUNCTION IF EXISTS subs_last_day(subs);
+
+-- dates are the first days of their months again
+UPDATE subs SET
+    start_date = date_trunc('month', start_date)::date,
+    end_date = date_trunc('month', end_date)::date;
+
+ALTER TABLE subs
+    DROP COLUMN IF EXISTS billing_day,
+    DROP COLUMN IF EXISTS end_date_precision,
+    DROP COLUMN IF EXISTS start_date_precision;
+
+DROP TYPE IF EXISTS date_precision;
@@ -0,0 +1,76 @@
+-- precision of the subs date: month means the date is the first day of the given month
+CREATE TYPE date_precision AS ENUM ('month', 'day');
+
+-- NULL precision of the end date means month precision,
+-- billing day is the day of month of charges for monthly, quarterly and yearly subs
+ALTER TABLE subs
+    ADD COLUMN start_date_precision date_precision NOT NULL DEFAULT 'month',
+    ADD COLUMN end_date_precision date_precision NULL,
+    ADD COLUMN billing_day INT NOT NULL DEFAULT 1 CHECK (billing_day BETWEEN 1 AND 31);
+
+UPDATE subs SET end_date_precision = 'month' WHERE end_date IS NOT NULL;
+
+-- Returns the last day of the subs: its end date with day precision or the last day
+-- of the end date month otherwise (NULL for subs without end date).
+CREATE FUNCTION subs_last_day(s subs)
+RETURNS DATE
+LANGUAGE SQL IMMUTABLE
+AS $$
+    SELECT CASE s.end_date_precision
+        WHEN 'day' THEN s.end_date
+        ELSE (date_trunc('month', s.end_date) + INTERVAL '1 month' - INTERVAL '1 day')::date
+    END
+$$;
+
+-- Returns billing dates of the subs within the days window [win_start, win_end]
+-- (both days are inclusive, NULL win_start means no lower bound).
+-- Subs is charged on its start date and then every billing period: weekly charges follow
+-- the start date and other ones are on the billing day of the month (on the last day
+-- of the month if it is shorter). The last day of the subs is the last charged day.
+-- Charges within paused months are skipped.
+CREATE OR REPLACE FUNCTION subs_charge_dates(s subs, win_start DATE, win_end DATE)
+RETURNS SETOF DATE
+LANGUAGE SQL STABLE
+AS $$
+    WITH bounds AS (
+        SELECT
+            LEAST(COALESCE(subs_last_day(s), win_end), win_end) AS upper,
+            -- number of months in the billing period (0 for weekly one)
+            CASE s.billing_period
+                WHEN 'weekly' THEN 0
+                WHEN 'monthly' THEN 1
+                WHEN 'quarterly' THEN 3
+                WHEN 'yearly' THEN 12
+            END * s.billing_interval AS step_months,
+            -- min number of days in the billing period to limit the number of steps
+            CASE s.billing_period
+                WHEN 'weekly' THEN 7
+                WHEN 'monthly' THEN 28
+                WHEN 'quarterly' THEN 89
+                WHEN 'yearly' THEN 365
+            END * s.billing_interval AS step_days
+    )
+    SELECT charge.charge_date
+    FROM bounds
+    -- charges on the billing day can be up to a month earlier than the start date day
+    CROSS JOIN LATERAL generate_series(0, GREATEST(bounds.upper - s.start_date + 31, 0) / bounds.step_days) AS k
+    CROSS JOIN LATERAL (
+        SELECT (date_trunc('month', s.start_date) + make_interval(months => k * bounds.step_months))::date AS month
+    ) AS charge_month
+    CROSS JOIN LATERAL (
+        SELECT CASE
+            WHEN k = 0 THEN s.start_date
+            WHEN bounds.step_months = 0 THEN s.start_date + k * bounds.step_days
+            ELSE charge_month.month + LEAST(s.billing_day,
+                EXTRACT(DAY FROM charge_month.month + INTERVAL '1 month' - INTERVAL '1 day')::int) - 1
+        END AS charge_date
+    ) AS charge
+    WHERE charge.charge_date <= bounds.upper
+        AND (win_start IS NULL OR charge.charge_date >= win_start)
+        AND NOT EXISTS (
+            SELECT 1 FROM subs_pauses AS pause
+            WHERE pause.subs_id = s.id
+                AND date_trunc('month', charge.charge_date) >= pause.start_date
+                AND (pause.end_date IS NULL OR date_trunc('month', charge.charge_date) <= pause.end_date)
+        )
+$$;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX outbox_unsent_idx ON outbox (created_at)
+    WHERE sent_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS published_to,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS outbox_status;
@@ -0,0 +1,16 @@
+CREATE TYPE outbox_status AS ENUM ('pending', 'sent', 'dead');
+
+-- failed message is retried by its own schedule and becomes dead after max attempts,
+-- names of publishers which have published the message are not retried
+ALTER TABLE outbox
+    ADD COLUMN status outbox_status NOT NULL DEFAULT 'pending',
+    ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN published_to TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE outbox SET status = 'sent' WHERE sent_at IS NOT NULL;
+UPDATE outbox SET next_attempt_at = created_at WHERE sent_at IS NULL;
+
+-- pending messages are polled by next attempt time
+DROP INDEX IF EXISTS outbox_unsent_idx;
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at)
+    WHERE status = 'pending';
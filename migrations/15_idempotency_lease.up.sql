@@ -0,0 +1,4 @@
+-- request in progress holds its key until this time,
+-- after it the key can be taken over by the same request (e.g. after crash)
+ALTER TABLE idempotency_keys
+    ADD COLUMN locked_until TIMESTAMPTZ NOT NULL DEFAULT now();
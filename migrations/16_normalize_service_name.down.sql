@@ -0,0 +1,2 @@
+-- original spaces of service names cannot be restored, normalized names are kept
+SELECT 1;
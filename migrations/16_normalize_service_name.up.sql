@@ -0,0 +1,6 @@
+-- service names are saved without leading, trailing and repeated spaces
+-- (like entity.NormalizeServiceName), so filters by service name match them
+UPDATE subs SET
+    service_name = btrim(regexp_replace(service_name, '[[:space:]]+', ' ', 'g'), ' '),
+    version = version + 1
+WHERE service_name <> btrim(regexp_replace(service_name, '[[:space:]]+', ' ', 'g'), ' ');
@@ -0,0 +1,7 @@
+-- version of the calendar token of the user, it is incremented on token regeneration
+-- which revokes the previous token (users without row have tokens of version 0)
+CREATE TABLE calendar_tokens (
+    user_id UUID PRIMARY KEY,
+    version BIGINT NOT NULL,
+    regenerated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,7 @@
+DELETE FROM subs_audit WHERE action IN ('pause', 'resume');
+
+ALTER TYPE subs_audit_action RENAME TO subs_audit_action_old;
+CREATE TYPE subs_audit_action AS ENUM ('create', 'update', 'delete', 'restore');
+ALTER TABLE subs_audit
+    ALTER COLUMN action TYPE subs_audit_action USING action::text::subs_audit_action;
+DROP TYPE subs_audit_action_old;
@@ -0,0 +1,2 @@
+ALTER TYPE subs_audit_action ADD VALUE IF NOT EXISTS 'pause';
+ALTER TYPE subs_audit_action ADD VALUE IF NOT EXISTS 'resume';
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS subs_next_charge_date(subs, DATE);
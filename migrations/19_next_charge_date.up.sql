@@ -0,0 +1,21 @@
+-- Returns the first billing date of the subs not before from_date (NULL if there is none).
+-- Charges are not skipped after the end of the last finished pause, so the next charge
+-- is within one billing period (plus a month of the billing day shift) after the latest
+-- of the given date, the start date and the end of the last finished pause.
+CREATE FUNCTION subs_next_charge_date(s subs, from_date DATE)
+RETURNS DATE
+LANGUAGE SQL STABLE
+AS $$
+    SELECT min(charge_date)
+    FROM subs_charge_dates(s, from_date, (
+        SELECT (GREATEST(from_date, s.start_date, MAX(pause.end_date + INTERVAL '1 month'))
+            + CASE s.billing_period
+                WHEN 'weekly' THEN make_interval(days => 7 * s.billing_interval)
+                WHEN 'monthly' THEN make_interval(months => s.billing_interval)
+                WHEN 'quarterly' THEN make_interval(months => 3 * s.billing_interval)
+                WHEN 'yearly' THEN make_interval(years => s.billing_interval)
+            END + INTERVAL '1 month')::date
+        FROM subs_pauses AS pause
+        WHERE pause.subs_id = s.id AND pause.end_date IS NOT NULL
+    )) AS charge_date
+$$;